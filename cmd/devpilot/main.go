@@ -9,6 +9,7 @@ import (
 	"github.com/siyuqian/devpilot/internal/gmail"
 	"github.com/siyuqian/devpilot/internal/initcmd"
 	"github.com/siyuqian/devpilot/internal/openspec"
+	"github.com/siyuqian/devpilot/internal/plan"
 	"github.com/siyuqian/devpilot/internal/skillmgr"
 	"github.com/siyuqian/devpilot/internal/slack"
 	"github.com/siyuqian/devpilot/internal/taskrunner"
@@ -36,6 +37,7 @@ func main() {
 	taskrunner.RegisterCommands(rootCmd)
 	generate.RegisterCommands(rootCmd)
 	openspec.RegisterCommands(rootCmd)
+	plan.RegisterCommands(rootCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -0,0 +1,389 @@
+package github
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBaseURL = "https://api.github.com"
+	apiVersion     = "2022-11-28"
+
+	// defaultMaxRateLimitWait is how long a request may sleep waiting for a
+	// rate limit to reset before giving up with a *RateLimitError.
+	defaultMaxRateLimitWait = time.Minute
+	maxRateLimitRetries     = 2
+
+	// maxCachedResponses bounds the ETag cache of a long-running client; the
+	// least recently used responses are dropped first.
+	maxCachedResponses = 256
+)
+
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+
+	maxRateLimitWait time.Duration
+	sleep            func(time.Duration)
+
+	mu    sync.Mutex
+	etags map[string]*list.Element // values are cachedResponse
+	lru   *list.List               // most recently used first
+}
+
+// cachedResponse is a GET response kept for conditional requests. A 304
+// reply to If-None-Match is served from it and does not count against the
+// rate limit.
+type cachedResponse struct {
+	url  string
+	etag string
+	body []byte
+	next string
+}
+
+type Option func(*Client)
+
+func WithBaseURL(u string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimRight(u, "/") }
+}
+
+// WithHost points the client at a GitHub host: github.com when empty, or a
+// GitHub Enterprise Server host such as ghe.example.com.
+func WithHost(host string) Option {
+	return WithBaseURL(APIBaseURL(host))
+}
+
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithMaxRateLimitWait bounds how long a request sleeps for a rate limit to
+// reset before failing. Zero fails immediately.
+func WithMaxRateLimitWait(d time.Duration) Option {
+	return func(c *Client) { c.maxRateLimitWait = d }
+}
+
+func NewClient(token string, opts ...Option) *Client {
+	c := &Client{
+		token:            token,
+		baseURL:          defaultBaseURL,
+		httpClient:       &http.Client{Timeout: 30 * time.Second},
+		maxRateLimitWait: defaultMaxRateLimitWait,
+		sleep:            time.Sleep,
+		etags:            map[string]*list.Element{},
+		lru:              list.New(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is a non-2xx response from the GitHub API.
+type APIError struct {
+	StatusCode       int
+	Method           string
+	Path             string
+	Message          string `json:"message"`
+	DocumentationURL string `json:"documentation_url"`
+	Errors           []struct {
+		Resource string `json:"resource"`
+		Field    string `json:"field"`
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+	} `json:"errors"`
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	for _, fe := range e.Errors {
+		switch {
+		case fe.Message != "":
+			msg += "; " + fe.Message
+		case fe.Code != "":
+			msg += fmt.Sprintf("; %s %s %s", fe.Resource, fe.Field, fe.Code)
+		}
+	}
+	return fmt.Sprintf("%s %s: HTTP %d: %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+// HasCode reports whether any of the error's field errors has the given code,
+// e.g. "already_exists".
+func (e *APIError) HasCode(code string) bool {
+	for _, fe := range e.Errors {
+		if fe.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound reports whether err is a 404 from the GitHub API.
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// RateLimitError is returned when the rate limit will not reset within the
+// client's maximum wait.
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit exceeded; resets at %s", e.Reset.Format(time.Kitchen))
+}
+
+// do sends a request with a JSON body (if in is non-nil) and returns the
+// response body and the URL of the next page from the Link header.
+func (c *Client) do(method, path string, params url.Values, in any) ([]byte, string, error) {
+	reqURL := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		reqURL = c.baseURL + path
+	}
+	if len(params) > 0 {
+		reqURL += "?" + params.Encode()
+	}
+
+	var payload []byte
+	if in != nil {
+		var err error
+		if payload, err = json.Marshal(in); err != nil {
+			return nil, "", fmt.Errorf("encode request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, reqURL, bytes.NewReader(payload))
+		if err != nil {
+			return nil, "", fmt.Errorf("create request failed: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", apiVersion)
+		if in != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		cached, isCached := c.cached(method, reqURL)
+		if isCached {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, "", fmt.Errorf("request failed: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("read body failed: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusNotModified && isCached {
+			return cached.body, cached.next, nil
+		}
+		if wait, limited := rateLimitWait(resp); limited {
+			if attempt >= maxRateLimitRetries || wait > c.maxRateLimitWait {
+				return nil, "", &RateLimitError{Reset: time.Now().Add(wait)}
+			}
+			c.sleep(wait)
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			apiErr := &APIError{StatusCode: resp.StatusCode, Method: method, Path: path}
+			if json.Unmarshal(body, apiErr) != nil || apiErr.Message == "" {
+				apiErr.Message = strings.TrimSpace(string(body))
+			}
+			return nil, "", apiErr
+		}
+
+		next := nextPageURL(resp.Header.Get("Link"))
+		if method == http.MethodGet {
+			if etag := resp.Header.Get("ETag"); etag != "" {
+				c.store(reqURL, cachedResponse{etag: etag, body: body, next: next})
+			}
+		}
+		return body, next, nil
+	}
+}
+
+func (c *Client) cached(method, reqURL string) (cachedResponse, bool) {
+	if method != http.MethodGet {
+		return cachedResponse{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.etags[reqURL]
+	if !ok {
+		return cachedResponse{}, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(cachedResponse), true
+}
+
+func (c *Client) store(reqURL string, r cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r.url = reqURL
+	if e, ok := c.etags[reqURL]; ok {
+		e.Value = r
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.etags[reqURL] = c.lru.PushFront(r)
+	if c.lru.Len() > maxCachedResponses {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.etags, oldest.Value.(cachedResponse).url)
+	}
+}
+
+// rateLimitWait reports whether resp is a primary or secondary rate limit
+// response and how long to wait before retrying.
+func rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, true
+		}
+		wait := time.Until(time.Unix(reset, 0))
+		if wait < 0 {
+			wait = 0
+		}
+		return wait + time.Second, true
+	}
+	return 0, false
+}
+
+var linkNextRe = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+func nextPageURL(link string) string {
+	if m := linkNextRe.FindStringSubmatch(link); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func (c *Client) get(path string, params url.Values, out any) error {
+	body, _, err := c.do(http.MethodGet, path, params, nil)
+	if err != nil {
+		return err
+	}
+	return decode(body, out)
+}
+
+// getAll follows Link pagination and returns the items of every page of a
+// JSON array response.
+func getAll[T any](c *Client, path string, params url.Values) ([]T, error) {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("per_page", "100")
+	var all []T
+	for path != "" {
+		body, next, err := c.do(http.MethodGet, path, params, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []T
+		if err := decode(body, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		// The next link already carries the query string.
+		path, params = next, nil
+	}
+	return all, nil
+}
+
+func (c *Client) send(method, path string, in, out any) error {
+	body, _, err := c.do(method, path, nil, in)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return decode(body, out)
+}
+
+func decode(body []byte, out any) error {
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	return nil
+}
+
+// tokenHosts returns the hosts the client's token may be sent to: the API
+// host and the matching web host (github.com for api.github.com).
+func (c *Client) tokenHosts() (api, web string) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", ""
+	}
+	api, web = u.Host, u.Host
+	if api == "api.github.com" {
+		web = defaultHost
+	}
+	return api, web
+}
+
+var attachmentPathRe = regexp.MustCompile(`^/(?:user-attachments/|[\w.-]+/[\w.-]+/files/\d+/|storage/user/)`)
+
+// IsAttachmentURL reports whether rawURL is a file uploaded to GitHub: an
+// attachment path on the client's own host, or a file on
+// *.githubusercontent.com. Links anywhere else are not attachments, so the
+// token is never used to fetch them.
+func (c *Client) IsAttachmentURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return false
+	}
+	host := strings.ToLower(u.Host)
+	if strings.HasSuffix(host, ".githubusercontent.com") {
+		return u.Scheme == "https"
+	}
+	api, web := c.tokenHosts()
+	return (host == api || host == web) && attachmentPathRe.MatchString(u.Path)
+}
+
+// Download fetches a file such as an issue attachment. The client's token
+// is sent only to the API and web hosts. The caller closes the returned
+// body.
+func (c *Client) Download(rawURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	if api, web := c.tokenHosts(); req.URL.Host == api || req.URL.Host == web {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &APIError{StatusCode: resp.StatusCode, Method: http.MethodGet, Path: rawURL}
+	}
+	return resp.Body, nil
+}
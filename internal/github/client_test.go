@@ -0,0 +1,289 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_AuthHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("unexpected auth: %s", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("Accept") != "application/vnd.github+json" {
+			t.Errorf("unexpected accept: %s", r.Header.Get("Accept"))
+		}
+		json.NewEncoder(w).Encode(User{Login: "octocat"})
+	}))
+	defer srv.Close()
+
+	login, err := NewClient("test-token", WithBaseURL(srv.URL)).CurrentUser()
+	if err != nil {
+		t.Fatalf("CurrentUser error: %v", err)
+	}
+	if login != "octocat" {
+		t.Errorf("expected octocat, got %s", login)
+	}
+}
+
+func TestClient_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"message":"Validation Failed","errors":[{"resource":"Label","field":"name","code":"already_exists"}]}`)
+	}))
+	defer srv.Close()
+
+	err := NewClient("t", WithBaseURL(srv.URL)).send(http.MethodPost, "/repos/o/r/labels", Label{Name: "x"}, nil)
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != 422 || !apiErr.HasCode("already_exists") {
+		t.Errorf("unexpected error: %+v", apiErr)
+	}
+	if !strings.Contains(err.Error(), "Validation Failed; Label name already_exists") {
+		t.Errorf("unexpected message: %s", err)
+	}
+}
+
+func TestClient_Pagination(t *testing.T) {
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("expected per_page=100, got %q", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/o/r/issues?labels=devpilot&page=2&per_page=100>; rel="next", <%s/x>; rel="last"`, srvURL, srvURL))
+			fmt.Fprint(w, `[{"number":1},{"number":2}]`)
+			return
+		}
+		if r.URL.Query().Get("labels") != "devpilot" {
+			t.Errorf("next page lost its query: %q", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `[{"number":3},{"number":4,"pull_request":{}}]`)
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	issues, err := NewClient("t", WithBaseURL(srv.URL)).ListIssues("o/r", IssueListOptions{Labels: []string{"devpilot"}})
+	if err != nil {
+		t.Fatalf("ListIssues error: %v", err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues across pages with the PR filtered out, got %+v", issues)
+	}
+}
+
+func TestClient_ETagCache(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode(User{Login: "octocat"})
+	}))
+	defer srv.Close()
+
+	c := NewClient("t", WithBaseURL(srv.URL))
+	for i := 0; i < 2; i++ {
+		login, err := c.CurrentUser()
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if login != "octocat" {
+			t.Errorf("call %d: expected cached octocat, got %q", i, login)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 requests, got %d", calls)
+	}
+}
+
+func TestClient_ETagCacheIsBounded(t *testing.T) {
+	c := NewClient("t")
+	for i := range maxCachedResponses + 10 {
+		c.store(fmt.Sprintf("https://api.github.com/repos/o/r/issues/%d", i), cachedResponse{etag: "e"})
+		if i == 0 {
+			continue
+		}
+		// Keep the first response in use so it survives eviction.
+		c.cached(http.MethodGet, "https://api.github.com/repos/o/r/issues/0")
+	}
+	if len(c.etags) != maxCachedResponses || c.lru.Len() != maxCachedResponses {
+		t.Fatalf("cache holds %d responses (%d in LRU), want %d", len(c.etags), c.lru.Len(), maxCachedResponses)
+	}
+	if _, ok := c.cached(http.MethodGet, "https://api.github.com/repos/o/r/issues/0"); !ok {
+		t.Error("recently used response was evicted")
+	}
+	if _, ok := c.cached(http.MethodGet, "https://api.github.com/repos/o/r/issues/1"); ok {
+		t.Error("least recently used response was kept")
+	}
+}
+
+func TestClient_RateLimitRetry(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(User{Login: "octocat"})
+	}))
+	defer srv.Close()
+
+	var slept time.Duration
+	c := NewClient("t", WithBaseURL(srv.URL))
+	c.sleep = func(d time.Duration) { slept += d }
+
+	if _, err := c.CurrentUser(); err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if slept != 3*time.Second {
+		t.Errorf("expected to sleep 3s, slept %s", slept)
+	}
+}
+
+func TestClient_RateLimitExceedsMaxWait(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"message":"API rate limit exceeded"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient("t", WithBaseURL(srv.URL), WithMaxRateLimitWait(time.Minute))
+	c.sleep = func(time.Duration) { t.Error("should not sleep past the max wait") }
+
+	_, err := c.CurrentUser()
+	if _, ok := err.(*RateLimitError); !ok {
+		t.Fatalf("expected *RateLimitError, got %T: %v", err, err)
+	}
+}
+
+func TestClient_ForbiddenIsNotRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"message":"Resource not accessible by integration"}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient("t", WithBaseURL(srv.URL)).CurrentUser()
+	if apiErr, ok := err.(*APIError); !ok || apiErr.StatusCode != 403 {
+		t.Fatalf("expected 403 *APIError, got %T: %v", err, err)
+	}
+}
+
+func TestClient_DownloadSendsTokenOnlyToGitHub(t *testing.T) {
+	auth := map[string]string{}
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth[name] = r.Header.Get("Authorization")
+			w.Write([]byte("file"))
+		})
+	}
+	api := httptest.NewServer(handler("api"))
+	defer api.Close()
+	other := httptest.NewServer(handler("other"))
+	defer other.Close()
+
+	c := NewClient("test-token", WithBaseURL(api.URL))
+	for _, u := range []string{api.URL + "/user-attachments/assets/a", other.URL + "/files/1/x"} {
+		body, err := c.Download(u)
+		if err != nil {
+			t.Fatalf("Download(%s): %v", u, err)
+		}
+		body.Close()
+	}
+	if auth["api"] != "Bearer test-token" {
+		t.Errorf("API host auth = %q, want the token", auth["api"])
+	}
+	if auth["other"] != "" {
+		t.Errorf("other host got Authorization %q", auth["other"])
+	}
+}
+
+func TestClient_IsAttachmentURL(t *testing.T) {
+	c := NewClient("t")
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://github.com/user-attachments/assets/abc", true},
+		{"https://github.com/o/r/files/12/log.txt", true},
+		{"https://private-user-images.githubusercontent.com/1/shot.png", true},
+		{"https://attacker.example/files/1/x", false},
+		{"https://attacker.example/user-attachments/assets/abc", false},
+		{"https://github.com/o/r/issues/1", false},
+		{"http://user-images.githubusercontent.com/1/shot.png", false},
+	}
+	for _, tt := range tests {
+		if got := c.IsAttachmentURL(tt.url); got != tt.want {
+			t.Errorf("IsAttachmentURL(%s) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+
+	ghe := NewClient("t", WithHost("ghe.example.com"))
+	if !ghe.IsAttachmentURL("https://ghe.example.com/user-attachments/assets/abc") || ghe.IsAttachmentURL("https://github.com/user-attachments/assets/abc") {
+		t.Error("GHE client should accept only its own host's attachments")
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/graphql" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["id"] == "bad" {
+			fmt.Fprint(w, `{"errors":[{"message":"Could not resolve to a node"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"viewer":{"login":"octocat"}}}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient("t", WithBaseURL(srv.URL))
+	var out struct {
+		Viewer User `json:"viewer"`
+	}
+	if err := c.GraphQL("query { viewer { login } }", map[string]any{"id": "ok"}, &out); err != nil {
+		t.Fatalf("GraphQL error: %v", err)
+	}
+	if out.Viewer.Login != "octocat" {
+		t.Errorf("expected octocat, got %q", out.Viewer.Login)
+	}
+
+	err := c.GraphQL("query { x }", map[string]any{"id": "bad"}, nil)
+	if _, ok := err.(*GraphQLError); !ok {
+		t.Fatalf("expected *GraphQLError, got %T: %v", err, err)
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	cases := map[string]string{
+		"https://api.github.com":         "https://api.github.com/graphql",
+		"https://ghe.example.com/api/v3": "https://ghe.example.com/api/graphql",
+	}
+	for base, want := range cases {
+		if got := NewClient("t", WithBaseURL(base)).graphqlURL(); got != want {
+			t.Errorf("graphqlURL(%s) = %s, want %s", base, got, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLError is an error reported in a GraphQL response body.
+type GraphQLError struct {
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return "graphql: " + strings.Join(e.Messages, "; ")
+}
+
+// graphqlURL returns the GraphQL endpoint for the REST base URL:
+// api.github.com/graphql, or <host>/api/graphql for GitHub Enterprise's
+// <host>/api/v3.
+func (c *Client) graphqlURL() string {
+	if base, ok := strings.CutSuffix(c.baseURL, "/v3"); ok {
+		return base + "/graphql"
+	}
+	return c.baseURL + "/graphql"
+}
+
+// GraphQL runs a query or mutation and decodes its "data" field into out,
+// which may be nil.
+func (c *Client) GraphQL(query string, variables map[string]any, out any) error {
+	in := map[string]any{"query": query, "variables": variables}
+	body, _, err := c.do(http.MethodPost, c.graphqlURL(), nil, in)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("parse graphql response: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		gqlErr := &GraphQLError{}
+		for _, e := range resp.Errors {
+			gqlErr.Messages = append(gqlErr.Messages, e.Message)
+		}
+		return gqlErr
+	}
+	if out == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	return decode(resp.Data, out)
+}
@@ -0,0 +1,165 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// IssueListOptions filters ListIssues.
+type IssueListOptions struct {
+	Labels    []string // issues must carry all of these
+	State     string   // "open" (default), "closed" or "all"
+	Sort      string   // "created" (default), "updated" or "comments"
+	Direction string   // "asc" or "desc" (default)
+}
+
+// ListIssues returns every issue in repo matching opts, following
+// pagination. Pull requests are filtered out.
+func (c *Client) ListIssues(repo string, opts IssueListOptions) ([]Issue, error) {
+	params := url.Values{}
+	if len(opts.Labels) > 0 {
+		params.Set("labels", strings.Join(opts.Labels, ","))
+	}
+	if opts.State != "" {
+		params.Set("state", opts.State)
+	}
+	if opts.Sort != "" {
+		params.Set("sort", opts.Sort)
+	}
+	if opts.Direction != "" {
+		params.Set("direction", opts.Direction)
+	}
+	all, err := getAll[Issue](c, fmt.Sprintf("/repos/%s/issues", repo), params)
+	if err != nil {
+		return nil, err
+	}
+	issues := all[:0]
+	for _, issue := range all {
+		if issue.PullRequest == nil {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+func (c *Client) GetIssue(repo string, number int) (*Issue, error) {
+	var issue Issue
+	if err := c.get(fmt.Sprintf("/repos/%s/issues/%d", repo, number), nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// NewIssue is the request body for CreateIssue.
+type NewIssue struct {
+	Title     string   `json:"title"`
+	Body      string   `json:"body,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+}
+
+func (c *Client) CreateIssue(repo string, in NewIssue) (*Issue, error) {
+	var issue Issue
+	if err := c.send(http.MethodPost, fmt.Sprintf("/repos/%s/issues", repo), in, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// IssueEdit is the request body for EditIssue; nil fields are left unchanged.
+type IssueEdit struct {
+	Title     *string `json:"title,omitempty"`
+	Body      *string `json:"body,omitempty"`
+	State     *string `json:"state,omitempty"`
+	Milestone *int    `json:"milestone,omitempty"`
+}
+
+func (c *Client) EditIssue(repo string, number int, in IssueEdit) (*Issue, error) {
+	var issue Issue
+	if err := c.send(http.MethodPatch, fmt.Sprintf("/repos/%s/issues/%d", repo, number), in, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// CloseIssue closes an issue as completed.
+func (c *Client) CloseIssue(repo string, number int) error {
+	state := "closed"
+	_, err := c.EditIssue(repo, number, IssueEdit{State: &state})
+	return err
+}
+
+func (c *Client) AddLabels(repo string, number int, labels ...string) error {
+	in := map[string][]string{"labels": labels}
+	return c.send(http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/labels", repo, number), in, nil)
+}
+
+// RemoveLabel removes a label from an issue. Removing a label the issue does
+// not have is not an error.
+func (c *Client) RemoveLabel(repo string, number int, label string) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d/labels/%s", repo, number, url.PathEscape(label))
+	if err := c.send(http.MethodDelete, path, nil, nil); err != nil && !IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (c *Client) AddAssignees(repo string, number int, logins ...string) error {
+	in := map[string][]string{"assignees": logins}
+	return c.send(http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/assignees", repo, number), in, nil)
+}
+
+func (c *Client) CreateComment(repo string, number int, body string) (*Comment, error) {
+	var comment Comment
+	in := map[string]string{"body": body}
+	if err := c.send(http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number), in, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (c *Client) ListComments(repo string, number int) ([]Comment, error) {
+	return getAll[Comment](c, fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number), nil)
+}
+
+func (c *Client) UpdateComment(repo string, id int64, body string) error {
+	in := map[string]string{"body": body}
+	return c.send(http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", repo, id), in, nil)
+}
+
+func (c *Client) DeleteComment(repo string, id int64) error {
+	return c.send(http.MethodDelete, fmt.Sprintf("/repos/%s/issues/comments/%d", repo, id), nil, nil)
+}
+
+func (c *Client) ListMilestones(repo string) ([]Milestone, error) {
+	return getAll[Milestone](c, fmt.Sprintf("/repos/%s/milestones", repo), url.Values{"state": {"open"}})
+}
+
+// SetMilestone sets an issue's or pull request's milestone by title.
+func (c *Client) SetMilestone(repo string, number int, title string) error {
+	milestones, err := c.ListMilestones(repo)
+	if err != nil {
+		return err
+	}
+	for _, m := range milestones {
+		if m.Title == title {
+			_, err := c.EditIssue(repo, number, IssueEdit{Milestone: &m.Number})
+			return err
+		}
+	}
+	return fmt.Errorf("milestone not found in %s: %s", repo, title)
+}
+
+// EnsureLabel creates a label, or updates its color and description if it
+// already exists.
+func (c *Client) EnsureLabel(repo string, label Label) error {
+	err := c.send(http.MethodPost, fmt.Sprintf("/repos/%s/labels", repo), label, nil)
+	apiErr, ok := err.(*APIError)
+	if !ok || !apiErr.HasCode("already_exists") {
+		return err
+	}
+	path := fmt.Sprintf("/repos/%s/labels/%s", repo, url.PathEscape(label.Name))
+	return c.send(http.MethodPatch, path, label, nil)
+}
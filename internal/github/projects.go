@@ -0,0 +1,305 @@
+package github
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Project is a GitHub Projects (v2) board.
+type Project struct {
+	ID     string
+	Title  string
+	Number int
+	URL    string
+	Fields []ProjectField
+}
+
+// ProjectField is a field of a project. Options is set for single-select
+// fields such as Status.
+type ProjectField struct {
+	ID      string
+	Name    string
+	Options []ProjectFieldOption
+}
+
+type ProjectFieldOption struct {
+	ID   string
+	Name string
+}
+
+// Field returns the field called name (case-insensitive), or nil.
+func (p *Project) Field(name string) *ProjectField {
+	for i := range p.Fields {
+		if strings.EqualFold(p.Fields[i].Name, name) {
+			return &p.Fields[i]
+		}
+	}
+	return nil
+}
+
+// Option returns the single-select option called name (case-insensitive),
+// or nil.
+func (f *ProjectField) Option(name string) *ProjectFieldOption {
+	for i := range f.Options {
+		if strings.EqualFold(f.Options[i].Name, name) {
+			return &f.Options[i]
+		}
+	}
+	return nil
+}
+
+// ProjectItem is an item on a project board: an issue, pull request or
+// draft issue.
+type ProjectItem struct {
+	ID      string
+	Type    string            // ISSUE, DRAFT_ISSUE, PULL_REQUEST or REDACTED
+	Fields  map[string]string // field name -> value, for single-select, text and number fields
+	Content ProjectItemContent
+}
+
+// ProjectItemContent is the issue or draft issue behind a project item.
+// Number, URL, Repo, Labels, Assignees and Milestone are only set for
+// issues.
+type ProjectItemContent struct {
+	ID        string
+	Number    int
+	Title     string
+	Body      string
+	URL       string
+	Repo      string
+	CreatedAt time.Time
+	Labels    []string
+	Assignees []string
+	Milestone string
+}
+
+const projectFieldsQuery = `query($owner: String!, $number: Int!) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        id title number url
+        fields(first: 50) {
+          nodes {
+            ... on ProjectV2FieldCommon { id name }
+            ... on ProjectV2SingleSelectField { options { id name } }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// GetProject returns project number of owner (an organization or user)
+// along with its fields.
+func (c *Client) GetProject(owner string, number int) (*Project, error) {
+	var out struct {
+		RepositoryOwner *struct {
+			ProjectV2 *struct {
+				ID     string `json:"id"`
+				Title  string `json:"title"`
+				Number int    `json:"number"`
+				URL    string `json:"url"`
+				Fields struct {
+					Nodes []struct {
+						ID      string               `json:"id"`
+						Name    string               `json:"name"`
+						Options []ProjectFieldOption `json:"options"`
+					} `json:"nodes"`
+				} `json:"fields"`
+			} `json:"projectV2"`
+		} `json:"repositoryOwner"`
+	}
+	if err := c.GraphQL(projectFieldsQuery, map[string]any{"owner": owner, "number": number}, &out); err != nil {
+		return nil, err
+	}
+	if out.RepositoryOwner == nil || out.RepositoryOwner.ProjectV2 == nil {
+		return nil, fmt.Errorf("project %s/%d not found", owner, number)
+	}
+	p := out.RepositoryOwner.ProjectV2
+	project := &Project{ID: p.ID, Title: p.Title, Number: p.Number, URL: p.URL}
+	for _, f := range p.Fields.Nodes {
+		if f.ID == "" {
+			continue
+		}
+		project.Fields = append(project.Fields, ProjectField{ID: f.ID, Name: f.Name, Options: f.Options})
+	}
+	return project, nil
+}
+
+// projectItemFields selects everything ProjectItem needs from a
+// ProjectV2Item.
+const projectItemFields = `
+      id type
+      fieldValues(first: 30) {
+        nodes {
+          ... on ProjectV2ItemFieldSingleSelectValue { name field { ... on ProjectV2FieldCommon { name } } }
+          ... on ProjectV2ItemFieldTextValue { text field { ... on ProjectV2FieldCommon { name } } }
+          ... on ProjectV2ItemFieldNumberValue { number field { ... on ProjectV2FieldCommon { name } } }
+        }
+      }
+      content {
+        ... on DraftIssue { id title body createdAt }
+        ... on Issue {
+          id number title body url createdAt
+          repository { nameWithOwner }
+          labels(first: 30) { nodes { name } }
+          assignees(first: 10) { nodes { login } }
+          milestone { title }
+        }
+      }`
+
+type projectItemNode struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	FieldValues struct {
+		Nodes []struct {
+			Name   *string  `json:"name"`
+			Text   *string  `json:"text"`
+			Number *float64 `json:"number"`
+			Field  struct {
+				Name string `json:"name"`
+			} `json:"field"`
+		} `json:"nodes"`
+	} `json:"fieldValues"`
+	Content *struct {
+		ID         string    `json:"id"`
+		Number     int       `json:"number"`
+		Title      string    `json:"title"`
+		Body       string    `json:"body"`
+		URL        string    `json:"url"`
+		CreatedAt  time.Time `json:"createdAt"`
+		Repository *struct {
+			NameWithOwner string `json:"nameWithOwner"`
+		} `json:"repository"`
+		Labels *struct {
+			Nodes []Label `json:"nodes"`
+		} `json:"labels"`
+		Assignees *struct {
+			Nodes []User `json:"nodes"`
+		} `json:"assignees"`
+		Milestone *Milestone `json:"milestone"`
+	} `json:"content"`
+}
+
+func (n projectItemNode) item() ProjectItem {
+	item := ProjectItem{ID: n.ID, Type: n.Type, Fields: map[string]string{}}
+	for _, v := range n.FieldValues.Nodes {
+		switch {
+		case v.Field.Name == "":
+		case v.Name != nil:
+			item.Fields[v.Field.Name] = *v.Name
+		case v.Text != nil:
+			item.Fields[v.Field.Name] = *v.Text
+		case v.Number != nil:
+			item.Fields[v.Field.Name] = strconv.FormatFloat(*v.Number, 'f', -1, 64)
+		}
+	}
+	if c := n.Content; c != nil {
+		item.Content = ProjectItemContent{
+			ID: c.ID, Number: c.Number, Title: c.Title, Body: c.Body, URL: c.URL, CreatedAt: c.CreatedAt,
+		}
+		if c.Repository != nil {
+			item.Content.Repo = c.Repository.NameWithOwner
+		}
+		if c.Labels != nil {
+			for _, l := range c.Labels.Nodes {
+				item.Content.Labels = append(item.Content.Labels, l.Name)
+			}
+		}
+		if c.Assignees != nil {
+			for _, a := range c.Assignees.Nodes {
+				item.Content.Assignees = append(item.Content.Assignees, a.Login)
+			}
+		}
+		if c.Milestone != nil {
+			item.Content.Milestone = c.Milestone.Title
+		}
+	}
+	return item
+}
+
+const projectItemsQuery = `query($id: ID!, $after: String) {
+  node(id: $id) {
+    ... on ProjectV2 {
+      items(first: 100, after: $after) {
+        pageInfo { hasNextPage endCursor }
+        nodes {` + projectItemFields + `
+        }
+      }
+    }
+  }
+}`
+
+// ListProjectItems returns every item on the project, following pagination.
+func (c *Client) ListProjectItems(projectID string) ([]ProjectItem, error) {
+	var items []ProjectItem
+	var after *string
+	for {
+		var out struct {
+			Node struct {
+				Items struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []projectItemNode `json:"nodes"`
+				} `json:"items"`
+			} `json:"node"`
+		}
+		if err := c.GraphQL(projectItemsQuery, map[string]any{"id": projectID, "after": after}, &out); err != nil {
+			return nil, err
+		}
+		for _, n := range out.Node.Items.Nodes {
+			items = append(items, n.item())
+		}
+		if !out.Node.Items.PageInfo.HasNextPage {
+			return items, nil
+		}
+		cursor := out.Node.Items.PageInfo.EndCursor
+		after = &cursor
+	}
+}
+
+const projectItemQuery = `query($id: ID!) {
+  node(id: $id) {
+    ... on ProjectV2Item {` + projectItemFields + `
+    }
+  }
+}`
+
+// GetProjectItem returns a single project item by node ID.
+func (c *Client) GetProjectItem(itemID string) (*ProjectItem, error) {
+	var out struct {
+		Node *projectItemNode `json:"node"`
+	}
+	if err := c.GraphQL(projectItemQuery, map[string]any{"id": itemID}, &out); err != nil {
+		return nil, err
+	}
+	if out.Node == nil || out.Node.ID == "" {
+		return nil, fmt.Errorf("project item %s not found", itemID)
+	}
+	item := out.Node.item()
+	return &item, nil
+}
+
+// SetProjectItemOption sets a single-select field (e.g. Status) of an item.
+func (c *Client) SetProjectItemOption(projectID, itemID, fieldID, optionID string) error {
+	const mutation = `mutation($project: ID!, $item: ID!, $field: ID!, $option: String!) {
+  updateProjectV2ItemFieldValue(input: {projectId: $project, itemId: $item, fieldId: $field, value: {singleSelectOptionId: $option}}) {
+    projectV2Item { id }
+  }
+}`
+	return c.GraphQL(mutation, map[string]any{
+		"project": projectID, "item": itemID, "field": fieldID, "option": optionID,
+	}, nil)
+}
+
+// UpdateDraftIssueBody replaces the body of a draft issue.
+func (c *Client) UpdateDraftIssueBody(draftID, body string) error {
+	const mutation = `mutation($id: ID!, $body: String!) {
+  updateProjectV2DraftIssue(input: {draftIssueId: $id, body: $body}) { draftIssue { id } }
+}`
+	return c.GraphQL(mutation, map[string]any{"id": draftID, "body": body}, nil)
+}
@@ -0,0 +1,59 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListProjectItems_Paginates(t *testing.T) {
+	var cursors []any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		cursors = append(cursors, req.Variables["after"])
+		if req.Variables["after"] == nil {
+			fmt.Fprint(w, `{"data":{"node":{"items":{"pageInfo":{"hasNextPage":true,"endCursor":"c1"},"nodes":[
+			  {"id":"PVTI_1","type":"ISSUE","fieldValues":{"nodes":[
+			    {"name":"Ready","field":{"name":"Status"}},{"number":2,"field":{"name":"Estimate"}},{}]},
+			   "content":{"id":"I_1","number":5,"title":"One","repository":{"nameWithOwner":"o/r"},"labels":{"nodes":[{"name":"bug"}]}}}]}}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"node":{"items":{"pageInfo":{"hasNextPage":false},"nodes":[
+		  {"id":"PVTI_2","type":"DRAFT_ISSUE","fieldValues":{"nodes":[]},"content":{"id":"DI_2","title":"Two","body":"b"}}]}}}}`)
+	}))
+	defer srv.Close()
+
+	items, err := NewClient("t", WithBaseURL(srv.URL)).ListProjectItems("PVT_1")
+	if err != nil {
+		t.Fatalf("ListProjectItems error: %v", err)
+	}
+	if len(items) != 2 || len(cursors) != 2 || cursors[1] != "c1" {
+		t.Fatalf("items = %+v, cursors = %v", items, cursors)
+	}
+	first := items[0]
+	if first.Fields["Status"] != "Ready" || first.Fields["Estimate"] != "2" {
+		t.Errorf("unexpected fields: %v", first.Fields)
+	}
+	if first.Content.Repo != "o/r" || first.Content.Number != 5 || len(first.Content.Labels) != 1 {
+		t.Errorf("unexpected content: %+v", first.Content)
+	}
+	if items[1].Type != "DRAFT_ISSUE" || items[1].Content.ID != "DI_2" {
+		t.Errorf("unexpected draft: %+v", items[1])
+	}
+}
+
+func TestGetProject_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"repositoryOwner":{"projectV2":null}}}`)
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient("t", WithBaseURL(srv.URL)).GetProject("o", 9); err == nil {
+		t.Fatal("expected error for missing project")
+	}
+}
@@ -0,0 +1,149 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NewPullRequest is the request body for CreatePullRequest. Head is a branch
+// name, or "owner:branch" for a branch in a fork.
+type NewPullRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+}
+
+func (c *Client) CreatePullRequest(repo string, in NewPullRequest) (*PullRequest, error) {
+	var pr PullRequest
+	if err := c.send(http.MethodPost, fmt.Sprintf("/repos/%s/pulls", repo), in, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+func (c *Client) GetPullRequest(repo string, number int) (*PullRequest, error) {
+	var pr PullRequest
+	if err := c.get(fmt.Sprintf("/repos/%s/pulls/%d", repo, number), nil, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// ListPullRequests returns the pull requests in repo whose head is head
+// ("owner:branch") and whose state is state ("open", "closed" or "all").
+func (c *Client) ListPullRequests(repo, head, state string) ([]PullRequest, error) {
+	params := url.Values{}
+	if head != "" {
+		params.Set("head", head)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+	return getAll[PullRequest](c, fmt.Sprintf("/repos/%s/pulls", repo), params)
+}
+
+// PullRequestEdit is the request body for EditPullRequest; nil fields are
+// left unchanged.
+type PullRequestEdit struct {
+	Title *string `json:"title,omitempty"`
+	Body  *string `json:"body,omitempty"`
+	Base  *string `json:"base,omitempty"`
+}
+
+func (c *Client) EditPullRequest(repo string, number int, in PullRequestEdit) (*PullRequest, error) {
+	var pr PullRequest
+	if err := c.send(http.MethodPatch, fmt.Sprintf("/repos/%s/pulls/%d", repo, number), in, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// MergePullRequest merges a pull request now. method is "merge", "squash"
+// or "rebase".
+func (c *Client) MergePullRequest(repo string, number int, method string) error {
+	in := map[string]string{"merge_method": method}
+	return c.send(http.MethodPut, fmt.Sprintf("/repos/%s/pulls/%d/merge", repo, number), in, nil)
+}
+
+// RequestReviewers requests reviews from users and teams. Reviewers of the
+// form "org/team" are requested as teams.
+func (c *Client) RequestReviewers(repo string, number int, reviewers ...string) error {
+	in := struct {
+		Reviewers     []string `json:"reviewers,omitempty"`
+		TeamReviewers []string `json:"team_reviewers,omitempty"`
+	}{}
+	for _, r := range reviewers {
+		if _, team, ok := strings.Cut(r, "/"); ok {
+			in.TeamReviewers = append(in.TeamReviewers, team)
+		} else {
+			in.Reviewers = append(in.Reviewers, r)
+		}
+	}
+	return c.send(http.MethodPost, fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", repo, number), in, nil)
+}
+
+// EnableAutoMerge turns on auto-merge for the pull request with the given
+// GraphQL node ID, so it merges once required checks and reviews pass.
+// method is "MERGE", "SQUASH" or "REBASE".
+func (c *Client) EnableAutoMerge(nodeID, method string) error {
+	const mutation = `mutation($id: ID!, $method: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) { clientMutationId }
+}`
+	return c.GraphQL(mutation, map[string]any{"id": nodeID, "method": method}, nil)
+}
+
+// AutoMerge enables auto-merge on a pull request. When GitHub refuses
+// because the pull request is already mergeable ("clean status"), it is
+// merged right away; any other refusal, such as auto-merge being disabled
+// for the repository or checks still pending, is returned. method is
+// "merge", "squash" or "rebase".
+func (c *Client) AutoMerge(repo string, number int, method string) error {
+	pr, err := c.GetPullRequest(repo, number)
+	if err != nil {
+		return err
+	}
+	autoErr := c.EnableAutoMerge(pr.NodeID, strings.ToUpper(method))
+	if autoErr == nil {
+		return nil
+	}
+	if !strings.Contains(strings.ToLower(autoErr.Error()), "clean status") {
+		return fmt.Errorf("enable auto-merge: %w", autoErr)
+	}
+	if err := c.MergePullRequest(repo, number, method); err != nil {
+		return fmt.Errorf("merge: %w", err)
+	}
+	return nil
+}
+
+// Review events for CreateReview. A pull request's author can only COMMENT
+// on it.
+const (
+	ReviewComment        = "COMMENT"
+	ReviewApprove        = "APPROVE"
+	ReviewRequestChanges = "REQUEST_CHANGES"
+)
+
+// NewReview is the request body for CreateReview.
+type NewReview struct {
+	Body  string `json:"body"`
+	Event string `json:"event"`
+}
+
+// Review is a submitted pull request review.
+type Review struct {
+	ID      int64  `json:"id"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+}
+
+// CreateReview submits a review on a pull request.
+func (c *Client) CreateReview(repo string, number int, in NewReview) (*Review, error) {
+	var review Review
+	if err := c.send(http.MethodPost, fmt.Sprintf("/repos/%s/pulls/%d/reviews", repo, number), in, &review); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
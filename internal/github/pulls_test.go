@@ -0,0 +1,187 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreatePullRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/o/r/pulls" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var in NewPullRequest
+		json.NewDecoder(r.Body).Decode(&in)
+		if in.Head != "fork:task/1-x" || in.Base != "main" {
+			t.Errorf("unexpected head/base: %+v", in)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number":7,"node_id":"PR_7","html_url":"https://github.com/o/r/pull/7"}`)
+	}))
+	defer srv.Close()
+
+	pr, err := NewClient("t", WithBaseURL(srv.URL)).CreatePullRequest("o/r", NewPullRequest{
+		Title: "T", Body: "B", Head: "fork:task/1-x", Base: "main",
+	})
+	if err != nil {
+		t.Fatalf("CreatePullRequest error: %v", err)
+	}
+	if pr.Number != 7 || pr.HTMLURL != "https://github.com/o/r/pull/7" {
+		t.Errorf("unexpected PR: %+v", pr)
+	}
+}
+
+func TestAutoMerge_FallsBackToDirectMerge(t *testing.T) {
+	var merged string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/pulls/7":
+			fmt.Fprint(w, `{"number":7,"node_id":"PR_7"}`)
+		case r.URL.Path == "/graphql":
+			fmt.Fprint(w, `{"errors":[{"message":"Pull request is in clean status"}]}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/repos/o/r/pulls/7/merge":
+			var in map[string]string
+			json.NewDecoder(r.Body).Decode(&in)
+			merged = in["merge_method"]
+			fmt.Fprint(w, `{"merged":true}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	if err := NewClient("t", WithBaseURL(srv.URL)).AutoMerge("o/r", 7, "squash"); err != nil {
+		t.Fatalf("AutoMerge error: %v", err)
+	}
+	if merged != "squash" {
+		t.Errorf("expected direct squash merge, got %q", merged)
+	}
+}
+
+func TestAutoMerge_OtherRefusalsDoNotMerge(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/pulls/7":
+			fmt.Fprint(w, `{"number":7,"node_id":"PR_7"}`)
+		case r.URL.Path == "/graphql":
+			fmt.Fprint(w, `{"errors":[{"message":"Auto merge is not allowed for this repository"}]}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	err := NewClient("t", WithBaseURL(srv.URL)).AutoMerge("o/r", 7, "squash")
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("AutoMerge error = %v, want the auto-merge refusal", err)
+	}
+}
+
+func TestRequestReviewers_SplitsTeams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var in struct {
+			Reviewers     []string `json:"reviewers"`
+			TeamReviewers []string `json:"team_reviewers"`
+		}
+		json.NewDecoder(r.Body).Decode(&in)
+		if strings.Join(in.Reviewers, ",") != "alice" || strings.Join(in.TeamReviewers, ",") != "backend" {
+			t.Errorf("unexpected reviewers: %+v", in)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+
+	if err := NewClient("t", WithBaseURL(srv.URL)).RequestReviewers("o/r", 7, "alice", "acme/backend"); err != nil {
+		t.Fatalf("RequestReviewers error: %v", err)
+	}
+}
+
+func TestRemoveLabel_IgnoresMissing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/repos/o/r/issues/3/labels/in%20progress" {
+			t.Errorf("unexpected path: %s", r.URL.EscapedPath())
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Label does not exist"}`)
+	}))
+	defer srv.Close()
+
+	if err := NewClient("t", WithBaseURL(srv.URL)).RemoveLabel("o/r", 3, "in progress"); err != nil {
+		t.Errorf("expected missing label to be ignored, got %v", err)
+	}
+}
+
+func TestEnsureLabel_UpdatesExisting(t *testing.T) {
+	var patched bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"Validation Failed","errors":[{"resource":"Label","code":"already_exists","field":"name"}]}`)
+		case http.MethodPatch:
+			patched = r.URL.Path == "/repos/o/r/labels/devpilot"
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+
+	if err := NewClient("t", WithBaseURL(srv.URL)).EnsureLabel("o/r", Label{Name: "devpilot", Color: "0075ca"}); err != nil {
+		t.Fatalf("EnsureLabel error: %v", err)
+	}
+	if !patched {
+		t.Error("expected existing label to be updated")
+	}
+}
+
+func TestSetMilestone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/o/r/milestones":
+			fmt.Fprint(w, `[{"number":4,"title":"v1.0"}]`)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/o/r/issues/7":
+			var in map[string]int
+			json.NewDecoder(r.Body).Decode(&in)
+			if in["milestone"] != 4 {
+				t.Errorf("expected milestone 4, got %v", in)
+			}
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient("t", WithBaseURL(srv.URL))
+	if err := c.SetMilestone("o/r", 7, "v1.0"); err != nil {
+		t.Fatalf("SetMilestone error: %v", err)
+	}
+	if err := c.SetMilestone("o/r", 7, "v9"); err == nil {
+		t.Error("expected error for unknown milestone")
+	}
+}
+
+func TestCreateReview(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/o/r/pulls/7/reviews" {
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var in NewReview
+		json.NewDecoder(r.Body).Decode(&in)
+		if in.Event != ReviewComment || in.Body != "looks good" {
+			t.Errorf("unexpected review: %+v", in)
+		}
+		fmt.Fprint(w, `{"id":3,"state":"COMMENTED","html_url":"https://github.com/o/r/pull/7#pullrequestreview-3"}`)
+	}))
+	defer srv.Close()
+
+	review, err := NewClient("t", WithBaseURL(srv.URL)).CreateReview("o/r", 7, NewReview{Body: "looks good", Event: ReviewComment})
+	if err != nil {
+		t.Fatalf("CreateReview error: %v", err)
+	}
+	if review.ID != 3 || review.State != "COMMENTED" {
+		t.Errorf("unexpected review: %+v", review)
+	}
+}
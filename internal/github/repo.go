@@ -0,0 +1,99 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const defaultHost = "github.com"
+
+// normalizeHost strips any scheme and trailing slash from host; empty means
+// github.com.
+func normalizeHost(host string) string {
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	host = strings.TrimRight(host, "/")
+	if host == "" || host == "api.github.com" {
+		return defaultHost
+	}
+	return host
+}
+
+// APIBaseURL returns the REST API base URL for host: api.github.com for
+// github.com (or an empty host), https://<host>/api/v3 for GitHub
+// Enterprise Server.
+func APIBaseURL(host string) string {
+	host = normalizeHost(host)
+	if host == defaultHost {
+		return defaultBaseURL
+	}
+	return "https://" + host + "/api/v3"
+}
+
+// CloneURL returns the HTTPS clone URL of repo ("owner/repo") on host.
+// Authentication is left to git (credential helper or url.insteadOf).
+func CloneURL(host, repo string) string {
+	return "https://" + normalizeHost(host) + "/" + repo + ".git"
+}
+
+// ParseRemoteURL extracts "owner/repo" from a git remote URL in any of the
+// forms git accepts: https://host/owner/repo(.git), git@host:owner/repo(.git)
+// or ssh://git@host/owner/repo(.git).
+func ParseRemoteURL(remoteURL string) (string, error) {
+	path := remoteURL
+	if u, err := url.Parse(remoteURL); err == nil && u.Scheme != "" && u.Host != "" {
+		path = u.Path
+	} else if _, rest, ok := strings.Cut(remoteURL, ":"); ok && !strings.Contains(remoteURL, "://") {
+		path = rest
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[len(parts)-2] == "" || parts[len(parts)-1] == "" {
+		return "", fmt.Errorf("cannot determine owner/repo from remote URL %q", remoteURL)
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1], nil
+}
+
+// RepoFromRemote returns "owner/repo" for a git remote of the repository in
+// dir.
+func RepoFromRemote(dir, remote string) (string, error) {
+	cmd := exec.Command("git", "remote", "get-url", remote)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git remote get-url %s: %w", remote, err)
+	}
+	return ParseRemoteURL(strings.TrimSpace(string(out)))
+}
+
+// ParseIssueURL splits an issue or pull request URL such as
+// https://github.com/owner/repo/pull/42 into "owner/repo" and 42.
+func ParseIssueURL(rawURL string) (string, int, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, fmt.Errorf("parse URL %q: %w", rawURL, err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || (parts[2] != "pull" && parts[2] != "issues") {
+		return "", 0, fmt.Errorf("not an issue or pull request URL: %s", rawURL)
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return "", 0, fmt.Errorf("not an issue or pull request URL: %s", rawURL)
+	}
+	return parts[0] + "/" + parts[1], number, nil
+}
+
+// ResolveRepo returns repo when set, otherwise the repository that remote
+// (default "origin") of the git checkout in dir points at.
+func ResolveRepo(dir, repo, remote string) (string, error) {
+	if repo != "" {
+		return repo, nil
+	}
+	if remote == "" {
+		remote = "origin"
+	}
+	return RepoFromRemote(dir, remote)
+}
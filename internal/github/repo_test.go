@@ -0,0 +1,53 @@
+package github
+
+import "testing"
+
+func TestParseRemoteURL(t *testing.T) {
+	cases := map[string]string{
+		"https://github.com/acme/app.git":         "acme/app",
+		"https://github.com/acme/app":             "acme/app",
+		"git@github.com:acme/app.git":             "acme/app",
+		"ssh://git@ghe.example.com:2222/acme/app": "acme/app",
+		"https://ghe.example.com/acme/app.git/":   "acme/app",
+	}
+	for in, want := range cases {
+		got, err := ParseRemoteURL(in)
+		if err != nil || got != want {
+			t.Errorf("ParseRemoteURL(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseRemoteURL("app.git"); err == nil {
+		t.Error("expected error for a URL without owner/repo")
+	}
+}
+
+func TestParseIssueURL(t *testing.T) {
+	repo, n, err := ParseIssueURL("https://github.com/acme/app/pull/42")
+	if err != nil || repo != "acme/app" || n != 42 {
+		t.Errorf("got %q %d %v", repo, n, err)
+	}
+	repo, n, err = ParseIssueURL("https://ghe.example.com/acme/app/issues/7")
+	if err != nil || repo != "acme/app" || n != 7 {
+		t.Errorf("got %q %d %v", repo, n, err)
+	}
+	if _, _, err := ParseIssueURL("https://github.com/acme/app"); err == nil {
+		t.Error("expected error for a repo URL")
+	}
+}
+
+func TestAPIBaseURL(t *testing.T) {
+	cases := map[string]string{
+		"":                         "https://api.github.com",
+		"github.com":               "https://api.github.com",
+		"ghe.example.com":          "https://ghe.example.com/api/v3",
+		"https://ghe.example.com/": "https://ghe.example.com/api/v3",
+	}
+	for host, want := range cases {
+		if got := APIBaseURL(host); got != want {
+			t.Errorf("APIBaseURL(%q) = %q, want %q", host, got, want)
+		}
+	}
+	if got := CloneURL("ghe.example.com", "acme/app"); got != "https://ghe.example.com/acme/app.git" {
+		t.Errorf("CloneURL = %q", got)
+	}
+}
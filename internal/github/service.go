@@ -0,0 +1,120 @@
+package github
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/siyuqian/devpilot/internal/auth"
+)
+
+func init() {
+	auth.Register(NewGitHubService())
+}
+
+type GitHubService struct {
+	baseURL string
+}
+
+func NewGitHubService() *GitHubService {
+	return &GitHubService{baseURL: defaultBaseURL}
+}
+
+func (s *GitHubService) Name() string {
+	return "github"
+}
+
+func (s *GitHubService) Login() error {
+	fmt.Println("GitHub Login")
+	fmt.Println("============")
+	fmt.Println()
+	fmt.Println("To authenticate, you need a personal access token:")
+	fmt.Println()
+	fmt.Println("1. Go to https://github.com/settings/tokens")
+	fmt.Println("2. Create a fine-grained token with read/write access to Issues,")
+	fmt.Println("   Pull requests and Contents on your repositories")
+	fmt.Println("3. Copy the token")
+	fmt.Println()
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Host (leave empty for github.com, or your GitHub Enterprise host): ")
+	host, _ := reader.ReadString('\n')
+	host = normalizeHost(strings.TrimSpace(host))
+
+	fmt.Print("Token: ")
+	token, _ := reader.ReadString('\n')
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("a token is required")
+	}
+
+	baseURL := s.baseURL
+	if host != defaultHost {
+		baseURL = APIBaseURL(host)
+	}
+	fmt.Print("Verifying token... ")
+	login, err := NewClient(token, WithBaseURL(baseURL)).CurrentUser()
+	if err != nil {
+		fmt.Println("failed")
+		return err
+	}
+	fmt.Println("ok")
+
+	creds := auth.ServiceCredentials{"token": token, "login": login, "host": host}
+	if err := auth.Save(s.Name(), creds); err != nil {
+		return fmt.Errorf("failed to save credentials: %w", err)
+	}
+	fmt.Printf("Logged in to GitHub as %s.\n", login)
+	return nil
+}
+
+func (s *GitHubService) Logout() error {
+	if err := auth.Remove(s.Name()); err != nil {
+		return err
+	}
+	fmt.Println("Logged out of GitHub.")
+	return nil
+}
+
+func (s *GitHubService) IsLoggedIn() bool {
+	_, err := auth.Load(s.Name())
+	return err == nil
+}
+
+// CurrentUser returns the login of the authenticated user.
+func (c *Client) CurrentUser() (string, error) {
+	var u User
+	if err := c.get("/user", nil, &u); err != nil {
+		return "", err
+	}
+	return u.Login, nil
+}
+
+// LoadToken returns a token for host (empty means github.com) from, in
+// order: the environment (GITHUB_TOKEN or GH_TOKEN for github.com,
+// GH_ENTERPRISE_TOKEN or GITHUB_ENTERPRISE_TOKEN otherwise), credentials
+// saved by 'devpilot login github' for that host, or an existing gh CLI
+// login.
+func LoadToken(host string) (string, error) {
+	host = normalizeHost(host)
+	envs := []string{"GITHUB_TOKEN", "GH_TOKEN"}
+	if host != defaultHost {
+		envs = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	}
+	for _, env := range envs {
+		if token := os.Getenv(env); token != "" {
+			return token, nil
+		}
+	}
+	if creds, err := auth.Load("github"); err == nil && creds["token"] != "" && normalizeHost(creds["host"]) == host {
+		return creds["token"], nil
+	}
+	if out, err := exec.Command("gh", "auth", "token", "--hostname", host).Output(); err == nil {
+		if token := strings.TrimSpace(string(out)); token != "" {
+			return token, nil
+		}
+	}
+	return "", fmt.Errorf("Not logged in to GitHub (%s). Run: devpilot login github", host)
+}
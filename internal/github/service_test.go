@@ -0,0 +1,32 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/siyuqian/devpilot/internal/auth"
+)
+
+func TestLoadToken(t *testing.T) {
+	restore := auth.OverrideConfigDir(t.TempDir())
+	defer restore()
+	for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("PATH", "") // no gh CLI fallback
+
+	auth.Save("github", auth.ServiceCredentials{"token": "saved", "host": "ghe.example.com"})
+	if token, err := LoadToken("ghe.example.com"); err != nil || token != "saved" {
+		t.Errorf("LoadToken(ghe) = %q, %v; want saved credentials", token, err)
+	}
+	if _, err := LoadToken(""); err == nil {
+		t.Error("credentials for another host must not be used for github.com")
+	}
+
+	t.Setenv("GITHUB_TOKEN", "from-env")
+	if token, _ := LoadToken(""); token != "from-env" {
+		t.Errorf("LoadToken(\"\") = %q, want from-env", token)
+	}
+	if token, _ := LoadToken("ghe.example.com"); token != "saved" {
+		t.Errorf("GITHUB_TOKEN must not be sent to an Enterprise host, got %q", token)
+	}
+}
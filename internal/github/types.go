@@ -0,0 +1,64 @@
+package github
+
+import "time"
+
+type User struct {
+	Login string `json:"login"`
+}
+
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type Milestone struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+type Issue struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	HTMLURL   string     `json:"html_url"`
+	Labels    []Label    `json:"labels"`
+	Assignees []User     `json:"assignees"`
+	Milestone *Milestone `json:"milestone"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// PullRequest is set when the issue is a pull request; the issues API
+	// lists both.
+	PullRequest *struct{} `json:"pull_request,omitempty"`
+}
+
+type Comment struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	User      User      `json:"user"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PullRequest struct {
+	Number  int    `json:"number"`
+	NodeID  string `json:"node_id"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	State   string `json:"state"` // "open" or "closed"
+	Merged  bool   `json:"merged"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+type Repository struct {
+	FullName       string `json:"full_name"`
+	DefaultBranch  string `json:"default_branch"`
+	AllowAutoMerge bool   `json:"allow_auto_merge"`
+}
@@ -64,8 +64,9 @@ type MessageRef struct {
 }
 
 type Message struct {
-	ID      string  `json:"id"`
-	Payload Payload `json:"payload"`
+	ID       string  `json:"id"`
+	ThreadID string  `json:"threadId"`
+	Payload  Payload `json:"payload"`
 }
 
 type Payload struct {
@@ -73,6 +74,7 @@ type Payload struct {
 	Body     Body      `json:"body"`
 	Parts    []Payload `json:"parts"`
 	MimeType string    `json:"mimeType"`
+	Filename string    `json:"filename"` // set on attachments
 }
 
 type Header struct {
@@ -81,8 +83,9 @@ type Header struct {
 }
 
 type Body struct {
-	Data string `json:"data"`
-	Size int    `json:"size"`
+	Data         string `json:"data"`
+	Size         int    `json:"size"`
+	AttachmentID string `json:"attachmentId"` // set when the data is fetched separately
 }
 
 func (c *Client) refreshIfNeeded() error {
@@ -159,19 +162,24 @@ func (c *Client) doRequest(method, path string, params url.Values) ([]byte, erro
 		if err != nil {
 			return nil, fmt.Errorf("read retry body failed: %w", err)
 		}
-		if resp2.StatusCode != http.StatusOK {
+		if resp2.StatusCode != http.StatusOK && resp2.StatusCode != http.StatusNoContent {
 			return nil, fmt.Errorf("HTTP %d: %s", resp2.StatusCode, string(body))
 		}
 		return body, nil
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
 	}
 	return body, nil
 }
 
 func (c *Client) doPost(path string, payload any) ([]byte, error) {
+	return c.doJSON(http.MethodPost, path, payload)
+}
+
+// doJSON sends payload as a JSON request body.
+func (c *Client) doJSON(method, path string, payload any) ([]byte, error) {
 	if err := c.refreshIfNeeded(); err != nil {
 		return nil, err
 	}
@@ -183,7 +191,7 @@ func (c *Client) doPost(path string, payload any) ([]byte, error) {
 		return nil, fmt.Errorf("marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, reqURL, strings.NewReader(string(jsonBody)))
+	req, err := http.NewRequest(method, reqURL, strings.NewReader(string(jsonBody)))
 	if err != nil {
 		return nil, fmt.Errorf("create request failed: %w", err)
 	}
@@ -229,7 +237,20 @@ func (c *Client) ListMessages(query string, limit int) ([]MessageRef, error) {
 }
 
 func (c *Client) ListAllMessageIDs(query string) ([]string, error) {
-	var allIDs []string
+	refs, err := c.ListAllMessages(query)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(refs))
+	for i, r := range refs {
+		ids[i] = r.ID
+	}
+	return ids, nil
+}
+
+// ListAllMessages is ListAllMessageIDs keeping each message's thread.
+func (c *Client) ListAllMessages(query string) ([]MessageRef, error) {
+	var all []MessageRef
 	pageToken := ""
 	for {
 		params := url.Values{}
@@ -250,15 +271,13 @@ func (c *Client) ListAllMessageIDs(query string) ([]string, error) {
 		if err := json.Unmarshal(data, &resp); err != nil {
 			return nil, fmt.Errorf("parse message list: %w", err)
 		}
-		for _, m := range resp.Messages {
-			allIDs = append(allIDs, m.ID)
-		}
+		all = append(all, resp.Messages...)
 		if resp.NextPageToken == "" {
 			break
 		}
 		pageToken = resp.NextPageToken
 	}
-	return allIDs, nil
+	return all, nil
 }
 
 func (c *Client) GetMessage(id string) (*Message, error) {
@@ -276,14 +295,55 @@ func (c *Client) GetMessage(id string) (*Message, error) {
 }
 
 func (c *Client) BatchModify(ids []string, removeLabelIds []string) error {
-	payload := map[string]any{
-		"ids":            ids,
-		"removeLabelIds": removeLabelIds,
+	return c.BatchModifyLabels(ids, nil, removeLabelIds)
+}
+
+// BatchModifyLabels adds and removes labels on up to 1000 messages.
+func (c *Client) BatchModifyLabels(ids, addLabelIds, removeLabelIds []string) error {
+	payload := map[string]any{"ids": ids}
+	if len(addLabelIds) > 0 {
+		payload["addLabelIds"] = addLabelIds
+	}
+	if len(removeLabelIds) > 0 {
+		payload["removeLabelIds"] = removeLabelIds
 	}
 	_, err := c.doPost("/gmail/v1/users/me/messages/batchModify", payload)
 	return err
 }
 
+// Profile is the signed-in Gmail account.
+type Profile struct {
+	EmailAddress string `json:"emailAddress"`
+	HistoryID    string `json:"historyId"` // the mailbox's current position, for NewMessages
+}
+
+// Profile returns the signed-in account.
+func (c *Client) Profile() (*Profile, error) {
+	data, err := c.doRequest(http.MethodGet, "/gmail/v1/users/me/profile", nil)
+	if err != nil {
+		return nil, err
+	}
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse profile: %w", err)
+	}
+	return &p, nil
+}
+
+// SendMessage sends an RFC 2822 message and returns its ID.
+func (c *Client) SendMessage(raw []byte) (string, error) {
+	payload := map[string]string{"raw": base64.URLEncoding.EncodeToString(raw)}
+	data, err := c.doPost("/gmail/v1/users/me/messages/send", payload)
+	if err != nil {
+		return "", err
+	}
+	var ref MessageRef
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return "", fmt.Errorf("parse sent message: %w", err)
+	}
+	return ref.ID, nil
+}
+
 // GetHeader extracts a header value from a message by name.
 func GetHeader(msg *Message, name string) string {
 	for _, h := range msg.Payload.Headers {
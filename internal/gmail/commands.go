@@ -1,13 +1,19 @@
 package gmail
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/siyuqian/devpilot/internal/auth"
+	"github.com/siyuqian/devpilot/internal/project"
+	"github.com/siyuqian/devpilot/internal/slack"
+	"github.com/siyuqian/devpilot/internal/trello"
 	"github.com/spf13/cobra"
 )
 
@@ -27,12 +33,65 @@ func RegisterCommands(parent *cobra.Command) {
 	summaryCmd.Flags().String("channel", "", "Send summary to a Slack channel")
 	summaryCmd.Flags().String("dm", "", "Send summary as a DM to a Slack user ID")
 	summaryCmd.Flags().Bool("no-mark-read", false, "Skip marking emails as read (preview mode)")
+	summaryCmd.Flags().Bool("since-last-run", false, "Only summarize unread emails that arrived since the last --since-last-run summary")
+	summaryCmd.Flags().Bool("no-threads", false, "Summarize emails one by one instead of by conversation")
+	summaryCmd.Flags().Bool("attachment-text", false, "Include the text of small text and PDF attachments (PDFs need pdftotext)")
+
+	triageCmd.Flags().String("query", "is:unread", "Gmail search query selecting the emails to triage")
+	triageCmd.Flags().Int("limit", 50, "Maximum number of emails to triage")
+	triageCmd.Flags().Bool("apply", false, "Create tasks, labels and drafts (default: only show what would be done)")
+	triageCmd.Flags().Bool("no-tasks", false, "Don't create tasks for action emails")
+	triageCmd.Flags().Bool("no-labels", false, "Don't label emails by category")
+	triageCmd.Flags().Bool("no-drafts", false, "Don't draft suggested replies")
+	triageCmd.Flags().String("label-prefix", "devpilot/", "Prefix of the category labels")
+	triageCmd.Flags().String("source", "", "Task source: trello or github (default from .devpilot.yaml, fallback to trello)")
+	triageCmd.Flags().String("board", "", "Trello board name (default from .devpilot.yaml)")
+	triageCmd.Flags().String("list", "Inbox", "Trello list for tasks")
 
 	gmailCmd.AddCommand(listCmd)
 	gmailCmd.AddCommand(readCmd)
 	gmailCmd.AddCommand(markReadCmd)
 	gmailCmd.AddCommand(bulkMarkReadCmd)
 	gmailCmd.AddCommand(summaryCmd)
+	gmailCmd.AddCommand(triageCmd)
+
+	for _, c := range []*cobra.Command{labelApplyCmd, labelRemoveCmd, labelArchiveCmd, labelTrashCmd} {
+		c.Flags().String("query", "", "Gmail search query selecting the emails (instead of message IDs)")
+	}
+	labelCmd.AddCommand(labelListCmd)
+	labelCmd.AddCommand(labelCreateCmd)
+	labelCmd.AddCommand(labelRenameCmd)
+	labelCmd.AddCommand(labelDeleteCmd)
+	labelCmd.AddCommand(labelApplyCmd)
+	labelCmd.AddCommand(labelRemoveCmd)
+	labelCmd.AddCommand(labelArchiveCmd)
+	labelCmd.AddCommand(labelTrashCmd)
+	gmailCmd.AddCommand(labelCmd)
+
+	filterCreateCmd.Flags().String("from", "", "Match the sender")
+	filterCreateCmd.Flags().String("to", "", "Match a recipient")
+	filterCreateCmd.Flags().String("subject", "", "Match words in the subject")
+	filterCreateCmd.Flags().String("query", "", "Match a Gmail search query")
+	filterCreateCmd.Flags().String("exclude", "", "Skip emails matching this Gmail search query")
+	filterCreateCmd.Flags().Bool("has-attachment", false, "Match only emails with attachments")
+	filterCreateCmd.Flags().String("label", "", "Apply this label (created if missing)")
+	filterCreateCmd.Flags().Bool("archive", false, "Skip the inbox")
+	filterCreateCmd.Flags().Bool("mark-read", false, "Mark as read")
+	filterCreateCmd.Flags().Bool("star", false, "Star")
+	filterCreateCmd.Flags().Bool("trash", false, "Delete (move to trash)")
+	filterCreateCmd.Flags().Bool("never-spam", false, "Never send to spam")
+	filterCreateCmd.Flags().String("forward", "", "Forward to this address (must be a verified forwarding address)")
+
+	filterSuggestCmd.Flags().String("query", "newer_than:14d", "Gmail search query selecting the emails to learn from")
+	filterSuggestCmd.Flags().Int("limit", 300, "Maximum number of emails to learn from")
+	filterSuggestCmd.Flags().Int("min-count", 3, "Only consider senders with at least this many emails")
+	filterSuggestCmd.Flags().Bool("apply", false, "Create the suggested filters (default: only show them)")
+
+	filterCmd.AddCommand(filterListCmd)
+	filterCmd.AddCommand(filterCreateCmd)
+	filterCmd.AddCommand(filterDeleteCmd)
+	filterCmd.AddCommand(filterSuggestCmd)
+	gmailCmd.AddCommand(filterCmd)
 
 	parent.AddCommand(gmailCmd)
 }
@@ -206,38 +265,76 @@ var summaryCmd = &cobra.Command{
 		channel, _ := cmd.Flags().GetString("channel")
 		dm, _ := cmd.Flags().GetString("dm")
 		noMarkRead, _ := cmd.Flags().GetBool("no-mark-read")
+		sinceLastRun, _ := cmd.Flags().GetBool("since-last-run")
+		noThreads, _ := cmd.Flags().GetBool("no-threads")
+		attachmentText, _ := cmd.Flags().GetBool("attachment-text")
+
+		sinks, err := digestSinks(client, channel, dm)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 
 		// Default to dry run when no output target is specified
-		hasOutputTarget := channel != "" || dm != ""
+		hasOutputTarget := len(sinks) > 0
 		if !hasOutputTarget && !cmd.Flags().Changed("no-mark-read") {
 			noMarkRead = true
 		}
 
-		// Fetch all unread email IDs
-		query := UnreadQuery()
-		fmt.Printf("Fetching unread emails (%s)...\n", query)
-		ids, err := client.ListAllMessageIDs(query)
+		store := NewStore("")
+		refs, next, err := unreadEmails(client, store, sinceLastRun)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error fetching emails: %v\n", err)
 			os.Exit(1)
 		}
+		// The cursor moves on once the digest is delivered: to at least one
+		// sink, or to stdout when there are none. A run that fails before
+		// then is repeated in full next time.
+		saveCursor := func() {
+			if next == nil {
+				return
+			}
+			if err := store.SaveCursor(summaryCursor, *next); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
 
-		if len(ids) == 0 {
+		if len(refs) == 0 {
 			fmt.Println("No unread emails for today.")
+			saveCursor()
 			return
 		}
+		ids := make([]string, len(refs))
+		for i, ref := range refs {
+			ids[i] = ref.ID
+		}
 
 		fmt.Printf("Found %d unread email(s). Fetching content...\n", len(ids))
 
-		// Fetch email content concurrently
-		emails, err := FetchEmails(client, ids)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error fetching email content: %v\n", err)
-			os.Exit(1)
-		}
-
 		// Build prompt and invoke claude -p
-		prompt := BuildPrompt(emails)
+		// Fetch email content concurrently, reusing emails fetched by
+		// earlier runs
+		cache := store.LoadCache()
+		var prompt string
+		if noThreads {
+			emails, err := FetchEmailsCached(client, ids, cache)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error fetching email content: %v\n", err)
+				os.Exit(1)
+			}
+			prompt = BuildPrompt(emails)
+		} else {
+			convs, err := FetchConversations(client, refs, ConversationOptions{AttachmentText: attachmentText, Cache: cache})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error fetching email content: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Grouped into %d conversation(s).\n", len(convs))
+			prompt = BuildConversationPrompt(convs)
+		}
+		if err := store.SaveCache(cache); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not save the message cache: %v\n", err)
+		}
 		fmt.Println("Generating summary with Claude...")
 		summary, err := RunClaude(prompt)
 		if err != nil {
@@ -249,23 +346,27 @@ var summaryCmd = &cobra.Command{
 		fmt.Println()
 		fmt.Println(summary)
 
-		// Send to Slack if requested
-		slackTarget := channel
-		if dm != "" {
-			slackTarget = dm
-		}
-		if slackTarget != "" {
-			fmt.Printf("\nSending summary to Slack (%s)...\n", slackTarget)
-			if err := SendToSlack(summary, slackTarget); err != nil {
+		digest := Digest{Date: time.Now(), Count: len(ids), Text: summary}
+		delivered := len(sinks) == 0
+		for _, sink := range sinks {
+			fmt.Printf("\nSending summary to %s...\n", sink.Name())
+			if err := sink.Send(digest); err != nil {
+				// The others still run; the emails stay unread only
+				// when no sink receives the digest.
 				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
-				// Still mark as read per design decision
 			} else {
-				fmt.Println("Summary sent to Slack.")
+				fmt.Println("Summary sent.")
+				delivered = true
 			}
 		}
+		if delivered {
+			saveCursor()
+		} else {
+			fmt.Fprintln(os.Stderr, "Warning: no sink received the summary; leaving the emails unread for the next summary.")
+		}
 
-		// Mark emails as read unless --no-mark-read
-		if !noMarkRead {
+		// Mark emails as read unless --no-mark-read or nothing got the digest
+		if !noMarkRead && delivered {
 			fmt.Printf("Marking %d email(s) as read...\n", len(ids))
 			batchSize := 1000
 			for i := 0; i < len(ids); i += batchSize {
@@ -283,6 +384,649 @@ var summaryCmd = &cobra.Command{
 	},
 }
 
+var triageCmd = &cobra.Command{
+	Use:   "triage",
+	Short: "Classify emails with AI and turn action items into tasks, labels and drafts",
+	Long: `Classify emails with Claude into action, informational and promotional, then:
+  - create a Trello card or GitHub issue for each action email
+  - label every email with its category (e.g. devpilot/action)
+  - save suggested replies as drafts for review
+
+Emails already carrying a category label are skipped, so running again only
+triages new mail. Nothing is changed unless --apply is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		query, _ := cmd.Flags().GetString("query")
+		limit, _ := cmd.Flags().GetInt("limit")
+		apply, _ := cmd.Flags().GetBool("apply")
+		noTasks, _ := cmd.Flags().GetBool("no-tasks")
+		noLabels, _ := cmd.Flags().GetBool("no-labels")
+		noDrafts, _ := cmd.Flags().GetBool("no-drafts")
+		labelPrefix, _ := cmd.Flags().GetString("label-prefix")
+		sourceName, _ := cmd.Flags().GetString("source")
+		boardName, _ := cmd.Flags().GetString("board")
+		listName, _ := cmd.Flags().GetString("list")
+
+		// Emails labelled by an earlier run are done; triaging them again
+		// would file their tasks and drafts twice.
+		if !noLabels && labelPrefix != "" {
+			query = strings.TrimSpace(query + " " + ExcludeTriaged(labelPrefix))
+		}
+		fmt.Printf("Fetching emails (%s)...\n", query)
+		refs, err := client.ListMessages(query, limit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching emails: %v\n", err)
+			os.Exit(1)
+		}
+		if len(refs) == 0 {
+			fmt.Println("No emails to triage.")
+			return
+		}
+		ids := make([]string, len(refs))
+		for i, ref := range refs {
+			ids[i] = ref.ID
+		}
+		store := NewStore("")
+		cache := store.LoadCache()
+		emails, err := FetchEmailsCached(client, ids, cache)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching email content: %v\n", err)
+			os.Exit(1)
+		}
+		if err := store.SaveCache(cache); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not save the message cache: %v\n", err)
+		}
+
+		fmt.Printf("Classifying %d email(s) with Claude...\n", len(emails))
+		output, err := RunClaude(BuildClassifyPrompt(emails))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		classes, err := ParseClassifications(output, emails)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		triage := &Triage{Client: client, Drafts: !noDrafts, DryRun: !apply, Out: os.Stdout}
+		if !noLabels {
+			triage.LabelPrefix = labelPrefix
+		}
+		if !noTasks {
+			dir, _ := os.Getwd()
+			projectCfg, _ := project.Load(dir)
+			target := trello.TaskTarget{
+				Source: projectCfg.ResolveSource(sourceName),
+				Board:  boardName,
+				List:   listName,
+				Dir:    dir,
+				Labels: []string{"email"},
+			}
+			triage.FileTask = func(plan string) (string, error) {
+				task, err := trello.CreateTask(projectCfg, target, plan)
+				if err != nil {
+					return "", err
+				}
+				return task.URL, nil
+			}
+		}
+
+		fmt.Println()
+		if err := triage.Run(emails, classes); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !apply {
+			fmt.Println("\nDry run: nothing was changed. Run again with --apply to act on it.")
+		}
+	},
+}
+
+var labelCmd = &cobra.Command{
+	Use:   "label",
+	Short: "Manage labels and label, archive or trash emails",
+}
+
+var labelListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List labels",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		labels, err := client.ListLabels()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		sort.Slice(labels, func(i, j int) bool {
+			if labels[i].Type != labels[j].Type {
+				return labels[i].Type == "user"
+			}
+			return labels[i].Name < labels[j].Name
+		})
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tID\tTYPE")
+		for _, l := range labels {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", l.Name, l.ID, l.Type)
+		}
+		w.Flush()
+	},
+}
+
+var labelCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a label (use / to nest, e.g. Receipts/2026)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		label, err := client.CreateLabel(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created label %s (%s)\n", label.Name, label.ID)
+	},
+}
+
+var labelRenameCmd = &cobra.Command{
+	Use:   "rename <label> <new-name>",
+	Short: "Rename a label",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		label, err := userLabel(client, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		renamed, err := client.RenameLabel(label.ID, args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Renamed label %s to %s\n", label.Name, renamed.Name)
+	},
+}
+
+var labelDeleteCmd = &cobra.Command{
+	Use:   "delete <label>",
+	Short: "Delete a label (its emails are kept)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		label, err := userLabel(client, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := client.DeleteLabel(label.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Deleted label %s\n", label.Name)
+	},
+}
+
+var labelApplyCmd = &cobra.Command{
+	Use:   "apply <label> [message-id...] [--query <gmail-query>]",
+	Short: "Add a label to emails",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		modifyLabel(cmd, args, true)
+	},
+}
+
+var labelRemoveCmd = &cobra.Command{
+	Use:   "remove <label> [message-id...] [--query <gmail-query>]",
+	Short: "Remove a label from emails",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		modifyLabel(cmd, args, false)
+	},
+}
+
+// modifyLabel runs label apply and label remove.
+func modifyLabel(cmd *cobra.Command, args []string, add bool) {
+	client, err := requireLogin()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	label, err := client.FindLabel(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	ids := selectMessages(cmd, client, args[1:])
+
+	if add {
+		err = client.ModifyLabels(ids, []string{label.ID}, nil)
+	} else {
+		err = client.ModifyLabels(ids, nil, []string{label.ID})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if add {
+		fmt.Printf("Labelled %d email(s) %s.\n", len(ids), label.Name)
+	} else {
+		fmt.Printf("Removed %s from %d email(s).\n", label.Name, len(ids))
+	}
+}
+
+var labelArchiveCmd = &cobra.Command{
+	Use:   "archive [message-id...] [--query <gmail-query>]",
+	Short: "Archive emails (remove them from the inbox)",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		ids := selectMessages(cmd, client, args)
+		if err := client.Archive(ids); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Archived %d email(s).\n", len(ids))
+	},
+}
+
+var labelTrashCmd = &cobra.Command{
+	Use:   "trash [message-id...] [--query <gmail-query>]",
+	Short: "Move emails to the trash (deleted by Gmail after 30 days)",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		ids := selectMessages(cmd, client, args)
+		if err := client.Trash(ids); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Moved %d email(s) to the trash.\n", len(ids))
+	},
+}
+
+// userLabel finds a label that can be renamed or deleted.
+func userLabel(client *Client, nameOrID string) (*Label, error) {
+	label, err := client.FindLabel(nameOrID)
+	if err != nil {
+		return nil, err
+	}
+	if label.Type == "system" {
+		return nil, fmt.Errorf("%s is a system label and cannot be changed", label.Name)
+	}
+	return label, nil
+}
+
+// selectMessages returns the message IDs given as arguments, or those
+// matching --query. It exits when neither or both are given, or when
+// nothing matches.
+func selectMessages(cmd *cobra.Command, client *Client, ids []string) []string {
+	query, _ := cmd.Flags().GetString("query")
+	if (query == "") == (len(ids) == 0) {
+		fmt.Fprintln(os.Stderr, "Error: give either message IDs or --query")
+		os.Exit(1)
+	}
+	if query == "" {
+		return ids
+	}
+
+	fmt.Printf("Searching for emails matching: %s\n", query)
+	ids, err := client.ListAllMessageIDs(query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(ids) == 0 {
+		fmt.Println("No matching messages found.")
+		os.Exit(0)
+	}
+	fmt.Printf("Found %d message(s).\n", len(ids))
+	return ids
+}
+
+var filterCmd = &cobra.Command{
+	Use:   "filter",
+	Short: "Manage Gmail filters",
+	Long: `Manage the filters Gmail applies to incoming mail.
+
+Gmail cannot edit a filter: create the new one and delete the old.`,
+}
+
+var filterListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List filters",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		filters, err := client.ListFilters()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", filterError(err))
+			os.Exit(1)
+		}
+		if len(filters) == 0 {
+			fmt.Println("No filters.")
+			return
+		}
+		labels, err := client.ListLabels()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		names := LabelNames(labels)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "ID\tMATCHES\tACTIONS")
+		for _, f := range filters {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", f.ID, f.Criteria.Describe(), f.Action.Describe(names))
+		}
+		w.Flush()
+	},
+}
+
+var filterCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a filter",
+	Example: `  devpilot gmail filter create --from news@example.com --label Newsletters --archive
+  devpilot gmail filter create --query "list:builds.example.com" --mark-read`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		var f Filter
+		f.Criteria.From, _ = cmd.Flags().GetString("from")
+		f.Criteria.To, _ = cmd.Flags().GetString("to")
+		f.Criteria.Subject, _ = cmd.Flags().GetString("subject")
+		f.Criteria.Query, _ = cmd.Flags().GetString("query")
+		f.Criteria.NegatedQuery, _ = cmd.Flags().GetString("exclude")
+		f.Criteria.HasAttachment, _ = cmd.Flags().GetBool("has-attachment")
+		f.Action.Forward, _ = cmd.Flags().GetString("forward")
+		labelName, _ := cmd.Flags().GetString("label")
+		for _, a := range []struct{ flag, label string }{{"star", LabelStarred}, {"trash", LabelTrash}} {
+			if on, _ := cmd.Flags().GetBool(a.flag); on {
+				f.Action.AddLabelIDs = append(f.Action.AddLabelIDs, a.label)
+			}
+		}
+		for _, a := range []struct{ flag, label string }{{"archive", LabelInbox}, {"mark-read", LabelUnread}, {"never-spam", LabelSpam}} {
+			if on, _ := cmd.Flags().GetBool(a.flag); on {
+				f.Action.RemoveLabelIDs = append(f.Action.RemoveLabelIDs, a.label)
+			}
+		}
+
+		if f.Criteria.IsZero() {
+			fmt.Fprintln(os.Stderr, "Error: give at least one of --from, --to, --subject, --query, --exclude or --has-attachment")
+			os.Exit(1)
+		}
+		if labelName == "" && len(f.Action.AddLabelIDs) == 0 && len(f.Action.RemoveLabelIDs) == 0 && f.Action.Forward == "" {
+			fmt.Fprintln(os.Stderr, "Error: give at least one action: --label, --archive, --mark-read, --star, --trash, --never-spam or --forward")
+			os.Exit(1)
+		}
+		if labelName != "" {
+			labelID, err := client.EnsureLabel(labelName)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			f.Action.AddLabelIDs = append([]string{labelID}, f.Action.AddLabelIDs...)
+		}
+
+		created, err := client.CreateFilter(f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", filterError(err))
+			os.Exit(1)
+		}
+		fmt.Printf("Created filter %s: %s\n", created.ID, created.Criteria.Describe())
+	},
+}
+
+var filterDeleteCmd = &cobra.Command{
+	Use:   "delete <filter-id>...",
+	Short: "Delete filters (see filter list for IDs)",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		for _, id := range args {
+			if err := client.DeleteFilter(id); err != nil {
+				fmt.Fprintf(os.Stderr, "Error deleting %s: %v\n", id, filterError(err))
+				os.Exit(1)
+			}
+			fmt.Printf("Deleted filter %s\n", id)
+		}
+	},
+}
+
+var filterSuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Propose filters for recurring noise using AI",
+	Long: `Group recent emails by sender and ask Claude which senders are noise
+(newsletters, marketing, automated notifications), then propose a filter
+for each that labels, archives or marks them read.
+
+Nothing is created unless --apply is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if _, err := exec.LookPath("claude"); err != nil {
+			fmt.Fprintln(os.Stderr, "Error: Claude Code CLI is required but not found on PATH. Install it from https://claude.ai/code")
+			os.Exit(1)
+		}
+
+		query, _ := cmd.Flags().GetString("query")
+		limit, _ := cmd.Flags().GetInt("limit")
+		minCount, _ := cmd.Flags().GetInt("min-count")
+		apply, _ := cmd.Flags().GetBool("apply")
+
+		existing, err := client.ListFilters()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", filterError(err))
+			os.Exit(1)
+		}
+
+		fmt.Printf("Fetching emails (%s)...\n", query)
+		refs, err := client.ListMessages(query, limit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching emails: %v\n", err)
+			os.Exit(1)
+		}
+		ids := make([]string, len(refs))
+		for i, ref := range refs {
+			ids[i] = ref.ID
+		}
+		store := NewStore("")
+		cache := store.LoadCache()
+		emails, err := FetchEmailsCached(client, ids, cache)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching email content: %v\n", err)
+			os.Exit(1)
+		}
+		if err := store.SaveCache(cache); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not save the message cache: %v\n", err)
+		}
+
+		senders := SenderFrequency(emails, minCount)
+		if len(senders) == 0 {
+			fmt.Printf("No sender sent %d or more of the %d email(s); nothing to suggest.\n", minCount, len(emails))
+			return
+		}
+
+		fmt.Printf("Asking Claude about %d frequent sender(s)...\n", len(senders))
+		output, err := RunClaude(BuildFilterSuggestPrompt(senders, existing))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		suggestions, err := ParseFilterSuggestions(output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(suggestions) == 0 {
+			fmt.Println("No filters suggested.")
+			return
+		}
+
+		fmt.Println()
+		for _, s := range suggestions {
+			fmt.Printf("%s → %s\n", s.Criteria().Describe(), s.Describe())
+			fmt.Printf("  %s\n", s.Reason)
+			if !apply {
+				continue
+			}
+			labelID := ""
+			if s.Label != "" {
+				if labelID, err = client.EnsureLabel(s.Label); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			created, err := client.CreateFilter(s.Filter(labelID))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", filterError(err))
+				os.Exit(1)
+			}
+			fmt.Printf("  Created filter %s\n", created.ID)
+		}
+		if !apply {
+			fmt.Println("\nDry run: no filters were created. Run again with --apply to create them.")
+		}
+	},
+}
+
+// filterError explains the 403 Gmail returns for filters when the login
+// predates the settings scope.
+func filterError(err error) error {
+	if strings.HasPrefix(err.Error(), "HTTP 403") {
+		return fmt.Errorf("%w\nManaging filters needs an extra permission. Run: devpilot login gmail", err)
+	}
+	return err
+}
+
+// summaryCursor names the sync cursor of gmail summary --since-last-run.
+const summaryCursor = "summary"
+
+// unreadEmails returns the unread emails to summarize. With sinceLastRun
+// these are the ones that arrived since the cursor saved by the last such
+// run, and the returned cursor is the one to save once they are summarized.
+// Without a usable cursor it falls back to every unread email.
+func unreadEmails(client *Client, store *Store, sinceLastRun bool) ([]MessageRef, *Cursor, error) {
+	if !sinceLastRun {
+		fmt.Printf("Fetching unread emails (%s)...\n", UnreadQuery())
+		refs, err := client.ListAllMessages(UnreadQuery())
+		return refs, nil, err
+	}
+
+	// Take the mailbox position before reading history, so an email that
+	// arrives meanwhile is picked up next time.
+	profile, err := client.Profile()
+	if err != nil {
+		return nil, nil, err
+	}
+	next := &Cursor{HistoryID: profile.HistoryID, LastRun: time.Now()}
+
+	last, err := store.Cursor(summaryCursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	if last.HistoryID != "" {
+		fmt.Printf("Fetching unread emails since the last run (%s)...\n", last.LastRun.Local().Format("2006-01-02 15:04"))
+		refs, err := client.NewMessages(last.HistoryID)
+		if !errors.Is(err, ErrHistoryExpired) {
+			return refs, next, err
+		}
+		fmt.Println("The last run is too long ago for Gmail's history; reading all unread emails.")
+	} else {
+		fmt.Println("No earlier run; reading all unread emails.")
+	}
+	refs, err := client.ListAllMessages(UnreadQuery())
+	return refs, next, err
+}
+
+// digestSinks returns where the digest goes: Slack when --channel or --dm
+// is given, otherwise the gmail.digest sinks of .devpilot.yaml or, failing
+// that, of the user config. Webhook sinks and email sinks with a "to"
+// address are only taken from the user config.
+func digestSinks(client *Client, channel, dm string) ([]Sink, error) {
+	if channel != "" || dm != "" {
+		target := channel
+		if dm != "" {
+			target = dm
+		}
+		token, err := slack.LoadBotToken()
+		if err != nil {
+			return nil, err
+		}
+		return []Sink{NewSlackSink(slack.NewClient(token), target)}, nil
+	}
+
+	dir, _ := os.Getwd()
+	projectCfg, err := project.Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read .devpilot.yaml: %w", err)
+	}
+	cfgs, ignored := ProjectSinks(projectCfg.Gmail.Digest)
+	for _, cfg := range ignored {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring the %s sink in .devpilot.yaml; slack, webhook, email sinks with a to address and file sinks outside the project are only read from ~/.config/devpilot/config.yaml\n", cfg.Type)
+	}
+	if len(cfgs) == 0 {
+		userCfg, err := project.LoadUser()
+		if err != nil {
+			return nil, fmt.Errorf("read user config: %w", err)
+		}
+		cfgs = userCfg.Gmail.Digest
+	}
+	return NewSinks(cfgs, client, projectCfg)
+}
+
 var markReadCmd = &cobra.Command{
 	Use:   "mark-read <id>...",
 	Short: "Mark one or more emails as read",
@@ -0,0 +1,66 @@
+package gmail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// outgoingEmail is a plain-text message devpilot sends or drafts.
+type outgoingEmail struct {
+	To        string
+	Subject   string
+	InReplyTo string // Message-ID of the email this replies to, if any
+	Body      string
+}
+
+// bytes renders the email as an RFC 2822 message.
+func (e outgoingEmail) bytes() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "To: %s\r\n", e.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", e.Subject))
+	if e.InReplyTo != "" {
+		fmt.Fprintf(&b, "In-Reply-To: %s\r\nReferences: %s\r\n", e.InReplyTo, e.InReplyTo)
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(e.Body, "\n", "\r\n"))
+	return b.Bytes()
+}
+
+// replySubject prefixes subject with "Re: " unless it already has it.
+func replySubject(subject string) string {
+	if strings.HasPrefix(strings.ToLower(subject), "re:") {
+		return subject
+	}
+	return "Re: " + subject
+}
+
+type draftRequest struct {
+	Message struct {
+		Raw      string `json:"raw"`
+		ThreadID string `json:"threadId,omitempty"`
+	} `json:"message"`
+}
+
+// CreateDraft saves an RFC 2822 message as a draft, in threadID's thread
+// when set, and returns the draft's ID.
+func (c *Client) CreateDraft(threadID string, raw []byte) (string, error) {
+	var req draftRequest
+	req.Message.Raw = base64.URLEncoding.EncodeToString(raw)
+	req.Message.ThreadID = threadID
+	data, err := c.doPost("/gmail/v1/users/me/drafts", req)
+	if err != nil {
+		return "", err
+	}
+	var draft struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &draft); err != nil {
+		return "", fmt.Errorf("parse draft: %w", err)
+	}
+	return draft.ID, nil
+}
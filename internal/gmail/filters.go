@@ -0,0 +1,322 @@
+package gmail
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FilterCriteria selects the incoming messages a filter acts on.
+type FilterCriteria struct {
+	From          string `json:"from,omitempty"`
+	To            string `json:"to,omitempty"`
+	Subject       string `json:"subject,omitempty"`
+	Query         string `json:"query,omitempty"`        // Gmail search syntax
+	NegatedQuery  string `json:"negatedQuery,omitempty"` // messages matching this are excluded
+	HasAttachment bool   `json:"hasAttachment,omitempty"`
+}
+
+// IsZero reports whether the criteria would match every message.
+func (c FilterCriteria) IsZero() bool {
+	return c == FilterCriteria{}
+}
+
+// FilterAction is what a filter does to matching messages. Archiving is
+// removing INBOX, marking read is removing UNREAD.
+type FilterAction struct {
+	AddLabelIDs    []string `json:"addLabelIds,omitempty"`
+	RemoveLabelIDs []string `json:"removeLabelIds,omitempty"`
+	Forward        string   `json:"forward,omitempty"`
+}
+
+// Filter is a Gmail server-side filter. Gmail cannot edit filters; replace
+// one by creating the new filter and deleting the old.
+type Filter struct {
+	ID       string         `json:"id,omitempty"`
+	Criteria FilterCriteria `json:"criteria"`
+	Action   FilterAction   `json:"action"`
+}
+
+type filterListResponse struct {
+	Filter []Filter `json:"filter"`
+}
+
+// ListFilters returns the account's filters.
+func (c *Client) ListFilters() ([]Filter, error) {
+	data, err := c.doRequest(http.MethodGet, "/gmail/v1/users/me/settings/filters", nil)
+	if err != nil {
+		return nil, err
+	}
+	var resp filterListResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parse filters: %w", err)
+	}
+	return resp.Filter, nil
+}
+
+// GetFilter returns a filter.
+func (c *Client) GetFilter(id string) (*Filter, error) {
+	data, err := c.doRequest(http.MethodGet, fmt.Sprintf("/gmail/v1/users/me/settings/filters/%s", id), nil)
+	if err != nil {
+		return nil, err
+	}
+	var f Filter
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse filter: %w", err)
+	}
+	return &f, nil
+}
+
+// CreateFilter creates a filter and returns it with its ID.
+func (c *Client) CreateFilter(f Filter) (*Filter, error) {
+	f.ID = ""
+	data, err := c.doPost("/gmail/v1/users/me/settings/filters", f)
+	if err != nil {
+		return nil, err
+	}
+	var created Filter
+	if err := json.Unmarshal(data, &created); err != nil {
+		return nil, fmt.Errorf("parse filter: %w", err)
+	}
+	return &created, nil
+}
+
+// DeleteFilter deletes a filter.
+func (c *Client) DeleteFilter(id string) error {
+	_, err := c.doRequest(http.MethodDelete, fmt.Sprintf("/gmail/v1/users/me/settings/filters/%s", id), nil)
+	return err
+}
+
+// Describe renders the criteria in Gmail search syntax, e.g.
+// "from:news@example.com subject:(Weekly digest)".
+func (c FilterCriteria) Describe() string {
+	var parts []string
+	term := func(op, v string) {
+		if v == "" {
+			return
+		}
+		if strings.ContainsAny(v, " \t") {
+			v = "(" + v + ")"
+		}
+		parts = append(parts, op+v)
+	}
+	term("from:", c.From)
+	term("to:", c.To)
+	term("subject:", c.Subject)
+	if c.HasAttachment {
+		parts = append(parts, "has:attachment")
+	}
+	if c.Query != "" {
+		parts = append(parts, c.Query)
+	}
+	if c.NegatedQuery != "" {
+		parts = append(parts, "-("+c.NegatedQuery+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Describe renders the action, naming labels through labelNames (label ID
+// to name), e.g. "label Newsletters, archive, mark read".
+func (a FilterAction) Describe(labelNames map[string]string) string {
+	var parts []string
+	for _, id := range a.AddLabelIDs {
+		switch id {
+		case LabelStarred:
+			parts = append(parts, "star")
+		case LabelTrash:
+			parts = append(parts, "trash")
+		default:
+			name := labelNames[id]
+			if name == "" {
+				name = id
+			}
+			parts = append(parts, "label "+name)
+		}
+	}
+	for _, id := range a.RemoveLabelIDs {
+		switch id {
+		case LabelInbox:
+			parts = append(parts, "archive")
+		case LabelUnread:
+			parts = append(parts, "mark read")
+		case LabelSpam:
+			parts = append(parts, "never spam")
+		default:
+			name := labelNames[id]
+			if name == "" {
+				name = id
+			}
+			parts = append(parts, "remove "+name)
+		}
+	}
+	if a.Forward != "" {
+		parts = append(parts, "forward to "+a.Forward)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LabelNames maps label IDs to names, for FilterAction.Describe.
+func LabelNames(labels []Label) map[string]string {
+	names := make(map[string]string, len(labels))
+	for _, l := range labels {
+		names[l.ID] = l.Name
+	}
+	return names
+}
+
+// SenderStats is how much mail one sender sent.
+type SenderStats struct {
+	Address  string
+	Count    int
+	Subjects []string // a few distinct subjects
+}
+
+// maxSenderSubjects is how many subjects SenderStats keeps per sender.
+const maxSenderSubjects = 5
+
+// SenderFrequency groups emails by sender address, busiest first, keeping
+// senders with at least minCount emails.
+func SenderFrequency(emails []EmailSummary, minCount int) []SenderStats {
+	bySender := map[string]*SenderStats{}
+	for _, e := range emails {
+		addr := senderAddress(e.From)
+		s := bySender[addr]
+		if s == nil {
+			s = &SenderStats{Address: addr}
+			bySender[addr] = s
+		}
+		s.Count++
+		if len(s.Subjects) < maxSenderSubjects && !slices.Contains(s.Subjects, e.Subject) {
+			s.Subjects = append(s.Subjects, e.Subject)
+		}
+	}
+
+	var stats []SenderStats
+	for _, s := range bySender {
+		if s.Count >= minCount {
+			stats = append(stats, *s)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Address < stats[j].Address
+	})
+	return stats
+}
+
+// senderAddress returns the bare, lower-case address of a From header.
+func senderAddress(from string) string {
+	if addr, err := mail.ParseAddress(from); err == nil {
+		return strings.ToLower(addr.Address)
+	}
+	return strings.ToLower(strings.TrimSpace(from))
+}
+
+// FilterSuggestion is a filter Claude proposes.
+type FilterSuggestion struct {
+	From     string `json:"from,omitempty"`
+	Subject  string `json:"subject,omitempty"`
+	Query    string `json:"query,omitempty"`
+	Label    string `json:"label,omitempty"` // label name, created if missing
+	Archive  bool   `json:"archive"`
+	MarkRead bool   `json:"mark_read"`
+	Reason   string `json:"reason"`
+}
+
+// Criteria returns the suggestion's filter criteria.
+func (s FilterSuggestion) Criteria() FilterCriteria {
+	return FilterCriteria{From: s.From, Subject: s.Subject, Query: s.Query}
+}
+
+// Filter returns the suggested filter; labelID is the ID of s.Label.
+func (s FilterSuggestion) Filter(labelID string) Filter {
+	f := Filter{Criteria: s.Criteria()}
+	if labelID != "" {
+		f.Action.AddLabelIDs = []string{labelID}
+	}
+	if s.Archive {
+		f.Action.RemoveLabelIDs = append(f.Action.RemoveLabelIDs, LabelInbox)
+	}
+	if s.MarkRead {
+		f.Action.RemoveLabelIDs = append(f.Action.RemoveLabelIDs, LabelUnread)
+	}
+	return f
+}
+
+// Describe renders the suggestion's action, e.g. "label Newsletters, archive".
+func (s FilterSuggestion) Describe() string {
+	var parts []string
+	if s.Label != "" {
+		parts = append(parts, "label "+s.Label)
+	}
+	if s.Archive {
+		parts = append(parts, "archive")
+	}
+	if s.MarkRead {
+		parts = append(parts, "mark read")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// BuildFilterSuggestPrompt constructs the prompt asking Claude for filters
+// that would keep the noise among senders out of the inbox. existing
+// filters are listed so they are not proposed again.
+func BuildFilterSuggestPrompt(senders []SenderStats, existing []Filter) string {
+	var sb strings.Builder
+	sb.WriteString("You are an email assistant. Below are the senders of recent email with how many emails each sent and sample subjects.\n")
+	sb.WriteString("Propose Gmail filters for the noise among them: newsletters, marketing, automated notifications and similar bulk mail the reader doesn't need in the inbox.\n")
+	sb.WriteString("Leave alone mail from people, and anything that may need the reader's attention. Prefer one filter per sender or domain; use subject or query only to narrow a sender that also sends important mail.\n\n")
+	sb.WriteString("Respond with ONLY a JSON array (empty if nothing is worth filtering), one object per filter, with these fields:\n")
+	sb.WriteString(`- "from": sender address or domain to match, e.g. "news@example.com" or "@example.com"; otherwise ""` + "\n")
+	sb.WriteString(`- "subject": words the subject must contain; otherwise ""` + "\n")
+	sb.WriteString(`- "query": extra Gmail search terms; otherwise ""` + "\n")
+	sb.WriteString(`- "label": a short label name to file the emails under, e.g. "Newsletters"; "" for none` + "\n")
+	sb.WriteString(`- "archive": true to skip the inbox` + "\n")
+	sb.WriteString(`- "mark_read": true to mark the emails read` + "\n")
+	sb.WriteString(`- "reason": one line on why, ~80 chars max` + "\n\n")
+
+	if len(existing) > 0 {
+		sb.WriteString("Existing filters (don't propose these again):\n")
+		for _, f := range existing {
+			fmt.Fprintf(&sb, "- %s\n", f.Criteria.Describe())
+		}
+		sb.WriteString("\n")
+	}
+
+	fmt.Fprintf(&sb, "--- %d senders ---\n\n", len(senders))
+	for _, s := range senders {
+		fmt.Fprintf(&sb, "%s (%d emails)\n", s.Address, s.Count)
+		for _, subject := range s.Subjects {
+			fmt.Fprintf(&sb, "  - %s\n", subject)
+		}
+	}
+	return sb.String()
+}
+
+// ParseFilterSuggestions reads Claude's JSON answer, dropping suggestions
+// that match everything or do nothing.
+func ParseFilterSuggestions(output string) ([]FilterSuggestion, error) {
+	start, end := strings.Index(output, "["), strings.LastIndex(output, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON array in filter suggestions")
+	}
+	var all []FilterSuggestion
+	if err := json.Unmarshal([]byte(output[start:end+1]), &all); err != nil {
+		return nil, fmt.Errorf("parse filter suggestions: %w", err)
+	}
+	var suggestions []FilterSuggestion
+	for _, s := range all {
+		s.Label = strings.TrimSpace(s.Label)
+		if s.Criteria().IsZero() || s.Describe() == "" {
+			continue
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, nil
+}
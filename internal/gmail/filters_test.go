@@ -0,0 +1,119 @@
+package gmail
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFilters(t *testing.T) {
+	var created Filter
+	deleted := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/gmail/v1/users/me/settings/filters":
+			w.Write([]byte(`{"filter":[{"id":"f1","criteria":{"from":"news@example.com"},"action":{"addLabelIds":["Label_1"],"removeLabelIds":["INBOX"]}}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/gmail/v1/users/me/settings/filters":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.Write([]byte(`{"id":"f2","criteria":{"subject":"build failed"},"action":{"removeLabelIds":["UNREAD"]}}`))
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/gmail/v1/users/me/settings/filters/"):
+			deleted = strings.TrimPrefix(r.URL.Path, "/gmail/v1/users/me/settings/filters/")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient("test-token", WithBaseURL(srv.URL))
+
+	filters, err := client.ListFilters()
+	if err != nil {
+		t.Fatalf("ListFilters: %v", err)
+	}
+	if len(filters) != 1 || filters[0].Criteria.From != "news@example.com" {
+		t.Fatalf("filters = %+v", filters)
+	}
+	if got := filters[0].Action.Describe(map[string]string{"Label_1": "Newsletters"}); got != "label Newsletters, archive" {
+		t.Errorf("Describe() = %q", got)
+	}
+
+	f, err := client.CreateFilter(Filter{
+		ID:       "ignored",
+		Criteria: FilterCriteria{Subject: "build failed"},
+		Action:   FilterAction{RemoveLabelIDs: []string{LabelUnread}},
+	})
+	if err != nil {
+		t.Fatalf("CreateFilter: %v", err)
+	}
+	if f.ID != "f2" || created.ID != "" || created.Criteria.Subject != "build failed" {
+		t.Errorf("created %+v, sent %+v", f, created)
+	}
+
+	if err := client.DeleteFilter("f1"); err != nil {
+		t.Fatalf("DeleteFilter: %v", err)
+	}
+	if deleted != "f1" {
+		t.Errorf("deleted %q, want f1", deleted)
+	}
+}
+
+func TestFilterCriteria_Describe(t *testing.T) {
+	c := FilterCriteria{From: "@example.com", Subject: "Weekly digest", HasAttachment: true, NegatedQuery: "urgent"}
+	want := "from:@example.com subject:(Weekly digest) has:attachment -(urgent)"
+	if got := c.Describe(); got != want {
+		t.Errorf("Describe() = %q, want %q", got, want)
+	}
+}
+
+func TestSenderFrequency(t *testing.T) {
+	emails := []EmailSummary{
+		{From: "Example News <news@example.com>", Subject: "Issue 1"},
+		{From: "news@Example.com", Subject: "Issue 2"},
+		{From: "Example News <news@example.com>", Subject: "Issue 1"},
+		{From: "Ada <ada@example.org>", Subject: "Lunch?"},
+	}
+	stats := SenderFrequency(emails, 2)
+	if len(stats) != 1 {
+		t.Fatalf("stats = %+v, want only news@example.com", stats)
+	}
+	if s := stats[0]; s.Address != "news@example.com" || s.Count != 3 || len(s.Subjects) != 2 {
+		t.Errorf("stats[0] = %+v", s)
+	}
+}
+
+func TestParseFilterSuggestions(t *testing.T) {
+	output := "Here you go:\n```json\n[" +
+		`{"from":"news@example.com","label":"Newsletters","archive":true,"reason":"weekly newsletter"},` +
+		`{"label":"Everything","archive":true,"reason":"matches everything"},` +
+		`{"from":"ada@example.org","reason":"no action"}` +
+		"]\n```"
+	suggestions, err := ParseFilterSuggestions(output)
+	if err != nil {
+		t.Fatalf("ParseFilterSuggestions: %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("suggestions = %+v, want only the newsletter", suggestions)
+	}
+	f := suggestions[0].Filter("Label_9")
+	if f.Criteria.From != "news@example.com" || len(f.Action.AddLabelIDs) != 1 || f.Action.AddLabelIDs[0] != "Label_9" || len(f.Action.RemoveLabelIDs) != 1 || f.Action.RemoveLabelIDs[0] != LabelInbox {
+		t.Errorf("filter = %+v", f)
+	}
+
+	if _, err := ParseFilterSuggestions("no filters needed"); err == nil {
+		t.Error("expected an error without a JSON array")
+	}
+}
+
+func TestBuildFilterSuggestPrompt(t *testing.T) {
+	prompt := BuildFilterSuggestPrompt(
+		[]SenderStats{{Address: "news@example.com", Count: 5, Subjects: []string{"Issue 1"}}},
+		[]Filter{{Criteria: FilterCriteria{From: "deals@example.com"}}},
+	)
+	for _, want := range []string{"news@example.com (5 emails)", "  - Issue 1", "- from:deals@example.com", "--- 1 senders ---"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package gmail
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// System labels that stand for mailbox states.
+const (
+	LabelInbox   = "INBOX"
+	LabelUnread  = "UNREAD"
+	LabelStarred = "STARRED"
+	LabelTrash   = "TRASH"
+	LabelSpam    = "SPAM"
+)
+
+// Label is a Gmail label. System labels such as INBOX and UNREAD have
+// Type "system".
+type Label struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+type labelListResponse struct {
+	Labels []Label `json:"labels"`
+}
+
+// ListLabels returns all labels of the account.
+func (c *Client) ListLabels() ([]Label, error) {
+	data, err := c.doRequest(http.MethodGet, "/gmail/v1/users/me/labels", nil)
+	if err != nil {
+		return nil, err
+	}
+	var resp labelListResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("parse labels: %w", err)
+	}
+	return resp.Labels, nil
+}
+
+// CreateLabel creates a user label. Nested labels use "/" in the name,
+// e.g. "devpilot/action".
+func (c *Client) CreateLabel(name string) (*Label, error) {
+	data, err := c.doPost("/gmail/v1/users/me/labels", map[string]string{
+		"name":                  name,
+		"labelListVisibility":   "labelShow",
+		"messageListVisibility": "show",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var label Label
+	if err := json.Unmarshal(data, &label); err != nil {
+		return nil, fmt.Errorf("parse label: %w", err)
+	}
+	return &label, nil
+}
+
+// EnsureLabel returns the ID of the label with the given name, creating it
+// if it does not exist. Names match case-insensitively, as Gmail refuses to
+// create a label differing from an existing one only in case.
+func (c *Client) EnsureLabel(name string) (string, error) {
+	labels, err := c.ListLabels()
+	if err != nil {
+		return "", err
+	}
+	for _, l := range labels {
+		if strings.EqualFold(l.Name, name) {
+			return l.ID, nil
+		}
+	}
+	label, err := c.CreateLabel(name)
+	if err != nil {
+		return "", fmt.Errorf("create label %s: %w", name, err)
+	}
+	return label.ID, nil
+}
+
+// FindLabel returns the label with the given name or ID. Names match
+// case-insensitively, as in Gmail's search.
+func (c *Client) FindLabel(nameOrID string) (*Label, error) {
+	labels, err := c.ListLabels()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range labels {
+		if l.ID == nameOrID || strings.EqualFold(l.Name, nameOrID) {
+			return &l, nil
+		}
+	}
+	return nil, fmt.Errorf("label %q not found", nameOrID)
+}
+
+// RenameLabel renames a user label. Gmail renames its nested labels along
+// with it.
+func (c *Client) RenameLabel(id, name string) (*Label, error) {
+	data, err := c.doJSON(http.MethodPatch, fmt.Sprintf("/gmail/v1/users/me/labels/%s", id), map[string]string{"name": name})
+	if err != nil {
+		return nil, err
+	}
+	var label Label
+	if err := json.Unmarshal(data, &label); err != nil {
+		return nil, fmt.Errorf("parse label: %w", err)
+	}
+	return &label, nil
+}
+
+// DeleteLabel deletes a user label. Its messages keep their other labels.
+func (c *Client) DeleteLabel(id string) error {
+	_, err := c.doRequest(http.MethodDelete, fmt.Sprintf("/gmail/v1/users/me/labels/%s", id), nil)
+	return err
+}
+
+// ModifyLabels adds and removes labels on any number of messages, in
+// batches of 1000.
+func (c *Client) ModifyLabels(ids, addLabelIds, removeLabelIds []string) error {
+	for i := 0; i < len(ids); i += 1000 {
+		end := min(i+1000, len(ids))
+		if err := c.BatchModifyLabels(ids[i:end], addLabelIds, removeLabelIds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Archive removes messages from the inbox.
+func (c *Client) Archive(ids []string) error {
+	return c.ModifyLabels(ids, nil, []string{LabelInbox})
+}
+
+// Trash moves messages to the trash, where Gmail deletes them after 30 days.
+func (c *Client) Trash(ids []string) error {
+	return c.ModifyLabels(ids, []string{LabelTrash}, nil)
+}
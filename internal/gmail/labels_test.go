@@ -0,0 +1,103 @@
+package gmail
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLabelCRUD(t *testing.T) {
+	var renamed map[string]string
+	deleted := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/gmail/v1/users/me/labels":
+			w.Write([]byte(`{"labels":[{"id":"INBOX","name":"INBOX","type":"system"},{"id":"Label_1","name":"Receipts","type":"user"}]}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/gmail/v1/users/me/labels/Label_1":
+			json.NewDecoder(r.Body).Decode(&renamed)
+			w.Write([]byte(`{"id":"Label_1","name":"Invoices","type":"user"}`))
+		case r.Method == http.MethodDelete && r.URL.Path == "/gmail/v1/users/me/labels/Label_1":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient("test-token", WithBaseURL(srv.URL))
+
+	label, err := client.FindLabel("receipts")
+	if err != nil || label.ID != "Label_1" {
+		t.Fatalf("FindLabel(receipts) = %+v, %v", label, err)
+	}
+	if _, err := client.FindLabel("Travel"); err == nil {
+		t.Error("expected an error for a missing label")
+	}
+	if id, err := client.EnsureLabel("RECEIPTS"); err != nil || id != "Label_1" {
+		t.Errorf("EnsureLabel(RECEIPTS) = %q, %v, want the existing Label_1", id, err)
+	}
+
+	got, err := client.RenameLabel("Label_1", "Invoices")
+	if err != nil {
+		t.Fatalf("RenameLabel: %v", err)
+	}
+	if got.Name != "Invoices" || renamed["name"] != "Invoices" {
+		t.Errorf("renamed to %q, sent %v", got.Name, renamed)
+	}
+
+	if err := client.DeleteLabel("Label_1"); err != nil {
+		t.Fatalf("DeleteLabel: %v", err)
+	}
+	if !deleted {
+		t.Error("label was not deleted")
+	}
+}
+
+func TestArchiveAndTrash(t *testing.T) {
+	var batches [][]string
+	var added, removed []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/gmail/v1/users/me/messages/batchModify":
+			var body struct {
+				IDs    []string `json:"ids"`
+				Add    []string `json:"addLabelIds"`
+				Remove []string `json:"removeLabelIds"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			batches = append(batches, body.IDs)
+			added, removed = body.Add, body.Remove
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	client := NewClient("test-token", WithBaseURL(srv.URL))
+
+	ids := make([]string, 1500)
+	for i := range ids {
+		ids[i] = "m"
+	}
+	if err := client.Archive(ids); err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	if len(batches) != 2 || len(batches[0]) != 1000 || len(batches[1]) != 500 {
+		t.Errorf("batch sizes = %d, want 1000 and 500", len(batches))
+	}
+	if len(removed) != 1 || removed[0] != LabelInbox {
+		t.Errorf("removed labels = %v, want [INBOX]", removed)
+	}
+
+	batches = nil
+	if err := client.Trash([]string{"m1", "m2"}); err != nil {
+		t.Fatalf("Trash: %v", err)
+	}
+	if len(batches) != 1 || len(batches[0]) != 2 {
+		t.Errorf("trash batches = %v, want one request for both", batches)
+	}
+	if len(added) != 1 || added[0] != LabelTrash || len(removed) != 0 {
+		t.Errorf("added %v, removed %v, want only TRASH added", added, removed)
+	}
+}
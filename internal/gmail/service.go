@@ -13,6 +13,8 @@ const (
 	gmailAuthURL  = "https://accounts.google.com/o/oauth2/v2/auth"
 	gmailTokenURL = "https://oauth2.googleapis.com/token"
 	gmailScope    = "https://www.googleapis.com/auth/gmail.modify"
+	// gmailSettingsScope covers server-side filters (settings.filters).
+	gmailSettingsScope = "https://www.googleapis.com/auth/gmail.settings.basic"
 )
 
 func init() {
@@ -96,6 +98,6 @@ func (g *GmailService) oauthConfig() auth.OAuthConfig {
 		TokenURL:     gmailTokenURL,
 		ClientID:     creds["client_id"],
 		ClientSecret: creds["client_secret"],
-		Scopes:       []string{gmailScope},
+		Scopes:       []string{gmailScope, gmailSettingsScope},
 	}
 }
@@ -0,0 +1,295 @@
+package gmail
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/auth"
+	"github.com/siyuqian/devpilot/internal/project"
+	"github.com/siyuqian/devpilot/internal/slack"
+	"github.com/siyuqian/devpilot/internal/trello"
+)
+
+// defaultDigestList is the Trello list digest cards go in. It is not the
+// runner's Ready list, so a digest is never picked up as a task.
+const defaultDigestList = "Inbox"
+
+// Digest is a generated email digest ready to deliver.
+type Digest struct {
+	Date  time.Time
+	Count int // emails summarized
+	Text  string
+}
+
+// Title names the digest, e.g. "Email digest 2026-10-18".
+func (d Digest) Title() string {
+	return "Email digest " + d.Date.Format("2006-01-02")
+}
+
+// Sink delivers a digest somewhere.
+type Sink interface {
+	// Name describes the destination for progress output, e.g. "Slack (#inbox)".
+	Name() string
+	Send(d Digest) error
+}
+
+// NewSinks builds the sinks configured under gmail.digest. client is the
+// Gmail client, used by email sinks.
+func NewSinks(cfgs []project.DigestSink, client *Client, projectCfg *project.Config) ([]Sink, error) {
+	var sinks []Sink
+	for i, cfg := range cfgs {
+		sink, err := newSink(cfg, client, projectCfg)
+		if err != nil {
+			return nil, fmt.Errorf("gmail.digest[%d]: %w", i, err)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// userOnlySink reports whether a sink may only be configured in the user
+// config: it sends the digest somewhere the config picks, so a cloned
+// repository's .devpilot.yaml could otherwise route someone's mail summary
+// to itself. A project may still mail the digest to its reader, post it to
+// their Trello, or write it to a file inside the project.
+func userOnlySink(cfg project.DigestSink) bool {
+	switch cfg.Type {
+	case "webhook", "slack":
+		return true
+	case "email":
+		return cfg.To != ""
+	case "file":
+		return strings.HasPrefix(cfg.Path, "~") || !filepath.IsLocal(cfg.Path)
+	}
+	return false
+}
+
+// ProjectSinks splits the gmail.digest sinks of a project's .devpilot.yaml
+// into those it may configure and those only the user config may.
+func ProjectSinks(cfgs []project.DigestSink) (allowed, ignored []project.DigestSink) {
+	for _, cfg := range cfgs {
+		if userOnlySink(cfg) {
+			ignored = append(ignored, cfg)
+		} else {
+			allowed = append(allowed, cfg)
+		}
+	}
+	return allowed, ignored
+}
+
+func newSink(cfg project.DigestSink, client *Client, projectCfg *project.Config) (Sink, error) {
+	switch cfg.Type {
+	case "slack":
+		if cfg.Channel == "" {
+			return nil, fmt.Errorf("slack sink needs a channel")
+		}
+		token, err := slack.LoadBotToken()
+		if err != nil {
+			return nil, err
+		}
+		return &SlackSink{client: slack.NewClient(token), target: cfg.Channel}, nil
+	case "email":
+		return &EmailSink{client: client, to: cfg.To}, nil
+	case "file":
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("file sink needs a path")
+		}
+		return &FileSink{path: expandHome(cfg.Path)}, nil
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook sink needs a url")
+		}
+		return &WebhookSink{url: cfg.URL, httpClient: &http.Client{Timeout: 30 * time.Second}}, nil
+	case "trello":
+		return newTrelloSink(cfg, projectCfg)
+	default:
+		return nil, fmt.Errorf("unknown sink type %q (use slack, email, file, webhook or trello)", cfg.Type)
+	}
+}
+
+// SlackSink posts the digest to a channel or as a DM.
+type SlackSink struct {
+	client *slack.Client
+	target string // channel name or ID, or a user ID
+}
+
+// NewSlackSink returns a sink posting to target with client.
+func NewSlackSink(client *slack.Client, target string) *SlackSink {
+	return &SlackSink{client: client, target: target}
+}
+
+func (s *SlackSink) Name() string {
+	return "Slack (" + s.target + ")"
+}
+
+func (s *SlackSink) Send(d Digest) error {
+	channelID, _, err := slack.ResolveTarget(s.client, s.target)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.Send(slack.Message{Channel: channelID, Text: d.Text})
+	return err
+}
+
+// EmailSink mails the digest, by default to the signed-in address.
+type EmailSink struct {
+	client *Client
+	to     string
+}
+
+func (s *EmailSink) Name() string {
+	if s.to == "" {
+		return "email (to self)"
+	}
+	return "email (" + s.to + ")"
+}
+
+func (s *EmailSink) Send(d Digest) error {
+	to := s.to
+	if to == "" {
+		profile, err := s.client.Profile()
+		if err != nil {
+			return fmt.Errorf("look up own address: %w", err)
+		}
+		to = profile.EmailAddress
+	}
+	email := outgoingEmail{To: to, Subject: fmt.Sprintf("%s (%d emails)", d.Title(), d.Count), Body: d.Text}
+	_, err := s.client.SendMessage(email.bytes())
+	return err
+}
+
+// FileSink appends the digest to a Markdown note. When path is a directory,
+// such as an Obsidian vault folder, each day gets its own note.
+type FileSink struct {
+	path string
+}
+
+func (s *FileSink) Name() string {
+	return "file (" + s.path + ")"
+}
+
+func (s *FileSink) Send(d Digest) error {
+	path := s.path
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, d.Title()+".md")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Fprintf(&b, "---\ndate: %s\ntags: [email-digest]\n---\n\n# %s\n", d.Date.Format("2006-01-02"), d.Title())
+	}
+	fmt.Fprintf(&b, "\n## %s (%d emails)\n\n%s\n", d.Date.Format("15:04"), d.Count, d.Text)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WebhookSink POSTs the digest as JSON.
+type WebhookSink struct {
+	url        string
+	httpClient *http.Client
+}
+
+type webhookPayload struct {
+	Title string    `json:"title"`
+	Date  time.Time `json:"date"`
+	Count int       `json:"count"`
+	Text  string    `json:"text"`
+}
+
+func (s *WebhookSink) Name() string {
+	if u, err := url.Parse(s.url); err == nil && u.Host != "" {
+		return "webhook (" + u.Host + ")"
+	}
+	return "webhook"
+}
+
+func (s *WebhookSink) Send(d Digest) error {
+	data, err := json.Marshal(webhookPayload{Title: d.Title(), Date: d.Date, Count: d.Count, Text: d.Text})
+	if err != nil {
+		return err
+	}
+	resp, err := s.httpClient.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// TrelloSink adds the digest to a board as a card.
+type TrelloSink struct {
+	client *trello.Client
+	listID string
+	label  string
+}
+
+func newTrelloSink(cfg project.DigestSink, projectCfg *project.Config) (*TrelloSink, error) {
+	boardName := cfg.Board
+	if boardName == "" {
+		boardName = projectCfg.Board
+	}
+	if boardName == "" {
+		return nil, fmt.Errorf("trello sink needs a board")
+	}
+	listName := cfg.List
+	if listName == "" {
+		listName = defaultDigestList
+	}
+	creds, err := auth.Load("trello")
+	if err != nil {
+		return nil, fmt.Errorf("Not logged in to Trello. Run: devpilot login trello")
+	}
+	client := trello.NewClient(creds["api_key"], creds["token"])
+	board, err := client.FindBoardByName(boardName)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.FindListByName(board.ID, listName)
+	if err != nil {
+		return nil, err
+	}
+	return &TrelloSink{client: client, listID: list.ID, label: boardName + " / " + listName}, nil
+}
+
+func (s *TrelloSink) Name() string {
+	return "Trello (" + s.label + ")"
+}
+
+func (s *TrelloSink) Send(d Digest) error {
+	_, err := s.client.CreateCard(s.listID, d.Title(), d.Text)
+	return err
+}
+
+// expandHome replaces a leading ~ with the home directory.
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,151 @@
+package gmail
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+var testDigest = Digest{Date: time.Date(2026, 3, 14, 8, 30, 0, 0, time.UTC), Count: 3, Text: "ACTION REQUIRED\n- Reply to Ada"}
+
+func TestFileSink_DailyNote(t *testing.T) {
+	dir := t.TempDir()
+	sink := &FileSink{path: dir}
+	if err := sink.Send(testDigest); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	later := testDigest
+	later.Date = later.Date.Add(9 * time.Hour)
+	if err := sink.Send(later); err != nil {
+		t.Fatalf("second Send: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "Email digest 2026-03-14.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	note := string(data)
+	if !strings.HasPrefix(note, "---\ndate: 2026-03-14\n") || strings.Count(note, "---\n") != 2 {
+		t.Errorf("note should start with one front matter block:\n%s", note)
+	}
+	if !strings.Contains(note, "## 08:30 (3 emails)\n\nACTION REQUIRED") || !strings.Contains(note, "## 17:30 (3 emails)") {
+		t.Errorf("note is missing a digest:\n%s", note)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got webhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	if err := (&WebhookSink{url: srv.URL, httpClient: srv.Client()}).Send(testDigest); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.Title != "Email digest 2026-03-14" || got.Count != 3 || got.Text != testDigest.Text {
+		t.Errorf("payload = %+v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := (&WebhookSink{url: failing.URL, httpClient: failing.Client()}).Send(testDigest); err == nil {
+		t.Error("expected an error for HTTP 500")
+	}
+}
+
+func TestEmailSink_ToSelf(t *testing.T) {
+	var raw string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gmail/v1/users/me/profile":
+			w.Write([]byte(`{"emailAddress":"ada@example.com"}`))
+		case "/gmail/v1/users/me/messages/send":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			decoded, _ := base64.URLEncoding.DecodeString(body["raw"])
+			raw = string(decoded)
+			w.Write([]byte(`{"id":"sent1"}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	sink := &EmailSink{client: NewClient("test-token", WithBaseURL(srv.URL))}
+	if err := sink.Send(testDigest); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	for _, want := range []string{"To: ada@example.com\r\n", "Subject: Email digest 2026-03-14 (3 emails)\r\n", "\r\n\r\nACTION REQUIRED\r\n- Reply to Ada"} {
+		if !strings.Contains(raw, want) {
+			t.Errorf("message is missing %q:\n%s", want, raw)
+		}
+	}
+}
+
+func TestNewSinks_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		sink project.DigestSink
+		want string
+	}{
+		{project.DigestSink{Type: "fax"}, "unknown sink type"},
+		{project.DigestSink{Type: "file"}, "needs a path"},
+		{project.DigestSink{Type: "webhook"}, "needs a url"},
+		{project.DigestSink{Type: "trello"}, "needs a board"},
+	}
+	for _, tt := range tests {
+		_, err := NewSinks([]project.DigestSink{{Type: "email"}, tt.sink}, nil, &project.Config{})
+		if err == nil || !strings.Contains(err.Error(), "gmail.digest[1]") || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s sink: error = %v, want %q", tt.sink.Type, err, tt.want)
+		}
+	}
+}
+
+func TestProjectSinks_KeepsPersonalDestinationsOut(t *testing.T) {
+	allowed, ignored := ProjectSinks([]project.DigestSink{
+		{Type: "slack", Channel: "#inbox"},
+		{Type: "slack", Channel: "U123ABC"},
+		{Type: "webhook", URL: "https://attacker.example/collect"},
+		{Type: "email"},
+		{Type: "email", To: "someone@example.com"},
+		{Type: "file", Path: "digest.md"},
+		{Type: "file", Path: "notes/inbox"},
+		{Type: "file", Path: "/tmp/digest.md"},
+		{Type: "file", Path: "~/.bashrc"},
+		{Type: "file", Path: "../outside.md"},
+		{Type: "trello"},
+	})
+	var got []string
+	for _, cfg := range allowed {
+		got = append(got, cfg.Type+":"+cfg.Path)
+	}
+	if want := "email:,file:digest.md,file:notes/inbox,trello:"; strings.Join(got, ",") != want {
+		t.Errorf("allowed = %v, want %s", got, want)
+	}
+	got = nil
+	for _, cfg := range ignored {
+		got = append(got, cfg.Type+":"+cfg.Channel+cfg.URL+cfg.To+cfg.Path)
+	}
+	want := "slack:#inbox,slack:U123ABC,webhook:https://attacker.example/collect,email:someone@example.com," +
+		"file:/tmp/digest.md,file:~/.bashrc,file:../outside.md"
+	if strings.Join(got, ",") != want {
+		t.Errorf("ignored = %v, want %s", got, want)
+	}
+}
+
+func TestWebhookSink_NameShowsHost(t *testing.T) {
+	if got := (&WebhookSink{url: "https://hooks.example.com/digest?token=secret"}).Name(); got != "webhook (hooks.example.com)" {
+		t.Errorf("Name() = %q", got)
+	}
+}
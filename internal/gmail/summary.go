@@ -9,11 +9,15 @@ import (
 
 // EmailSummary holds a fetched email's key fields for summarization.
 type EmailSummary struct {
-	ID      string
-	From    string
-	Subject string
-	Date    string
-	Body    string
+	ID        string
+	ThreadID  string
+	MessageID string // the Message-ID header, for threading replies
+	From      string
+	Subject   string
+	Date      string
+	Body      string
+	// Attachments lists the message's files, without their text.
+	Attachments []AttachmentInfo
 }
 
 // UnreadQuery returns a Gmail query for all unread emails.
@@ -24,6 +28,12 @@ func UnreadQuery() string {
 // FetchEmails fetches full email content for all given message IDs concurrently.
 // Uses a bounded semaphore of 10 goroutines.
 func FetchEmails(client *Client, ids []string) ([]EmailSummary, error) {
+	return FetchEmailsCached(client, ids, nil)
+}
+
+// FetchEmailsCached is FetchEmails that takes emails from cache when it has
+// them and adds the ones it fetches. A nil cache fetches everything.
+func FetchEmailsCached(client *Client, ids []string, cache *MessageCache) ([]EmailSummary, error) {
 	type result struct {
 		idx   int
 		email EmailSummary
@@ -35,6 +45,12 @@ func FetchEmails(client *Client, ids []string) ([]EmailSummary, error) {
 	var wg sync.WaitGroup
 
 	for i, id := range ids {
+		if cache != nil {
+			if email, ok := cache.Get(id); ok {
+				results[i] = result{idx: i, email: email}
+				continue
+			}
+		}
 		wg.Add(1)
 		go func(idx int, msgID string) {
 			defer wg.Done()
@@ -46,18 +62,7 @@ func FetchEmails(client *Client, ids []string) ([]EmailSummary, error) {
 				results[idx] = result{idx: idx, err: err}
 				return
 			}
-
-			body := GetBody(msg)
-			results[idx] = result{
-				idx: idx,
-				email: EmailSummary{
-					ID:      msgID,
-					From:    GetHeader(msg, "From"),
-					Subject: GetHeader(msg, "Subject"),
-					Date:    GetHeader(msg, "Date"),
-					Body:    TruncateBody(body, 1000),
-				},
-			}
+			results[idx] = result{idx: idx, email: summarize(msg)}
 		}(i, id)
 	}
 
@@ -69,11 +74,28 @@ func FetchEmails(client *Client, ids []string) ([]EmailSummary, error) {
 			// Skip emails that failed to fetch
 			continue
 		}
+		if cache != nil {
+			cache.Put(r.email)
+		}
 		emails = append(emails, r.email)
 	}
 	return emails, nil
 }
 
+// summarize extracts what the digest and the message cache keep of msg.
+func summarize(msg *Message) EmailSummary {
+	return EmailSummary{
+		ID:          msg.ID,
+		ThreadID:    msg.ThreadID,
+		MessageID:   GetHeader(msg, "Message-ID"),
+		From:        GetHeader(msg, "From"),
+		Subject:     GetHeader(msg, "Subject"),
+		Date:        GetHeader(msg, "Date"),
+		Body:        TruncateBody(GetBody(msg), 1000),
+		Attachments: Attachments(msg),
+	}
+}
+
 // TruncateBody truncates a string to maxLen characters, appending "[truncated]" if truncated.
 func TruncateBody(body string, maxLen int) string {
 	if len(body) <= maxLen {
@@ -126,18 +148,3 @@ func RunClaude(prompt string) (string, error) {
 	}
 	return summary, nil
 }
-
-// SendToSlack sends a message to Slack via `devpilot slack send`.
-func SendToSlack(message, channel string) error {
-	devpilotPath, err := exec.LookPath("devpilot")
-	if err != nil {
-		return fmt.Errorf("devpilot not found on PATH")
-	}
-
-	cmd := exec.Command(devpilotPath, "slack", "send", "--channel", channel, "--message", message)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("slack send failed: %s", strings.TrimSpace(string(output)))
-	}
-	return nil
-}
@@ -0,0 +1,194 @@
+package gmail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// cacheTTL is how long a fetched email stays in the local cache. Message
+// content never changes, so this only bounds the cache's size.
+const cacheTTL = 30 * 24 * time.Hour
+
+// ErrHistoryExpired is returned by NewMessages when Gmail no longer has
+// history that far back, so a full scan is needed.
+var ErrHistoryExpired = errors.New("gmail history cursor expired")
+
+// stateDir returns ~/.config/devpilot/gmail. Tests replace it.
+var stateDir = func() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "devpilot", "gmail")
+}
+
+type historyResponse struct {
+	History []struct {
+		MessagesAdded []struct {
+			Message struct {
+				ID       string   `json:"id"`
+				ThreadID string   `json:"threadId"`
+				LabelIDs []string `json:"labelIds"`
+			} `json:"message"`
+		} `json:"messagesAdded"`
+	} `json:"history"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// NewMessages returns the unread inbox messages added since the mailbox
+// was at startHistoryID, oldest first.
+func (c *Client) NewMessages(startHistoryID string) ([]MessageRef, error) {
+	var refs []MessageRef
+	seen := map[string]bool{}
+	params := url.Values{
+		"startHistoryId": {startHistoryID},
+		"historyTypes":   {"messageAdded"},
+		"labelId":        {"INBOX"},
+		"maxResults":     {"500"},
+	}
+	for {
+		data, err := c.doRequest(http.MethodGet, "/gmail/v1/users/me/history", params)
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "HTTP 404") {
+				return nil, ErrHistoryExpired
+			}
+			return nil, err
+		}
+		var resp historyResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, fmt.Errorf("parse history: %w", err)
+		}
+		for _, h := range resp.History {
+			for _, added := range h.MessagesAdded {
+				m := added.Message
+				if seen[m.ID] || !slices.Contains(m.LabelIDs, "UNREAD") {
+					continue
+				}
+				seen[m.ID] = true
+				refs = append(refs, MessageRef{ID: m.ID, ThreadID: m.ThreadID})
+			}
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		params.Set("pageToken", resp.NextPageToken)
+	}
+	return refs, nil
+}
+
+// Cursor records where a command's last run left the mailbox.
+type Cursor struct {
+	HistoryID string    `json:"historyId"`
+	LastRun   time.Time `json:"lastRun"`
+}
+
+// Store keeps sync cursors and the message cache on disk.
+type Store struct {
+	dir string
+}
+
+// NewStore returns a store in dir; empty means ~/.config/devpilot/gmail.
+func NewStore(dir string) *Store {
+	if dir == "" {
+		dir = stateDir()
+	}
+	return &Store{dir: dir}
+}
+
+func (s *Store) cursorsPath() string { return filepath.Join(s.dir, "cursors.json") }
+func (s *Store) cachePath() string   { return filepath.Join(s.dir, "messages.json") }
+
+// Cursor returns the cursor saved by the named command, or a zero Cursor
+// before its first run.
+func (s *Store) Cursor(name string) (Cursor, error) {
+	cursors, err := s.loadCursors()
+	return cursors[name], err
+}
+
+// SaveCursor records the named command's cursor.
+func (s *Store) SaveCursor(name string, c Cursor) error {
+	cursors, err := s.loadCursors()
+	if err != nil {
+		return err
+	}
+	cursors[name] = c
+	return s.writeJSON(s.cursorsPath(), cursors)
+}
+
+func (s *Store) loadCursors() (map[string]Cursor, error) {
+	cursors := map[string]Cursor{}
+	if err := s.readJSON(s.cursorsPath(), &cursors); err != nil {
+		return cursors, fmt.Errorf("read sync cursors: %w", err)
+	}
+	return cursors, nil
+}
+
+type cacheEntry struct {
+	Email     EmailSummary `json:"email"`
+	FetchedAt time.Time    `json:"fetchedAt"`
+}
+
+// MessageCache holds emails fetched earlier so they are not fetched again.
+type MessageCache struct {
+	entries map[string]cacheEntry
+}
+
+// Get returns a cached email.
+func (c *MessageCache) Get(id string) (EmailSummary, bool) {
+	e, ok := c.entries[id]
+	return e.Email, ok
+}
+
+// Put caches an email.
+func (c *MessageCache) Put(e EmailSummary) {
+	if c.entries == nil {
+		c.entries = map[string]cacheEntry{}
+	}
+	c.entries[e.ID] = cacheEntry{Email: e, FetchedAt: time.Now()}
+}
+
+// LoadCache reads the message cache. A missing or unreadable cache is empty.
+func (s *Store) LoadCache() *MessageCache {
+	c := &MessageCache{entries: map[string]cacheEntry{}}
+	if err := s.readJSON(s.cachePath(), &c.entries); err != nil {
+		c.entries = map[string]cacheEntry{}
+	}
+	return c
+}
+
+// SaveCache writes the message cache, dropping entries older than cacheTTL.
+func (s *Store) SaveCache(c *MessageCache) error {
+	for id, e := range c.entries {
+		if time.Since(e.FetchedAt) > cacheTTL {
+			delete(c.entries, id)
+		}
+	}
+	return s.writeJSON(s.cachePath(), c.entries)
+}
+
+func (s *Store) readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (s *Store) writeJSON(path string, v any) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
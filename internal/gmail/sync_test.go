@@ -0,0 +1,113 @@
+package gmail
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewMessages(t *testing.T) {
+	call := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call++
+		q := r.URL.Query()
+		if r.URL.Path != "/gmail/v1/users/me/history" || q.Get("startHistoryId") != "100" || q.Get("historyTypes") != "messageAdded" {
+			t.Fatalf("unexpected request: %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		if call == 1 {
+			w.Write([]byte(`{"history":[{"messagesAdded":[` +
+				`{"message":{"id":"m1","labelIds":["INBOX","UNREAD"]}},` +
+				`{"message":{"id":"m2","labelIds":["INBOX"]}}]}],"nextPageToken":"p2"}`))
+			return
+		}
+		if q.Get("pageToken") != "p2" {
+			t.Fatalf("expected pageToken p2, got %q", q.Get("pageToken"))
+		}
+		w.Write([]byte(`{"history":[{"messagesAdded":[` +
+			`{"message":{"id":"m1","labelIds":["INBOX","UNREAD"]}},` +
+			`{"message":{"id":"m3","threadId":"t1","labelIds":["INBOX","UNREAD"]}}]}],"historyId":"130"}`))
+	}))
+	defer srv.Close()
+
+	refs, err := NewClient("test-token", WithBaseURL(srv.URL)).NewMessages("100")
+	if err != nil {
+		t.Fatalf("NewMessages error: %v", err)
+	}
+	if len(refs) != 2 || refs[0].ID != "m1" || refs[1].ID != "m3" {
+		t.Errorf("refs = %v, want m1 and m3 (read and duplicate messages skipped)", refs)
+	}
+	if refs[1].ThreadID != "t1" {
+		t.Errorf("refs[1].ThreadID = %q, want t1", refs[1].ThreadID)
+	}
+}
+
+func TestNewMessages_Expired(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":404,"message":"Requested entity was not found."}}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewClient("test-token", WithBaseURL(srv.URL)).NewMessages("1")
+	if !errors.Is(err, ErrHistoryExpired) {
+		t.Errorf("error = %v, want ErrHistoryExpired", err)
+	}
+}
+
+func TestStore_Cursors(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if c, err := store.Cursor("summary"); err != nil || c.HistoryID != "" {
+		t.Fatalf("cursor before the first run = %+v, %v", c, err)
+	}
+	when := time.Date(2026, 3, 14, 8, 0, 0, 0, time.UTC)
+	if err := store.SaveCursor("summary", Cursor{HistoryID: "130", LastRun: when}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveCursor("other", Cursor{HistoryID: "7"}); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewStore(store.dir).Cursor("summary")
+	if err != nil || c.HistoryID != "130" || !c.LastRun.Equal(when) {
+		t.Errorf("cursor = %+v, %v", c, err)
+	}
+}
+
+func TestFetchEmailsCached(t *testing.T) {
+	var fetched atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched.Add(1)
+		w.Write([]byte(`{"id":"m2","threadId":"t2","payload":{"headers":[{"name":"Subject","value":"Fresh"}]}}`))
+	}))
+	defer srv.Close()
+
+	store := NewStore(t.TempDir())
+	cache := store.LoadCache()
+	cache.Put(EmailSummary{ID: "m1", Subject: "Cached"})
+	if err := store.SaveCache(cache); err != nil {
+		t.Fatal(err)
+	}
+
+	cache = store.LoadCache()
+	emails, err := FetchEmailsCached(NewClient("test-token", WithBaseURL(srv.URL)), []string{"m1", "m2"}, cache)
+	if err != nil {
+		t.Fatalf("FetchEmailsCached error: %v", err)
+	}
+	if fetched.Load() != 1 {
+		t.Errorf("fetched %d messages, want only the uncached one", fetched.Load())
+	}
+	if len(emails) != 2 || emails[0].Subject != "Cached" || emails[1].Subject != "Fresh" {
+		t.Errorf("emails = %+v", emails)
+	}
+	if _, ok := cache.Get("m2"); !ok {
+		t.Error("fetched email was not cached")
+	}
+
+	cache.entries["old"] = cacheEntry{Email: EmailSummary{ID: "old"}, FetchedAt: time.Now().Add(-cacheTTL - time.Hour)}
+	store.SaveCache(cache)
+	if _, ok := store.LoadCache().Get("old"); ok {
+		t.Error("expired entry survived SaveCache")
+	}
+}
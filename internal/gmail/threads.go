@@ -0,0 +1,445 @@
+package gmail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxThreadMessages is how many of a conversation's latest messages the
+	// summary prompt includes; earlier ones are only counted.
+	maxThreadMessages = 10
+	// maxAttachmentBytes is the largest attachment whose text is extracted.
+	maxAttachmentBytes = 256 << 10
+	// maxAttachmentText caps the extracted text of each attachment.
+	maxAttachmentText = 2000
+	// pdfToTextTimeout bounds pdftotext, which can hang on a malformed PDF.
+	pdfToTextTimeout = 30 * time.Second
+)
+
+// Thread is a Gmail conversation as returned by threads.get.
+type Thread struct {
+	ID       string    `json:"id"`
+	Messages []Message `json:"messages"`
+}
+
+// GetThread returns a conversation with the full content of its messages,
+// oldest first.
+func (c *Client) GetThread(id string) (*Thread, error) {
+	params := url.Values{"format": {"full"}}
+	data, err := c.doRequest(http.MethodGet, fmt.Sprintf("/gmail/v1/users/me/threads/%s", id), params)
+	if err != nil {
+		return nil, err
+	}
+	var thread Thread
+	if err := json.Unmarshal(data, &thread); err != nil {
+		return nil, fmt.Errorf("parse thread: %w", err)
+	}
+	return &thread, nil
+}
+
+// ThreadMessageIDs returns the IDs of a conversation's messages, oldest
+// first, without fetching their content.
+func (c *Client) ThreadMessageIDs(id string) ([]string, error) {
+	params := url.Values{"format": {"minimal"}}
+	data, err := c.doRequest(http.MethodGet, fmt.Sprintf("/gmail/v1/users/me/threads/%s", id), params)
+	if err != nil {
+		return nil, err
+	}
+	var thread Thread
+	if err := json.Unmarshal(data, &thread); err != nil {
+		return nil, fmt.Errorf("parse thread: %w", err)
+	}
+	ids := make([]string, len(thread.Messages))
+	for i, msg := range thread.Messages {
+		ids[i] = msg.ID
+	}
+	return ids, nil
+}
+
+// GetAttachment downloads an attachment's content.
+func (c *Client) GetAttachment(messageID, attachmentID string) ([]byte, error) {
+	data, err := c.doRequest(http.MethodGet, fmt.Sprintf("/gmail/v1/users/me/messages/%s/attachments/%s", messageID, attachmentID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var body Body
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("parse attachment: %w", err)
+	}
+	content, err := base64.URLEncoding.DecodeString(body.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decode attachment: %w", err)
+	}
+	return content, nil
+}
+
+// AttachmentInfo describes a file attached to a message.
+type AttachmentInfo struct {
+	MessageID    string
+	AttachmentID string
+	Filename     string
+	MimeType     string
+	Size         int
+	Text         string // extracted text, when asked for and possible
+}
+
+// Attachments lists the files attached to a message.
+func Attachments(msg *Message) []AttachmentInfo {
+	var list []AttachmentInfo
+	var walk func(p *Payload)
+	walk = func(p *Payload) {
+		if p.Filename != "" {
+			list = append(list, AttachmentInfo{
+				MessageID:    msg.ID,
+				AttachmentID: p.Body.AttachmentID,
+				Filename:     p.Filename,
+				MimeType:     p.MimeType,
+				Size:         p.Body.Size,
+			})
+		}
+		for i := range p.Parts {
+			walk(&p.Parts[i])
+		}
+	}
+	walk(&msg.Payload)
+	return list
+}
+
+var (
+	// quoteHeaderRe matches the line mail clients put above a quoted reply,
+	// e.g. "On Mon, 1 Jan 2024 at 09:00, Ada <ada@example.com> wrote:".
+	quoteHeaderRe = regexp.MustCompile(`^On .+ wrote:$`)
+	// forwardHeaderRe matches Outlook's separators before the earlier mail.
+	forwardHeaderRe = regexp.MustCompile(`^(-+ ?Original Message ?-+|_{10,})$`)
+)
+
+// StripQuoted removes the earlier messages a reply quotes, so each message
+// of a thread contributes only what it adds.
+func StripQuoted(body string) string {
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	var kept []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if quoteHeaderRe.MatchString(trimmed) || forwardHeaderRe.MatchString(trimmed) {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// ConversationMessage is one message of a Conversation.
+type ConversationMessage struct {
+	ID     string
+	From   string
+	Date   string
+	Body   string // without quoted replies
+	Unread bool   // one of the messages being summarized
+}
+
+// Conversation is a thread prepared for the digest.
+type Conversation struct {
+	ThreadID    string
+	Subject     string
+	Messages    []ConversationMessage // the latest maxThreadMessages, oldest first
+	Earlier     int                   // older messages left out
+	Attachments []AttachmentInfo
+}
+
+// UnreadCount returns the number of unread messages in the conversation.
+func (c Conversation) UnreadCount() int {
+	n := 0
+	for _, m := range c.Messages {
+		if m.Unread {
+			n++
+		}
+	}
+	return n
+}
+
+// ConversationOptions configures FetchConversations.
+type ConversationOptions struct {
+	// AttachmentText extracts the text of small text and PDF attachments
+	// of unread messages. PDFs need pdftotext on PATH.
+	AttachmentText bool
+	// Cache serves messages fetched earlier and keeps the ones fetched now.
+	// Nil fetches every thread in full.
+	Cache *MessageCache
+}
+
+// FetchConversations fetches the threads of the given messages, which are
+// marked unread in them, in the order the threads first appear. Like
+// FetchEmails it fetches concurrently and skips threads it cannot fetch.
+// With a cache, only a thread's message IDs are fetched, and then just the
+// messages the cache lacks; a thread none of whose messages are cached is
+// fetched in full in one request.
+func FetchConversations(client *Client, refs []MessageRef, opts ConversationOptions) ([]Conversation, error) {
+	unread := map[string]bool{}
+	var threadIDs []string
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		unread[ref.ID] = true
+		id := ref.ThreadID
+		if id == "" {
+			id = ref.ID // a message's ID is its thread's ID when it starts one
+		}
+		if !seen[id] {
+			seen[id] = true
+			threadIDs = append(threadIDs, id)
+		}
+	}
+
+	convs := make([]*Conversation, len(threadIDs))
+	sem := make(chan struct{}, 10)
+	var cacheMu sync.Mutex
+	var wg sync.WaitGroup
+	for i, id := range threadIDs {
+		wg.Add(1)
+		go func(idx int, threadID string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			emails, err := fetchThread(client, threadID, opts.Cache, &cacheMu)
+			if err != nil {
+				return
+			}
+			conv := buildConversation(threadID, emails, unread)
+			if opts.AttachmentText {
+				extractAttachmentText(client, conv)
+			}
+			convs[idx] = conv
+		}(i, id)
+	}
+	wg.Wait()
+
+	var result []Conversation
+	for _, c := range convs {
+		if c != nil {
+			result = append(result, *c)
+		}
+	}
+	return result, nil
+}
+
+// fetchThread returns the messages of a thread, oldest first, taking those
+// it can from cache and adding the rest to it. mu guards cache.
+func fetchThread(client *Client, threadID string, cache *MessageCache, mu *sync.Mutex) ([]EmailSummary, error) {
+	if cache == nil {
+		return fetchFullThread(client, threadID)
+	}
+	ids, err := client.ThreadMessageIDs(threadID)
+	if err != nil {
+		return nil, err
+	}
+	emails := make([]EmailSummary, len(ids))
+	var missing []int
+	mu.Lock()
+	for i, id := range ids {
+		if e, ok := cache.Get(id); ok {
+			emails[i] = e
+		} else {
+			missing = append(missing, i)
+		}
+	}
+	mu.Unlock()
+
+	if len(missing) == len(ids) {
+		emails, err = fetchFullThread(client, threadID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for _, i := range missing {
+			msg, err := client.GetMessage(ids[i])
+			if err != nil {
+				return nil, err
+			}
+			emails[i] = summarize(msg)
+		}
+	}
+
+	mu.Lock()
+	for _, e := range emails {
+		cache.Put(e)
+	}
+	mu.Unlock()
+	return emails, nil
+}
+
+func fetchFullThread(client *Client, threadID string) ([]EmailSummary, error) {
+	thread, err := client.GetThread(threadID)
+	if err != nil {
+		return nil, err
+	}
+	emails := make([]EmailSummary, len(thread.Messages))
+	for i := range thread.Messages {
+		emails[i] = summarize(&thread.Messages[i])
+	}
+	return emails, nil
+}
+
+// buildConversation prepares a thread's messages for the digest. Bodies are
+// the summaries' excerpts with their quoted replies stripped.
+func buildConversation(threadID string, emails []EmailSummary, unread map[string]bool) *Conversation {
+	conv := &Conversation{ThreadID: threadID}
+	for _, e := range emails {
+		if conv.Subject == "" {
+			conv.Subject = e.Subject
+		}
+		conv.Messages = append(conv.Messages, ConversationMessage{
+			ID:     e.ID,
+			From:   e.From,
+			Date:   e.Date,
+			Body:   StripQuoted(e.Body),
+			Unread: unread[e.ID],
+		})
+		conv.Attachments = append(conv.Attachments, e.Attachments...)
+	}
+	if n := len(conv.Messages); n > maxThreadMessages {
+		conv.Earlier = n - maxThreadMessages
+		conv.Messages = conv.Messages[conv.Earlier:]
+	}
+	return conv
+}
+
+// extractAttachmentText fills in the text of small text and PDF
+// attachments of the conversation's unread messages.
+func extractAttachmentText(client *Client, conv *Conversation) {
+	unread := map[string]bool{}
+	for _, m := range conv.Messages {
+		unread[m.ID] = m.Unread
+	}
+	for i := range conv.Attachments {
+		a := &conv.Attachments[i]
+		if !unread[a.MessageID] || a.AttachmentID == "" || a.Size > maxAttachmentBytes || !extractable(*a) {
+			continue
+		}
+		content, err := client.GetAttachment(a.MessageID, a.AttachmentID)
+		if err != nil {
+			continue
+		}
+		if text := attachmentText(*a, content); text != "" {
+			a.Text = TruncateBody(text, maxAttachmentText)
+		}
+	}
+}
+
+func isPDF(a AttachmentInfo) bool {
+	return a.MimeType == "application/pdf" || strings.EqualFold(filepath.Ext(a.Filename), ".pdf")
+}
+
+func extractable(a AttachmentInfo) bool {
+	if isPDF(a) {
+		return true
+	}
+	if strings.HasPrefix(a.MimeType, "text/") {
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(a.Filename)) {
+	case ".txt", ".md", ".csv", ".log", ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// attachmentText returns an attachment's text. PDFs are converted with
+// pdftotext when it is installed; otherwise, or when it fails or takes
+// longer than pdfToTextTimeout, they yield "".
+func attachmentText(a AttachmentInfo, content []byte) string {
+	if !isPDF(a) {
+		return strings.TrimSpace(string(bytes.ToValidUTF8(content, nil)))
+	}
+	pdftotext, err := exec.LookPath("pdftotext")
+	if err != nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pdfToTextTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, pdftotext, "-q", "-", "-")
+	cmd.Stdin = bytes.NewReader(content)
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// formatSize renders a byte count for the prompt, e.g. "240 KB".
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// BuildConversationPrompt constructs the digest prompt for conversations,
+// so a thread is summarized as one discussion rather than message by
+// message.
+func BuildConversationPrompt(convs []Conversation) string {
+	unread := 0
+	for _, c := range convs {
+		unread += c.UnreadCount()
+	}
+
+	var sb strings.Builder
+	sb.WriteString("You are an email assistant. Summarize the following email conversations with unread messages into a concise, actionable digest.\n")
+	sb.WriteString("Each conversation is a thread: messages marked [unread] are new, the others are context. Summarize each conversation as a whole, focusing on what is new.\n")
+	sb.WriteString("Group them by priority:\n")
+	sb.WriteString("- ACTION REQUIRED: Needs response, review, decision, or action\n")
+	sb.WriteString("- INFORMATIONAL: Worth knowing but no action needed\n")
+	sb.WriteString("- PROMOTIONAL/NOISE: Newsletters, marketing (count only, don't summarize individually)\n\n")
+	sb.WriteString("Mention attachments that matter. Format the output as a clean digest with counts per category. Keep each summary to one line (~100 chars max).\n\n")
+	fmt.Fprintf(&sb, "--- %d conversations, %d unread emails ---\n\n", len(convs), unread)
+
+	for i, c := range convs {
+		fmt.Fprintf(&sb, "Conversation %d: %s\n", i+1, c.Subject)
+		if c.Earlier > 0 {
+			fmt.Fprintf(&sb, "(%d earlier messages not shown)\n", c.Earlier)
+		}
+		for _, m := range c.Messages {
+			marker := ""
+			if m.Unread {
+				marker = " [unread]"
+			}
+			fmt.Fprintf(&sb, "\nFrom: %s%s\n", m.From, marker)
+			fmt.Fprintf(&sb, "Date: %s\n", m.Date)
+			fmt.Fprintf(&sb, "Body:\n%s\n", m.Body)
+		}
+		if len(c.Attachments) > 0 {
+			sb.WriteString("\nAttachments:\n")
+			for _, a := range c.Attachments {
+				fmt.Fprintf(&sb, "- %s (%s, %s)\n", a.Filename, a.MimeType, formatSize(a.Size))
+				if a.Text != "" {
+					fmt.Fprintf(&sb, "  Content:\n%s\n", indent(a.Text, "    "))
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
@@ -0,0 +1,179 @@
+package gmail
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStripQuoted(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"no quote", "Sounds good.", "Sounds good."},
+		{"gmail reply", "Works for me.\r\n\r\nOn Mon, 1 Jan 2024 at 09:00, Ada <ada@example.com> wrote:\r\n> Shall we meet at 3?", "Works for me."},
+		{"inline quotes", "> Can you review?\nDone, see the PR.\n> Thanks", "Done, see the PR."},
+		{"outlook reply", "Approved.\n\n-----Original Message-----\nFrom: Bob", "Approved."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripQuoted(tt.body); got != tt.want {
+				t.Errorf("StripQuoted() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	msg := &Message{
+		ID: "m1",
+		Payload: Payload{
+			MimeType: "multipart/mixed",
+			Parts: []Payload{
+				{MimeType: "text/plain", Body: Body{Data: base64.URLEncoding.EncodeToString([]byte("see attached"))}},
+				{MimeType: "application/pdf", Filename: "invoice.pdf", Body: Body{AttachmentID: "a1", Size: 2048}},
+			},
+		},
+	}
+	got := Attachments(msg)
+	if len(got) != 1 {
+		t.Fatalf("got %d attachments, want 1", len(got))
+	}
+	a := got[0]
+	if a.MessageID != "m1" || a.AttachmentID != "a1" || a.Filename != "invoice.pdf" || a.MimeType != "application/pdf" || a.Size != 2048 {
+		t.Errorf("attachment = %+v", a)
+	}
+}
+
+func threadMessage(id, from, body string, parts ...Payload) string {
+	payload := Payload{
+		MimeType: "text/plain",
+		Headers:  []Header{{Name: "From", Value: from}, {Name: "Subject", Value: "Launch plan"}, {Name: "Date", Value: "Mon, 1 Jan 2024"}},
+		Body:     Body{Data: base64.URLEncoding.EncodeToString([]byte(body))},
+	}
+	if len(parts) > 0 {
+		payload.MimeType = "multipart/mixed"
+		payload.Body = Body{}
+		payload.Parts = append([]Payload{{MimeType: "text/plain", Body: Body{Data: base64.URLEncoding.EncodeToString([]byte(body))}}}, parts...)
+	}
+	msg := Message{ID: id, ThreadID: "t1", Payload: payload}
+	data, _ := json.Marshal(msg)
+	return string(data)
+}
+
+func TestFetchConversations(t *testing.T) {
+	notes := base64.URLEncoding.EncodeToString([]byte("Step 1: ship it"))
+	threadCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gmail/v1/users/me/threads/t1":
+			threadCalls++
+			if r.URL.Query().Get("format") != "full" {
+				t.Errorf("format = %q, want full", r.URL.Query().Get("format"))
+			}
+			fmt.Fprintf(w, `{"id":"t1","messages":[%s,%s]}`,
+				threadMessage("m1", "ada@example.com", "Shall we launch Friday?"),
+				threadMessage("m2", "bob@example.com", "Yes, notes attached.\n\nOn Mon, Ada wrote:\n> Shall we launch Friday?",
+					Payload{MimeType: "text/plain", Filename: "notes.txt", Body: Body{AttachmentID: "a1", Size: 15}}))
+		case "/gmail/v1/users/me/messages/m2/attachments/a1":
+			fmt.Fprintf(w, `{"data":%q,"size":15}`, notes)
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient("test-token", WithBaseURL(srv.URL))
+	refs := []MessageRef{{ID: "m1", ThreadID: "t1"}, {ID: "m2", ThreadID: "t1"}}
+	convs, err := FetchConversations(client, refs, ConversationOptions{AttachmentText: true})
+	if err != nil {
+		t.Fatalf("FetchConversations error: %v", err)
+	}
+	if threadCalls != 1 {
+		t.Errorf("thread fetched %d times, want once", threadCalls)
+	}
+	if len(convs) != 1 {
+		t.Fatalf("got %d conversations, want 1", len(convs))
+	}
+	c := convs[0]
+	if c.Subject != "Launch plan" || len(c.Messages) != 2 || c.UnreadCount() != 2 {
+		t.Fatalf("conversation = %+v", c)
+	}
+	if c.Messages[1].Body != "Yes, notes attached." {
+		t.Errorf("reply body = %q, want the quote stripped", c.Messages[1].Body)
+	}
+	if len(c.Attachments) != 1 || c.Attachments[0].Text != "Step 1: ship it" {
+		t.Errorf("attachments = %+v, want notes.txt with its text", c.Attachments)
+	}
+
+	prompt := BuildConversationPrompt(convs)
+	for _, want := range []string{"--- 1 conversations, 2 unread emails ---", "Conversation 1: Launch plan", "From: bob@example.com [unread]", "- notes.txt (text/plain, 15 B)", "Step 1: ship it"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestFetchConversations_UsesCache(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+"?"+r.URL.Query().Get("format"))
+		switch r.URL.Path {
+		case "/gmail/v1/users/me/threads/t1":
+			fmt.Fprint(w, `{"id":"t1","messages":[{"id":"m1","threadId":"t1"},{"id":"m2","threadId":"t1"}]}`)
+		case "/gmail/v1/users/me/messages/m2":
+			fmt.Fprint(w, threadMessage("m2", "bob@example.com", "Yes.\n\nOn Mon, Ada wrote:\n> Friday?"))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cache := &MessageCache{}
+	cache.Put(EmailSummary{ID: "m1", ThreadID: "t1", From: "ada@example.com", Subject: "Launch plan", Body: "Friday?"})
+
+	client := NewClient("test-token", WithBaseURL(srv.URL))
+	refs := []MessageRef{{ID: "m2", ThreadID: "t1"}}
+	convs, err := FetchConversations(client, refs, ConversationOptions{Cache: cache})
+	if err != nil {
+		t.Fatalf("FetchConversations error: %v", err)
+	}
+	if want := "/gmail/v1/users/me/threads/t1?minimal,/gmail/v1/users/me/messages/m2?full"; strings.Join(requests, ",") != want {
+		t.Errorf("requests = %v, want only the thread's IDs and the uncached message", requests)
+	}
+	if len(convs) != 1 || len(convs[0].Messages) != 2 {
+		t.Fatalf("conversations = %+v", convs)
+	}
+	c := convs[0]
+	if c.Subject != "Launch plan" || c.Messages[0].Body != "Friday?" || c.Messages[0].Unread {
+		t.Errorf("cached message = %+v", c.Messages[0])
+	}
+	if c.Messages[1].Body != "Yes." || !c.Messages[1].Unread {
+		t.Errorf("fetched message = %+v", c.Messages[1])
+	}
+	if _, ok := cache.Get("m2"); !ok {
+		t.Error("fetched message should be added to the cache")
+	}
+}
+
+func TestBuildConversation_KeepsLatestMessages(t *testing.T) {
+	var emails []EmailSummary
+	for i := range maxThreadMessages + 3 {
+		emails = append(emails, EmailSummary{ID: fmt.Sprintf("m%d", i)})
+	}
+	conv := buildConversation("t1", emails, map[string]bool{"m12": true})
+	if conv.Earlier != 3 || len(conv.Messages) != maxThreadMessages {
+		t.Fatalf("earlier = %d, messages = %d", conv.Earlier, len(conv.Messages))
+	}
+	if conv.Messages[0].ID != "m3" || !conv.Messages[maxThreadMessages-1].Unread {
+		t.Errorf("messages = %+v, want m3..m12 with m12 unread", conv.Messages)
+	}
+}
@@ -0,0 +1,224 @@
+package gmail
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Email categories, as in the digest.
+const (
+	CategoryAction        = "action"
+	CategoryInformational = "informational"
+	CategoryPromotional   = "promotional"
+)
+
+// Classification is Claude's verdict on one email.
+type Classification struct {
+	ID             string `json:"id"`
+	Category       string `json:"category"`
+	Summary        string `json:"summary"`
+	Action         string `json:"action,omitempty"`          // what the reader has to do, for action emails
+	Deadline       string `json:"deadline,omitempty"`        // YYYY-MM-DD, when the email states or implies one
+	SuggestedReply string `json:"suggested_reply,omitempty"` // a reply worth sending, if one is called for
+}
+
+// BuildClassifyPrompt constructs the prompt that classifies each email and
+// asks for the result as JSON.
+func BuildClassifyPrompt(emails []EmailSummary) string {
+	var sb strings.Builder
+	sb.WriteString("You are an email assistant. Classify each of the following emails.\n")
+	sb.WriteString("Categories:\n")
+	sb.WriteString("- action: needs a response, review, decision, or action from the reader\n")
+	sb.WriteString("- informational: worth knowing but no action needed\n")
+	sb.WriteString("- promotional: newsletters, marketing, notifications nobody needs to read\n\n")
+	sb.WriteString("Respond with ONLY a JSON array, one object per email, with these fields:\n")
+	sb.WriteString(`- "id": the email's id exactly as given` + "\n")
+	sb.WriteString(`- "category": "action", "informational" or "promotional"` + "\n")
+	sb.WriteString(`- "summary": one line, ~100 chars max` + "\n")
+	sb.WriteString(`- "action": for action emails, what to do as a short imperative task title; otherwise ""` + "\n")
+	sb.WriteString(`- "deadline": "YYYY-MM-DD" if the email states or clearly implies one; otherwise ""` + "\n")
+	sb.WriteString(`- "suggested_reply": a short, polite reply the reader could send, if a reply is expected; otherwise ""` + "\n\n")
+	fmt.Fprintf(&sb, "--- %d emails ---\n\n", len(emails))
+
+	for _, e := range emails {
+		fmt.Fprintf(&sb, "Email id: %s\n", e.ID)
+		fmt.Fprintf(&sb, "From: %s\n", e.From)
+		fmt.Fprintf(&sb, "Subject: %s\n", e.Subject)
+		fmt.Fprintf(&sb, "Date: %s\n", e.Date)
+		fmt.Fprintf(&sb, "Body:\n%s\n\n", e.Body)
+	}
+
+	return sb.String()
+}
+
+// ParseClassifications reads Claude's JSON answer. Classifications of
+// emails that were not asked about are dropped, and unknown categories are
+// treated as informational.
+func ParseClassifications(output string, emails []EmailSummary) ([]Classification, error) {
+	start, end := strings.Index(output, "["), strings.LastIndex(output, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON array in classification output")
+	}
+	var all []Classification
+	if err := json.Unmarshal([]byte(output[start:end+1]), &all); err != nil {
+		return nil, fmt.Errorf("parse classification: %w", err)
+	}
+
+	known := map[string]bool{}
+	for _, e := range emails {
+		known[e.ID] = true
+	}
+	var classes []Classification
+	for _, c := range all {
+		if !known[c.ID] {
+			continue
+		}
+		c.Category = strings.ToLower(strings.TrimSpace(c.Category))
+		switch c.Category {
+		case CategoryAction, CategoryInformational, CategoryPromotional:
+		default:
+			c.Category = CategoryInformational
+		}
+		classes = append(classes, c)
+	}
+	return classes, nil
+}
+
+// EmailLink returns a link that opens the email in Gmail.
+func EmailLink(id string) string {
+	return "https://mail.google.com/mail/u/0/#all/" + id
+}
+
+// TaskPlan returns the task description for an action email, titled after
+// the action so devpilot push and the runner can read it.
+func TaskPlan(e EmailSummary, c Classification) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", taskTitle(e, c))
+	if c.Deadline != "" {
+		fmt.Fprintf(&sb, "**Deadline:** %s\n\n", c.Deadline)
+	}
+	fmt.Fprintf(&sb, "%s\n\n", c.Summary)
+	sb.WriteString("## Email\n\n")
+	fmt.Fprintf(&sb, "- From: %s\n", e.From)
+	fmt.Fprintf(&sb, "- Subject: %s\n", e.Subject)
+	fmt.Fprintf(&sb, "- Date: %s\n", e.Date)
+	fmt.Fprintf(&sb, "- Link: %s\n", EmailLink(e.ID))
+	return sb.String()
+}
+
+func taskTitle(e EmailSummary, c Classification) string {
+	if c.Action != "" {
+		return c.Action
+	}
+	return e.Subject
+}
+
+// TaskFiler files a plan as a task and returns the task's URL.
+type TaskFiler func(plan string) (string, error)
+
+// Triage acts on classified emails. With DryRun set it only reports what it
+// would do.
+type Triage struct {
+	Client      *Client
+	LabelPrefix string    // labels are LabelPrefix + category; empty skips labelling
+	FileTask    TaskFiler // files action emails as tasks; nil skips tasks
+	Drafts      bool      // save suggested replies as drafts
+	DryRun      bool
+	Out         io.Writer
+
+	labelIDs map[string]string // category label name to ID
+}
+
+// Run triages emails according to classes. Each email is labelled as soon
+// as its task and draft exist, so a run that fails partway can be repeated
+// with ExcludeTriaged without filing the same task twice.
+func (t *Triage) Run(emails []EmailSummary, classes []Classification) error {
+	byID := map[string]EmailSummary{}
+	for _, e := range emails {
+		byID[e.ID] = e
+	}
+	verb := func(s string) string {
+		if t.DryRun {
+			return "Would " + strings.ToLower(s[:1]) + s[1:]
+		}
+		return s
+	}
+
+	for _, c := range classes {
+		e := byID[c.ID]
+		fmt.Fprintf(t.Out, "[%s] %s — %s\n", c.Category, e.Subject, c.Summary)
+
+		if c.Category == CategoryAction && t.FileTask != nil {
+			fmt.Fprintf(t.Out, "  %s\n", verb("Create task: "+taskTitle(e, c)))
+			if !t.DryRun {
+				url, err := t.FileTask(TaskPlan(e, c))
+				if err != nil {
+					return fmt.Errorf("create task for %q: %w", e.Subject, err)
+				}
+				fmt.Fprintf(t.Out, "    %s\n", url)
+			}
+		}
+		if c.Category == CategoryAction && t.Drafts && c.SuggestedReply != "" {
+			fmt.Fprintf(t.Out, "  %s\n", verb("Draft reply: "+firstLine(c.SuggestedReply)))
+			if !t.DryRun {
+				draft := outgoingEmail{To: e.From, Subject: replySubject(e.Subject), InReplyTo: e.MessageID, Body: c.SuggestedReply}
+				if _, err := t.Client.CreateDraft(e.ThreadID, draft.bytes()); err != nil {
+					return fmt.Errorf("draft reply to %q: %w", e.Subject, err)
+				}
+			}
+		}
+
+		if t.LabelPrefix == "" {
+			continue
+		}
+		name := t.LabelPrefix + c.Category
+		fmt.Fprintf(t.Out, "  %s\n", verb("Label "+name))
+		if t.DryRun {
+			continue
+		}
+		labelID, err := t.labelID(name)
+		if err != nil {
+			return err
+		}
+		if err := t.Client.ModifyLabels([]string{c.ID}, []string{labelID}, nil); err != nil {
+			return fmt.Errorf("label %q %s: %w", e.Subject, name, err)
+		}
+	}
+	return nil
+}
+
+// labelID returns the ID of a category label, creating it on first use.
+func (t *Triage) labelID(name string) (string, error) {
+	if id, ok := t.labelIDs[name]; ok {
+		return id, nil
+	}
+	id, err := t.Client.EnsureLabel(name)
+	if err != nil {
+		return "", err
+	}
+	if t.labelIDs == nil {
+		t.labelIDs = map[string]string{}
+	}
+	t.labelIDs[name] = id
+	return id, nil
+}
+
+// ExcludeTriaged returns Gmail search terms that leave out emails already
+// carrying one of the category labels under prefix, e.g.
+// "-label:devpilot-action -label:devpilot-informational -label:devpilot-promotional".
+func ExcludeTriaged(prefix string) string {
+	var terms []string
+	for _, category := range []string{CategoryAction, CategoryInformational, CategoryPromotional} {
+		// Gmail searches labels with "/" and spaces written as "-".
+		name := strings.NewReplacer("/", "-", " ", "-").Replace(strings.ToLower(prefix + category))
+		terms = append(terms, "-label:"+name)
+	}
+	return strings.Join(terms, " ")
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return TruncateBody(line, 80)
+}
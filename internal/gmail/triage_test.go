@@ -0,0 +1,199 @@
+package gmail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var triageEmails = []EmailSummary{
+	{ID: "m1", ThreadID: "t1", MessageID: "<abc@example.com>", From: "ada@example.com", Subject: "Review the Q3 budget", Body: "Please review by Friday."},
+	{ID: "m2", ThreadID: "t2", From: "news@shop.example", Subject: "50% off"},
+}
+
+func TestParseClassifications(t *testing.T) {
+	output := "Here you go:\n```json\n[" +
+		`{"id":"m1","category":"Action","summary":"Ada needs budget review","action":"Review the Q3 budget","deadline":"2026-03-20","suggested_reply":"Will do."},` +
+		`{"id":"m2","category":"spam","summary":"Sale"},` +
+		`{"id":"m9","category":"action","summary":"not asked about"}` +
+		"]\n```"
+	classes, err := ParseClassifications(output, triageEmails)
+	if err != nil {
+		t.Fatalf("ParseClassifications: %v", err)
+	}
+	if len(classes) != 2 {
+		t.Fatalf("classes = %+v, want m1 and m2", classes)
+	}
+	if classes[0].Category != CategoryAction || classes[0].Deadline != "2026-03-20" || classes[0].SuggestedReply != "Will do." {
+		t.Errorf("m1 = %+v", classes[0])
+	}
+	if classes[1].Category != CategoryInformational {
+		t.Errorf("unknown category = %q, want informational", classes[1].Category)
+	}
+
+	if _, err := ParseClassifications("I could not classify these.", triageEmails); err == nil {
+		t.Error("expected an error without a JSON array")
+	}
+}
+
+func TestTaskPlan(t *testing.T) {
+	plan := TaskPlan(triageEmails[0], Classification{ID: "m1", Summary: "Ada needs a budget review", Action: "Review the Q3 budget", Deadline: "2026-03-20"})
+	for _, want := range []string{"# Review the Q3 budget\n", "**Deadline:** 2026-03-20", "- From: ada@example.com", "- Link: https://mail.google.com/mail/u/0/#all/m1"} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("plan is missing %q:\n%s", want, plan)
+		}
+	}
+}
+
+var triageClasses = []Classification{
+	{ID: "m1", Category: CategoryAction, Summary: "Budget review", Action: "Review the Q3 budget", SuggestedReply: "Will do by Friday."},
+	{ID: "m2", Category: CategoryPromotional, Summary: "Sale"},
+}
+
+func TestTriage_DryRunChangesNothing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("dry run called %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	triage := &Triage{
+		Client:      NewClient("test-token", WithBaseURL(srv.URL)),
+		LabelPrefix: "devpilot/",
+		FileTask:    func(string) (string, error) { t.Error("dry run filed a task"); return "", nil },
+		Drafts:      true,
+		DryRun:      true,
+		Out:         &out,
+	}
+	if err := triage.Run(triageEmails, triageClasses); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	for _, want := range []string{"Would create task: Review the Q3 budget", "Would draft reply: Will do by Friday.", "Would label devpilot/action", "Would label devpilot/promotional"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestTriage_Apply(t *testing.T) {
+	var draftRaw, draftThread string
+	modified := map[string][]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/gmail/v1/users/me/labels":
+			w.Write([]byte(`{"labels":[{"id":"INBOX","name":"INBOX","type":"system"},{"id":"Label_1","name":"devpilot/action"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/gmail/v1/users/me/labels":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["name"] != "devpilot/promotional" {
+				t.Errorf("created label %q", body["name"])
+			}
+			w.Write([]byte(`{"id":"Label_2","name":"devpilot/promotional"}`))
+		case r.URL.Path == "/gmail/v1/users/me/messages/batchModify":
+			var body struct {
+				IDs    []any    `json:"ids"`
+				Add    []string `json:"addLabelIds"`
+				Remove []string `json:"removeLabelIds"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if len(body.Remove) != 0 {
+				t.Errorf("removed labels %v", body.Remove)
+			}
+			modified[body.Add[0]] = body.IDs
+			w.Write([]byte(`{}`))
+		case r.URL.Path == "/gmail/v1/users/me/drafts":
+			var body draftRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			raw, _ := base64.URLEncoding.DecodeString(body.Message.Raw)
+			draftRaw, draftThread = string(raw), body.Message.ThreadID
+			w.Write([]byte(`{"id":"d1"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	var plans []string
+	triage := &Triage{
+		Client:      NewClient("test-token", WithBaseURL(srv.URL)),
+		LabelPrefix: "devpilot/",
+		FileTask: func(plan string) (string, error) {
+			plans = append(plans, plan)
+			return "https://trello.com/c/xyz", nil
+		},
+		Drafts: true,
+		Out:    io.Discard,
+	}
+	if err := triage.Run(triageEmails, triageClasses); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if len(plans) != 1 || !strings.HasPrefix(plans[0], "# Review the Q3 budget") {
+		t.Errorf("filed plans = %q", plans)
+	}
+	if len(modified["Label_1"]) != 1 || modified["Label_1"][0] != "m1" || len(modified["Label_2"]) != 1 || modified["Label_2"][0] != "m2" {
+		t.Errorf("labelled = %v", modified)
+	}
+	if draftThread != "t1" {
+		t.Errorf("draft thread = %q, want t1", draftThread)
+	}
+	for _, want := range []string{"To: ada@example.com\r\n", "Subject: Re: Review the Q3 budget\r\n", "In-Reply-To: <abc@example.com>\r\n", "\r\n\r\nWill do by Friday."} {
+		if !strings.Contains(draftRaw, want) {
+			t.Errorf("draft is missing %q:\n%s", want, draftRaw)
+		}
+	}
+}
+
+func TestTriage_LabelsEachEmailAsItGoes(t *testing.T) {
+	var labelled []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gmail/v1/users/me/labels":
+			w.Write([]byte(`{"labels":[{"id":"Label_1","name":"devpilot/action"}]}`))
+		case "/gmail/v1/users/me/messages/batchModify":
+			var body struct {
+				IDs []string `json:"ids"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			labelled = append(labelled, body.IDs...)
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	emails := []EmailSummary{{ID: "m1", Subject: "First"}, {ID: "m2", Subject: "Second"}}
+	classes := []Classification{{ID: "m1", Category: CategoryAction}, {ID: "m2", Category: CategoryAction}}
+	triage := &Triage{
+		Client:      NewClient("test-token", WithBaseURL(srv.URL)),
+		LabelPrefix: "devpilot/",
+		FileTask: func(plan string) (string, error) {
+			if strings.HasPrefix(plan, "# Second") {
+				return "", fmt.Errorf("trello is down")
+			}
+			return "https://trello.com/c/1", nil
+		},
+		Out: io.Discard,
+	}
+	if err := triage.Run(emails, classes); err == nil {
+		t.Fatal("expected the second task to fail")
+	}
+	// The first email's task exists, so it is labelled and a retry skips it.
+	if len(labelled) != 1 || labelled[0] != "m1" {
+		t.Errorf("labelled = %v, want [m1]", labelled)
+	}
+}
+
+func TestExcludeTriaged(t *testing.T) {
+	want := "-label:devpilot-action -label:devpilot-informational -label:devpilot-promotional"
+	if got := ExcludeTriaged("devpilot/"); got != want {
+		t.Errorf("ExcludeTriaged = %q, want %q", got, want)
+	}
+}
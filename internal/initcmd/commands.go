@@ -108,7 +108,7 @@ var initCmd = &cobra.Command{
 
 		// Gitignore
 		if status.IsGitRepo {
-			if err := EnsureGitignore(dir, []string{".devpilot/logs/"}); err != nil {
+			if err := EnsureGitignore(dir, []string{".devpilot/logs/", ".devpilot/attachments/"}); err != nil {
 				fmt.Fprintf(os.Stderr, "  Error updating .gitignore: %v\n", err)
 			}
 		}
@@ -6,13 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
+	"github.com/siyuqian/devpilot/internal/github"
 	"github.com/siyuqian/devpilot/internal/project"
 	"github.com/siyuqian/devpilot/internal/skillmgr"
 )
@@ -197,7 +197,7 @@ var ghRequiredLabels = []ghLabel{
 }
 
 // ConfigureGitHubSource saves source=github to .devpilot.yaml and creates the
-// required labels on the current GitHub repository via the gh CLI.
+// required labels on the project's GitHub repository.
 func ConfigureGitHubSource(opts GenerateOpts) error {
 	cfg, err := project.Load(opts.Dir)
 	if err != nil {
@@ -209,20 +209,32 @@ func ConfigureGitHubSource(opts GenerateOpts) error {
 	}
 	fmt.Println("  Configured task source: GitHub Issues")
 
-	fmt.Println("  Creating required GitHub labels (--force skips existing)...")
+	token, err := github.LoadToken(cfg.GitHub.Host)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  Warning: could not create GitHub labels: %v\n", err)
+		return nil
+	}
+	repo, err := github.ResolveRepo(opts.Dir, cfg.PRRepo, cfg.Remote)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  Warning: could not create GitHub labels: %v\n", err)
+		return nil
+	}
+	createGitHubLabels(github.NewClient(token, github.WithHost(cfg.GitHub.Host)), repo)
+	return nil
+}
+
+// createGitHubLabels creates the labels DevPilot needs, updating any that
+// already exist.
+func createGitHubLabels(client *github.Client, repo string) {
+	fmt.Printf("  Creating required GitHub labels on %s...\n", repo)
 	for _, l := range ghRequiredLabels {
-		out, err := exec.Command("gh", "label", "create", l.name,
-			"--color", l.color,
-			"--description", l.desc,
-			"--force",
-		).CombinedOutput()
+		err := client.EnsureLabel(repo, github.Label{Name: l.name, Color: l.color, Description: l.desc})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "  Warning: could not create label %q: %s\n", l.name, strings.TrimSpace(string(out)))
+			fmt.Fprintf(os.Stderr, "  Warning: could not create label %q: %v\n", l.name, err)
 		} else {
 			fmt.Printf("  Label created: %s\n", l.name)
 		}
 	}
-	return nil
 }
 
 // EnsureGitignore ensures that the given entries exist in .gitignore.
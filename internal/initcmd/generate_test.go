@@ -2,11 +2,16 @@ package initcmd
 
 import (
 	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/siyuqian/devpilot/internal/github"
 	"github.com/siyuqian/devpilot/internal/skillmgr"
 )
 
@@ -226,3 +231,23 @@ func TestInstallSkillsNoSelection(t *testing.T) {
 	}
 }
 
+
+func TestCreateGitHubLabels(t *testing.T) {
+	var created []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/o/r/labels" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var l github.Label
+		json.NewDecoder(r.Body).Decode(&l)
+		created = append(created, l.Name)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+
+	createGitHubLabels(github.NewClient("t", github.WithBaseURL(srv.URL)), "o/r")
+	if len(created) != len(ghRequiredLabels) {
+		t.Errorf("expected %d labels, created %v", len(ghRequiredLabels), created)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/siyuqian/devpilot/internal/auth"
+	"github.com/siyuqian/devpilot/internal/github"
 	"github.com/siyuqian/devpilot/internal/project"
 	"github.com/siyuqian/devpilot/internal/trello"
 	"github.com/spf13/cobra"
@@ -83,7 +84,17 @@ var syncCmd = &cobra.Command{
 			}
 			target = NewTrelloTarget(client, list.ID)
 		case "github":
-			target = NewGitHubTarget()
+			token, err := github.LoadToken(projectCfg.GitHub.Host)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			repo, err := github.ResolveRepo(dir, projectCfg.PRRepo, projectCfg.Remote)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			target = NewGitHubTarget(github.NewClient(token, github.WithHost(projectCfg.GitHub.Host)), repo)
 		default:
 			fmt.Fprintf(os.Stderr, "Unknown source %q\n", sourceName)
 			os.Exit(1)
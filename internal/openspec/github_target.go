@@ -1,48 +1,47 @@
 package openspec
 
 import (
-	"encoding/json"
 	"fmt"
-	"os/exec"
+	"strconv"
+
+	"github.com/siyuqian/devpilot/internal/github"
 )
 
-// GitHubTarget implements SyncTarget using the gh CLI to manage GitHub Issues.
-type GitHubTarget struct{}
+const githubTargetLabel = "devpilot"
 
-// NewGitHubTarget creates a GitHubTarget.
-func NewGitHubTarget() *GitHubTarget {
-	return &GitHubTarget{}
+// GitHubTarget implements SyncTarget on GitHub Issues in a single repository.
+type GitHubTarget struct {
+	client *github.Client
+	repo   string // owner/repo
 }
 
-func (g *GitHubTarget) findArgs(name string) []string {
-	return []string{"issue", "list", "--label", "devpilot", "--state", "open",
-		"--search", name + " in:title", "--json", "number,title", "--limit", "5"}
+// NewGitHubTarget creates a GitHubTarget.
+func NewGitHubTarget(client *github.Client, repo string) *GitHubTarget {
+	return &GitHubTarget{client: client, repo: repo}
 }
 
 func (g *GitHubTarget) FindByName(name string) (string, error) {
-	out, err := exec.Command("gh", g.findArgs(name)...).Output()
+	issues, err := g.client.ListIssues(g.repo, github.IssueListOptions{
+		Labels: []string{githubTargetLabel},
+		State:  "open",
+	})
 	if err != nil {
-		return "", fmt.Errorf("gh issue list: %w", err)
-	}
-	var issues []struct {
-		Number int    `json:"number"`
-		Title  string `json:"title"`
-	}
-	if err := json.Unmarshal(out, &issues); err != nil {
-		return "", fmt.Errorf("parse issues: %w", err)
+		return "", fmt.Errorf("list issues: %w", err)
 	}
 	for _, issue := range issues {
 		if issue.Title == name {
-			return fmt.Sprintf("%d", issue.Number), nil
+			return strconv.Itoa(issue.Number), nil
 		}
 	}
 	return "", nil
 }
 
 func (g *GitHubTarget) Create(name, desc string) error {
-	_, err := exec.Command("gh", "issue", "create",
-		"--title", name, "--body", desc, "--label", "devpilot",
-	).Output()
+	_, err := g.client.CreateIssue(g.repo, github.NewIssue{
+		Title:  name,
+		Body:   desc,
+		Labels: []string{githubTargetLabel},
+	})
 	if err != nil {
 		return fmt.Errorf("create issue: %w", err)
 	}
@@ -50,8 +49,11 @@ func (g *GitHubTarget) Create(name, desc string) error {
 }
 
 func (g *GitHubTarget) Update(id, desc string) error {
-	_, err := exec.Command("gh", "issue", "edit", id, "--body", desc).Output()
+	number, err := strconv.Atoi(id)
 	if err != nil {
+		return fmt.Errorf("invalid issue number %q", id)
+	}
+	if _, err := g.client.EditIssue(g.repo, number, github.IssueEdit{Body: &desc}); err != nil {
 		return fmt.Errorf("update issue %s: %w", id, err)
 	}
 	return nil
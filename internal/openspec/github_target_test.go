@@ -1,35 +1,64 @@
 package openspec
 
-import "testing"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/siyuqian/devpilot/internal/github"
+)
 
 // Compile-time interface compliance check.
 var _ SyncTarget = (*GitHubTarget)(nil)
 
-func TestGitHubTarget_interfaceCompliance(t *testing.T) {
-	// The var _ line above ensures GitHubTarget implements SyncTarget.
-	// This test exists to make the check explicit and visible in test output.
-	g := NewGitHubTarget()
-	if g == nil {
-		t.Fatal("expected non-nil GitHubTarget")
+func TestGitHubTarget_FindByName(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/r/issues" || r.URL.Query().Get("labels") != "devpilot" {
+			t.Errorf("unexpected request: %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `[{"number":3,"title":"add-auth-v2"},{"number":4,"title":"add-auth"}]`)
+	}))
+	defer srv.Close()
+
+	g := NewGitHubTarget(github.NewClient("t", github.WithBaseURL(srv.URL)), "o/r")
+	id, err := g.FindByName("add-auth")
+	if err != nil {
+		t.Fatalf("FindByName: %v", err)
+	}
+	if id != "4" {
+		t.Errorf("expected exact title match 4, got %q", id)
+	}
+	if id, _ := g.FindByName("missing"); id != "" {
+		t.Errorf("expected no match, got %q", id)
 	}
 }
 
-func TestGitHubTarget_buildFindCommand(t *testing.T) {
-	g := NewGitHubTarget()
-	args := g.findArgs("add-auth")
+func TestGitHubTarget_CreateAndUpdate(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /repos/o/r/issues", "PATCH /repos/o/r/issues/4":
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
 
-	expected := []string{
-		"issue", "list", "--label", "devpilot", "--state", "open",
-		"--search", "add-auth in:title", "--json", "number,title", "--limit", "5",
+	g := NewGitHubTarget(github.NewClient("t", github.WithBaseURL(srv.URL)), "o/r")
+	if err := g.Create("add-auth", "desc"); err != nil {
+		t.Fatalf("Create: %v", err)
 	}
-
-	if len(args) != len(expected) {
-		t.Fatalf("expected %d args, got %d: %v", len(expected), len(args), args)
+	if got["title"] != "add-auth" || fmt.Sprint(got["labels"]) != "[devpilot]" {
+		t.Errorf("unexpected create body: %v", got)
 	}
-
-	for i, want := range expected {
-		if args[i] != want {
-			t.Errorf("arg[%d]: expected %q, got %q", i, want, args[i])
-		}
+	if err := g.Update("4", "new desc"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if got["body"] != "new desc" {
+		t.Errorf("unexpected update body: %v", got)
 	}
 }
@@ -10,8 +10,15 @@ import (
 
 // Change represents an OpenSpec change proposal found in openspec/changes/.
 type Change struct {
-	Name        string // directory name
-	Description string // combined proposal.md + tasks.md content
+	Name        string      // directory name
+	Description string      // combined proposal.md + tasks.md content
+	Groups      []TaskGroup // top-level sections of tasks.md, in order
+}
+
+// TaskGroup is a top-level section of a change's tasks.md, e.g. "## 1. Setup".
+type TaskGroup struct {
+	Title string   // heading text without the leading "## "
+	Tasks []string // checklist items under the heading, without the "- [ ] " marker
 }
 
 // ScanChanges reads the openspec/changes/ directory under projectDir and returns
@@ -39,12 +46,61 @@ func ScanChanges(projectDir string) ([]Change, error) {
 		changes = append(changes, Change{
 			Name:        entry.Name(),
 			Description: desc,
+			Groups:      readTaskGroups(changeDir),
 		})
 	}
 
 	return changes, nil
 }
 
+// FindChange returns the change with the given name from projectDir's
+// openspec/changes/, or an error if it does not exist.
+func FindChange(projectDir, name string) (*Change, error) {
+	changes, err := ScanChanges(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		if changes[i].Name == name {
+			return &changes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("change not found: %s", name)
+}
+
+func readTaskGroups(changeDir string) []TaskGroup {
+	data, err := os.ReadFile(filepath.Join(changeDir, "tasks.md"))
+	if err != nil {
+		return nil
+	}
+	return ParseTaskGroups(string(data))
+}
+
+// ParseTaskGroups splits tasks.md content into its "## " sections. Checklist
+// items before the first heading are ignored; headings without items are kept
+// so that section numbering stays aligned with the file.
+func ParseTaskGroups(content string) []TaskGroup {
+	var groups []TaskGroup
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if title, ok := strings.CutPrefix(line, "## "); ok {
+			groups = append(groups, TaskGroup{Title: strings.TrimSpace(title)})
+			continue
+		}
+		if len(groups) == 0 {
+			continue
+		}
+		for _, marker := range []string{"- [ ] ", "- [x] ", "- [X] "} {
+			if item, ok := strings.CutPrefix(line, marker); ok {
+				last := &groups[len(groups)-1]
+				last.Tasks = append(last.Tasks, strings.TrimSpace(item))
+				break
+			}
+		}
+	}
+	return groups
+}
+
 // buildDescription concatenates proposal.md and tasks.md from changeDir,
 // separated by "\n\n---\n\n". Returns empty string if neither file exists.
 func buildDescription(changeDir string) string {
@@ -73,3 +73,60 @@ func TestCheckInstalled_notInstalled(t *testing.T) {
 		t.Fatal("expected error for nonexistent binary, got nil")
 	}
 }
+
+func TestParseTaskGroups(t *testing.T) {
+	content := `Intro text
+- [ ] orphan item
+
+## 1. Setup
+
+- [x] 1.1 Add dependency
+- [ ] 1.2 Create package
+
+## 2. Implementation
+- [ ] 2.1 Write code
+Some note that is not a task
+
+## 3. Verification
+`
+	groups := ParseTaskGroups(content)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d: %+v", len(groups), groups)
+	}
+	if groups[0].Title != "1. Setup" {
+		t.Errorf("group 0 title = %q", groups[0].Title)
+	}
+	if len(groups[0].Tasks) != 2 || groups[0].Tasks[0] != "1.1 Add dependency" {
+		t.Errorf("group 0 tasks = %v", groups[0].Tasks)
+	}
+	if len(groups[1].Tasks) != 1 {
+		t.Errorf("group 1 tasks = %v", groups[1].Tasks)
+	}
+	if len(groups[2].Tasks) != 0 {
+		t.Errorf("group 2 should have no tasks, got %v", groups[2].Tasks)
+	}
+}
+
+func TestFindChange(t *testing.T) {
+	dir := t.TempDir()
+	changeDir := filepath.Join(dir, "openspec", "changes", "add-auth")
+	if err := os.MkdirAll(changeDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tasks := "## 1. Backend\n- [ ] 1.1 API\n\n## 2. Frontend\n- [ ] 2.1 UI\n"
+	if err := os.WriteFile(filepath.Join(changeDir, "tasks.md"), []byte(tasks), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := FindChange(dir, "add-auth")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.Groups) != 2 || c.Groups[1].Title != "2. Frontend" {
+		t.Errorf("unexpected groups: %+v", c.Groups)
+	}
+
+	if _, err := FindChange(dir, "missing"); err == nil {
+		t.Error("expected error for missing change")
+	}
+}
@@ -0,0 +1,140 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/generate"
+	"github.com/siyuqian/devpilot/internal/project"
+	"github.com/spf13/cobra"
+)
+
+func RegisterCommands(parent *cobra.Command) {
+	lintCmd.Flags().Bool("refine", false, "Rewrite plans that fail lint with Claude")
+	lintCmd.Flags().Bool("write", false, "With --refine, write refined plans back to their files")
+	lintCmd.Flags().String("model", "", "Override Claude model for --refine")
+
+	planCmd.AddCommand(lintCmd)
+	parent.AddCommand(planCmd)
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Work with task plans",
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <file>...",
+	Short: "Check that plan files are ready for the task runner",
+	Long: `Check each plan for a title, steps, acceptance criteria and references to
+files that exist in the repository. Use "-" to read a plan from stdin.
+Exits non-zero when any plan has errors.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		refine, _ := cmd.Flags().GetBool("refine")
+		write, _ := cmd.Flags().GetBool("write")
+		model, _ := cmd.Flags().GetString("model")
+
+		dir, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to get working directory:", err)
+			os.Exit(1)
+		}
+		if model == "" {
+			cfg, _ := project.Load(dir)
+			model = cfg.ModelFor("refine")
+		}
+		gen := func(ctx context.Context, prompt string) (string, error) {
+			return generate.Generate(ctx, prompt, model)
+		}
+
+		failed := false
+		for _, path := range args {
+			content, err := readPlan(path)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				failed = true
+				continue
+			}
+			result := Lint(content, Options{Dir: dir})
+			printResult(path, result)
+			if !result.HasErrors() {
+				continue
+			}
+			if !refine {
+				failed = true
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			ref, err := Refine(ctx, gen, content, result, Options{Title: titleOf(path, content), Dir: dir})
+			cancel()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: refine failed: %v\n", path, err)
+				failed = true
+				continue
+			}
+			if ref.NeedsClarification() {
+				fmt.Printf("%s: needs clarification:\n", path)
+				for _, q := range ref.Questions {
+					fmt.Printf("  - %s\n", q)
+				}
+				failed = true
+				continue
+			}
+			if write && path != "-" {
+				if err := os.WriteFile(path, []byte(ref.Plan+"\n"), 0644); err != nil {
+					fmt.Fprintln(os.Stderr, "Error:", err)
+					failed = true
+					continue
+				}
+				fmt.Printf("%s: wrote refined plan\n", path)
+			} else {
+				fmt.Printf("\n%s\n\n", ref.Plan)
+			}
+			again := Lint(ref.Plan, Options{Dir: dir})
+			printResult(path+" (refined)", again)
+			if again.HasErrors() {
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func readPlan(path string) (string, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(path)
+	return string(data), err
+}
+
+func printResult(path string, result Result) {
+	if len(result.Findings) == 0 {
+		fmt.Printf("%s: ok\n", path)
+		return
+	}
+	fmt.Printf("%s:\n", path)
+	for _, f := range result.Findings {
+		fmt.Printf("  %s\n", f)
+	}
+}
+
+// titleOf returns the plan's "# " heading, falling back to the file name.
+func titleOf(path, content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		if title, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+			return title
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
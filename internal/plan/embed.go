@@ -0,0 +1,6 @@
+package plan
+
+import "embed"
+
+//go:embed prompts
+var promptsFS embed.FS
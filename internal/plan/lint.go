@@ -0,0 +1,147 @@
+package plan
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Severity says whether a finding blocks execution.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Lint rules.
+const (
+	RuleTitle      = "title"
+	RuleLength     = "length"
+	RuleSteps      = "steps"
+	RuleAcceptance = "acceptance"
+	RuleFiles      = "files"
+)
+
+// minWords is the shortest plan worth handing to the executor; anything
+// shorter is an idea, not a plan.
+const minWords = 20
+
+// Finding is a single lint result.
+type Finding struct {
+	Severity Severity
+	Rule     string
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s [%s] %s", f.Severity, f.Rule, f.Message)
+}
+
+// Options configures Lint.
+type Options struct {
+	// Title is the name the plan is stored under, e.g. the card or issue
+	// title. A plan without a "# " heading passes the title rule if set.
+	Title string
+	// Dir is the repository root referenced files are resolved against.
+	// Empty skips the files rule.
+	Dir string
+}
+
+// Result holds every finding of a Lint run.
+type Result struct {
+	Findings []Finding
+}
+
+// HasErrors reports whether any finding is an error.
+func (r Result) HasErrors() bool {
+	for _, f := range r.Findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Result) String() string {
+	lines := make([]string, len(r.Findings))
+	for i, f := range r.Findings {
+		lines[i] = "- " + f.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	stepRe       = regexp.MustCompile(`^(\d+[.)]|[-*] \[[ xX]\])\s+\S`)
+	stepHeadRe   = regexp.MustCompile(`(?i)^#{2,4}\s+(step|task)\b`)
+	acceptanceRe = regexp.MustCompile(`(?i)^(#{1,4}\s+|\*\*)\s*(acceptance criteria|success criteria|definition of done|verification|test plan|testing)\b`)
+	pathRe       = regexp.MustCompile("`([A-Za-z0-9_.-]+(?:/[A-Za-z0-9_.-]+)+|[A-Za-z0-9_-]+\\.(?:go|md|ya?ml|json|toml|tmpl|sh|ts|tsx|js|py|sql|proto))`")
+	newFileRe    = regexp.MustCompile(`(?i)\b(creat(e|es|ing)|new)\b`)
+)
+
+// Lint checks that a plan is structured well enough to execute: it has a
+// title, numbered steps and acceptance criteria, and the files it names
+// exist in the repository.
+func Lint(content string, opts Options) Result {
+	var r Result
+	add := func(sev Severity, rule, format string, args ...any) {
+		r.Findings = append(r.Findings, Finding{Severity: sev, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var hasTitle, hasAcceptance bool
+	steps := 0
+	var missing []string
+	seen := map[string]bool{}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "# ") {
+			hasTitle = true
+		}
+		if stepRe.MatchString(line) || stepHeadRe.MatchString(line) {
+			steps++
+		}
+		if acceptanceRe.MatchString(line) {
+			hasAcceptance = true
+		}
+		if opts.Dir == "" {
+			continue
+		}
+		for _, m := range pathRe.FindAllStringSubmatch(line, -1) {
+			path := m[1]
+			if seen[path] || strings.Contains(path, "..") {
+				continue
+			}
+			seen[path] = true
+			if _, err := os.Stat(filepath.Join(opts.Dir, path)); err == nil {
+				continue
+			}
+			// Plans may name files they are about to create.
+			if newFileRe.MatchString(line) {
+				continue
+			}
+			missing = append(missing, path)
+		}
+	}
+
+	if !hasTitle && strings.TrimSpace(opts.Title) == "" {
+		add(SeverityError, RuleTitle, `no title: start the plan with a "# " heading`)
+	}
+	if words := len(strings.Fields(content)); words < minWords {
+		add(SeverityError, RuleLength, "plan is too short to execute (%d words, want at least %d)", words, minWords)
+	}
+	if steps == 0 {
+		add(SeverityError, RuleSteps, "no steps: list the work as numbered steps or a checklist")
+	}
+	if !hasAcceptance {
+		add(SeverityWarning, RuleAcceptance, `no acceptance criteria: add an "## Acceptance Criteria" section`)
+	}
+	for _, path := range missing {
+		add(SeverityWarning, RuleFiles, "references %s, which does not exist", path)
+	}
+	return r
+}
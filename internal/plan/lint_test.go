@@ -0,0 +1,66 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const goodPlan = "# Add dark mode\n\n" +
+	"Users want a dark theme for the dashboard, toggled from the settings page and remembered per browser.\n\n" +
+	"## Steps\n\n" +
+	"1. Add a `theme` field to the settings struct in `internal/settings/settings.go`.\n" +
+	"2. Create `web/theme.css` with the dark palette.\n\n" +
+	"## Acceptance Criteria\n\n" +
+	"- `go test ./...` passes.\n"
+
+func rules(r Result) []string {
+	var out []string
+	for _, f := range r.Findings {
+		out = append(out, string(f.Severity)+":"+f.Rule)
+	}
+	return out
+}
+
+func TestLint_GoodPlan(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "internal/settings"), 0755)
+	os.WriteFile(filepath.Join(dir, "internal/settings/settings.go"), nil, 0644)
+
+	r := Lint(goodPlan, Options{Dir: dir})
+	if len(r.Findings) != 0 {
+		t.Errorf("expected no findings, got %v", r.Findings)
+	}
+}
+
+func TestLint_VaguePlan(t *testing.T) {
+	r := Lint("make the login faster", Options{})
+	got := strings.Join(rules(r), ",")
+	want := "error:title,error:length,error:steps,warning:acceptance"
+	if got != want {
+		t.Errorf("findings = %s, want %s", got, want)
+	}
+	if !r.HasErrors() {
+		t.Error("expected errors")
+	}
+}
+
+func TestLint_TitleFromOptions(t *testing.T) {
+	plan := strings.Replace(goodPlan, "# Add dark mode\n", "", 1)
+	r := Lint(plan, Options{Title: "Add dark mode"})
+	if r.HasErrors() {
+		t.Errorf("unexpected errors: %v", r.Findings)
+	}
+}
+
+func TestLint_MissingFiles(t *testing.T) {
+	// settings.go does not exist; theme.css is being created.
+	r := Lint(goodPlan, Options{Dir: t.TempDir()})
+	if r.HasErrors() {
+		t.Fatalf("missing files should only warn: %v", r.Findings)
+	}
+	if len(r.Findings) != 1 || r.Findings[0].Rule != RuleFiles || !strings.Contains(r.Findings[0].Message, "internal/settings/settings.go") {
+		t.Errorf("findings = %v", r.Findings)
+	}
+}
@@ -0,0 +1,96 @@
+package plan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var refineTmpl = template.Must(template.ParseFS(promptsFS, "prompts/refine.tmpl"))
+
+// refinerSkillPath is where `devpilot skill add task-refiner` installs the
+// skill, relative to the repository root.
+var refinerSkillPath = filepath.Join(".claude", "skills", "task-refiner", "SKILL.md")
+
+// clarificationMarker starts refiner output for tasks a human must clarify.
+const clarificationMarker = "NEEDS CLARIFICATION"
+
+// Generator runs a prompt through Claude and returns its output.
+type Generator func(ctx context.Context, prompt string) (string, error)
+
+// Refinement is the outcome of running the refiner on a plan: either a
+// rewritten plan or the questions a human must answer first.
+type Refinement struct {
+	Plan      string
+	Questions []string
+}
+
+// NeedsClarification reports whether the refiner could not plan the task.
+func (r Refinement) NeedsClarification() bool {
+	return len(r.Questions) > 0
+}
+
+// Refine asks Claude to rewrite plan into an executable one, addressing the
+// problems in lint. When the repository at opts.Dir has the task-refiner
+// skill installed, its instructions are included in the prompt; the prompt
+// itself only adapts the interactive skill to an unattended run and fixes the
+// output format.
+func Refine(ctx context.Context, generate Generator, plan string, lint Result, opts Options) (Refinement, error) {
+	var buf bytes.Buffer
+	data := struct {
+		Title, Plan, Findings, Skill string
+	}{opts.Title, plan, lint.String(), refinerSkill(opts.Dir)}
+	if err := refineTmpl.Execute(&buf, data); err != nil {
+		return Refinement{}, fmt.Errorf("render refine prompt: %w", err)
+	}
+	out, err := generate(ctx, buf.String())
+	if err != nil {
+		return Refinement{}, err
+	}
+	return parseRefinement(out)
+}
+
+// refinerSkill returns the body of the task-refiner skill installed in dir,
+// without its front matter, or "" if it is not installed.
+func refinerSkill(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(dir, refinerSkillPath))
+	if err != nil {
+		return ""
+	}
+	body := strings.TrimSpace(string(data))
+	if rest, ok := strings.CutPrefix(body, "---\n"); ok {
+		if _, after, found := strings.Cut(rest, "\n---"); found {
+			body = strings.TrimSpace(after)
+		}
+	}
+	return body
+}
+
+func parseRefinement(out string) (Refinement, error) {
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return Refinement{}, fmt.Errorf("refiner returned no output")
+	}
+	rest, ok := strings.CutPrefix(out, clarificationMarker)
+	if !ok {
+		return Refinement{Plan: out}, nil
+	}
+	var questions []string
+	for _, line := range strings.Split(rest, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*"))
+		if line != "" {
+			questions = append(questions, line)
+		}
+	}
+	if len(questions) == 0 {
+		questions = []string{"The task is too ambiguous to plan; please describe the expected outcome."}
+	}
+	return Refinement{Questions: questions}, nil
+}
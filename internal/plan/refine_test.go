@@ -0,0 +1,70 @@
+package plan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRefine(t *testing.T) {
+	var prompt string
+	gen := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return "# Speed up login\n\n## Steps\n1. Cache the session lookup.", nil
+	}
+	lint := Lint("make the login faster", Options{})
+	ref, err := Refine(context.Background(), gen, "make the login faster", lint, Options{Title: "Speed up login"})
+	if err != nil {
+		t.Fatalf("Refine: %v", err)
+	}
+	if ref.NeedsClarification() || !strings.HasPrefix(ref.Plan, "# Speed up login") {
+		t.Errorf("unexpected refinement: %+v", ref)
+	}
+	if !strings.Contains(prompt, "make the login faster") || !strings.Contains(prompt, "error [steps]") {
+		t.Errorf("prompt missing plan or findings:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "<skill>") {
+		t.Errorf("prompt should not include a skill that is not installed:\n%s", prompt)
+	}
+}
+
+func TestRefine_UsesInstalledSkill(t *testing.T) {
+	dir := t.TempDir()
+	skill := "---\nname: task-refiner\ndescription: Improve plans\n---\n\n# Task Refiner\n\nCheck docs/rejected/ first."
+	os.MkdirAll(filepath.Join(dir, ".claude", "skills", "task-refiner"), 0755)
+	os.WriteFile(filepath.Join(dir, refinerSkillPath), []byte(skill), 0644)
+
+	var prompt string
+	gen := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return "# Speed up login\n\n## Steps\n1. Cache the session lookup.", nil
+	}
+	lint := Lint("make the login faster", Options{})
+	if _, err := Refine(context.Background(), gen, "make the login faster", lint, Options{Title: "Speed up login", Dir: dir}); err != nil {
+		t.Fatalf("Refine: %v", err)
+	}
+	if !strings.Contains(prompt, "<skill>\n# Task Refiner\n\nCheck docs/rejected/ first.\n</skill>") {
+		t.Errorf("prompt should include the skill body:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "name: task-refiner") {
+		t.Errorf("prompt should drop the skill's front matter:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "NEEDS CLARIFICATION") {
+		t.Errorf("prompt should keep the output format:\n%s", prompt)
+	}
+}
+
+func TestParseRefinement_Clarification(t *testing.T) {
+	ref, err := parseRefinement("NEEDS CLARIFICATION\n- Which login, web or CLI?\n- What is the target latency?\n")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !ref.NeedsClarification() || len(ref.Questions) != 2 || ref.Questions[0] != "Which login, web or CLI?" {
+		t.Errorf("unexpected refinement: %+v", ref)
+	}
+	if ref.Plan != "" {
+		t.Errorf("plan should be empty, got %q", ref.Plan)
+	}
+}
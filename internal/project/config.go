@@ -1,8 +1,11 @@
 package project
 
 import (
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -18,13 +21,179 @@ type SkillEntry struct {
 	InstalledAt time.Time `yaml:"installedAt"`
 }
 
+// PRRules controls which task metadata the runner carries over to the PRs it
+// creates.
+type PRRules struct {
+	Labels     map[string]string `yaml:"labels,omitempty"`     // source label -> PR label (matched case-insensitively)
+	Reviewers  []string          `yaml:"reviewers,omitempty"`  // users or org/team slugs to request review from
+	CodeOwners bool              `yaml:"codeowners,omitempty"` // also request review from CODEOWNERS of changed files
+}
+
+// PRLabelFor returns the PR label mapped from a source label, or "" if unmapped.
+// An exact match wins; among keys differing only in case, the first in
+// sorted order does, so the result never depends on map iteration.
+func (r PRRules) PRLabelFor(sourceLabel string) string {
+	if to, ok := r.Labels[sourceLabel]; ok {
+		return to
+	}
+	for _, from := range slices.Sorted(maps.Keys(r.Labels)) {
+		if strings.EqualFold(from, sourceLabel) {
+			return r.Labels[from]
+		}
+	}
+	return ""
+}
+
+// GitHubConfig configures the GitHub API host and, for a runner serving
+// several repositories, which ones it takes tasks from.
+type GitHubConfig struct {
+	Host      string       `yaml:"host,omitempty"`      // GitHub Enterprise host; empty means github.com
+	Repos     []string     `yaml:"repos,omitempty"`     // owner/repo targets; empty means the current checkout's repo
+	Workspace string       `yaml:"workspace,omitempty"` // where per-repo checkouts live (default ~/.config/devpilot/workspaces)
+	Project   ProjectBoard `yaml:"project,omitempty"`   // Projects (v2) board for the github-project source
+}
+
+// ProjectBoard configures a GitHub Projects (v2) board used as a task source.
+// Empty field and status names fall back to the defaults returned by
+// WithDefaults.
+type ProjectBoard struct {
+	Owner         string `yaml:"owner,omitempty"`         // organization or user owning the project
+	Number        int    `yaml:"number,omitempty"`        // project number from its URL
+	StatusField   string `yaml:"statusField,omitempty"`   // single-select field tracking task state (default Status)
+	PriorityField string `yaml:"priorityField,omitempty"` // field holding P0/P1/P2 or 0/1/2 (default Priority)
+	Ready         string `yaml:"ready,omitempty"`         // status option of tasks to pick up (default Ready)
+	InProgress    string `yaml:"inProgress,omitempty"`    // default In Progress
+	Done          string `yaml:"done,omitempty"`          // default Done
+	Failed        string `yaml:"failed,omitempty"`        // default Failed
+}
+
+// WithDefaults returns b with empty field and status names filled in.
+func (b ProjectBoard) WithDefaults() ProjectBoard {
+	set := func(v *string, def string) {
+		if *v == "" {
+			*v = def
+		}
+	}
+	set(&b.StatusField, "Status")
+	set(&b.PriorityField, "Priority")
+	set(&b.Ready, "Ready")
+	set(&b.InProgress, "In Progress")
+	set(&b.Done, "Done")
+	set(&b.Failed, "Failed")
+	return b
+}
+
+// RunnerConfig identifies a runner and selects the tasks it takes, for
+// boards shared by several runners.
+type RunnerConfig struct {
+	ID          string        `yaml:"id,omitempty"`          // name recorded in task leases (default <hostname>-<pid>)
+	Selector    []string      `yaml:"selector,omitempty"`    // labels a task must carry, e.g. runner:gpu-free
+	LeaseTTL    time.Duration `yaml:"leaseTTL,omitempty"`    // how long a claim lasts without a heartbeat (default 10m)
+	Approval    bool          `yaml:"approval,omitempty"`    // tasks wait for /devpilot approve before starting
+	SlackListen string        `yaml:"slackListen,omitempty"` // address for the Slack slash command endpoint, e.g. :3000
+}
+
+// ContextConfig selects the extra context task sources gather around a plan
+// for the execution prompt.
+type ContextConfig struct {
+	Comments           bool  `yaml:"comments,omitempty"`
+	Checklists         bool  `yaml:"checklists,omitempty"`
+	Attachments        bool  `yaml:"attachments,omitempty"`        // download into .devpilot/attachments/<task> in the checkout
+	Linked             bool  `yaml:"linked,omitempty"`             // issues referenced from the task
+	MaxPromptBytes     int   `yaml:"maxPromptBytes,omitempty"`     // cap on context added to the prompt (default 16000)
+	MaxAttachmentBytes int64 `yaml:"maxAttachmentBytes,omitempty"` // larger attachments are listed but not downloaded (default 10 MiB)
+}
+
+// Enabled reports whether any context is gathered.
+func (c ContextConfig) Enabled() bool {
+	return c.Comments || c.Checklists || c.Attachments || c.Linked
+}
+
+// ReviewPanel replaces the single code review with several reviewers whose
+// verdicts are combined by Policy.
+type ReviewPanel struct {
+	Policy    string          `yaml:"policy,omitempty"` // "all" (default) or "majority" must approve
+	Reviewers []PanelReviewer `yaml:"reviewers,omitempty"`
+}
+
+// PanelReviewer is one member of a review panel.
+type PanelReviewer struct {
+	Name   string `yaml:"name"`
+	Focus  string `yaml:"focus,omitempty"`  // what to look for, added to the panel review prompt
+	Prompt string `yaml:"prompt,omitempty"` // path to a template replacing the panel review prompt
+	Model  string `yaml:"model,omitempty"`  // default: the review entry in models
+	Veto   bool   `yaml:"veto,omitempty"`   // a rejection fails the review whatever the policy
+}
+
+// EscalationConfig retries a failed task on the next, stronger model of a
+// ladder.
+type EscalationConfig struct {
+	Ladder []string `yaml:"ladder,omitempty"` // models from cheapest to strongest, e.g. [sonnet, opus]
+	On     []string `yaml:"on,omitempty"`     // failures that move up a tier: no-commits, execution, review (default all)
+}
+
+// ScheduleConfig limits when the runner starts tasks. Windows are
+// "HH:MM-HH:MM", optionally preceded by days ("Mon-Fri 09:00-17:00"); a
+// window may wrap past midnight.
+type ScheduleConfig struct {
+	Timezone    string   `yaml:"timezone,omitempty"`    // IANA name; default local time
+	ActiveHours []string `yaml:"activeHours,omitempty"` // windows tasks may start in; default any time
+	Quiet       []string `yaml:"quiet,omitempty"`       // windows no new task starts in; a running task finishes
+	Cron        []string `yaml:"cron,omitempty"`        // five-field cron times at which the runner drains the queue
+	Drain       bool     `yaml:"drain,omitempty"`       // exit once no task is ready
+}
+
+// NotifyConfig sends runner events to chat.
+type NotifyConfig struct {
+	Slack SlackNotify `yaml:"slack,omitempty"`
+}
+
+// SlackNotify posts runner events to a Slack channel, one thread per task.
+type SlackNotify struct {
+	Channel string   `yaml:"channel,omitempty"` // channel name or ID; empty disables Slack notifications
+	Events  []string `yaml:"events,omitempty"`  // event types to post; default task start, review, fix, escalation, done and failed
+}
+
+// GmailConfig configures the gmail commands.
+type GmailConfig struct {
+	Digest []DigestSink `yaml:"digest,omitempty"` // where gmail summary delivers its digest
+}
+
+// DigestSink is one destination of the Gmail digest. Type selects the
+// destination; the other fields apply to the types noted.
+type DigestSink struct {
+	Type    string `yaml:"type"`              // "slack", "email", "file", "webhook" or "trello"
+	Channel string `yaml:"channel,omitempty"` // slack: channel name or ID, or a user ID for a DM
+	To      string `yaml:"to,omitempty"`      // email: recipient; default the signed-in address
+	Path    string `yaml:"path,omitempty"`    // file: note to append to, or a directory (e.g. an Obsidian vault folder) for one note per day
+	URL     string `yaml:"url,omitempty"`     // webhook: endpoint the digest is POSTed to as JSON
+	Board   string `yaml:"board,omitempty"`   // trello: board name; default the project board
+	List    string `yaml:"list,omitempty"`    // trello: list name (default Inbox)
+}
+
 // Config represents project-level configuration stored in .devpilot.yaml.
 type Config struct {
 	Board              string            `yaml:"board,omitempty"`
-	Source             string            `yaml:"source,omitempty"` // "trello" or "github"
+	Source             string            `yaml:"source,omitempty"` // "trello", "github" or "github-project"
 	Models             map[string]string `yaml:"models,omitempty"`
 	OpenSpecMinVersion string            `yaml:"openspecMinVersion,omitempty"`
 	Skills             []SkillEntry      `yaml:"skills,omitempty"`
+	BaseBranch         string            `yaml:"baseBranch,omitempty"`   // branch tasks start from and PRs target
+	Remote             string            `yaml:"remote,omitempty"`       // git remote to push to (default origin)
+	PRRepo             string            `yaml:"prRepo,omitempty"`       // owner/repo PRs are opened against
+	StackedPRs         bool              `yaml:"stackedPRs,omitempty"`   // one stacked PR per OpenSpec task group
+	PRTemplate         string            `yaml:"prTemplate,omitempty"`   // path to a custom PR body template
+	BranchPolicy       string            `yaml:"branchPolicy,omitempty"` // "reset" or "rebase" a task branch left by an earlier run
+	PlanGate           string            `yaml:"planGate,omitempty"`     // "lint" or "refine" plans before running them
+	PR                 PRRules           `yaml:"pr,omitempty"`
+	GitHub             GitHubConfig      `yaml:"github,omitempty"`
+	Runner             RunnerConfig      `yaml:"runner,omitempty"`
+	Context            ContextConfig     `yaml:"context,omitempty"`
+	Review             ReviewPanel       `yaml:"review,omitempty"`
+	Escalation         EscalationConfig  `yaml:"escalation,omitempty"`
+	Schedule           ScheduleConfig    `yaml:"schedule,omitempty"`
+	Notify             NotifyConfig      `yaml:"notify,omitempty"`
+	Gmail              GmailConfig       `yaml:"gmail,omitempty"`
 }
 
 // ResolveSource returns the effective task source: flag value takes priority,
@@ -64,7 +233,11 @@ func (c *Config) UpsertSkill(entry SkillEntry) {
 // Load reads .devpilot.yaml from dir. Returns a zero-value Config (not an error)
 // if the file does not exist.
 func Load(dir string) (*Config, error) {
-	data, err := os.ReadFile(filepath.Join(dir, configFile))
+	return loadFile(filepath.Join(dir, configFile))
+}
+
+func loadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return &Config{}, nil
@@ -78,6 +251,20 @@ func Load(dir string) (*Config, error) {
 	return &cfg, nil
 }
 
+// userConfigPath returns ~/.config/devpilot/config.yaml. Tests replace it.
+var userConfigPath = func() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "devpilot", "config.yaml")
+}
+
+// LoadUser reads the user-level config, ~/.config/devpilot/config.yaml. It
+// has the same layout as .devpilot.yaml and holds settings that follow the
+// user rather than a project, such as where the Gmail digest goes. A
+// missing file yields an empty config.
+func LoadUser() (*Config, error) {
+	return loadFile(userConfigPath())
+}
+
 // Save writes cfg to .devpilot.yaml in dir, creating intermediate directories.
 func Save(dir string, cfg *Config) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
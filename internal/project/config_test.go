@@ -3,6 +3,7 @@ package project
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -262,3 +263,230 @@ func TestSkillsRoundTrip(t *testing.T) {
 		t.Errorf("InstalledAt = %v, want %v", s.InstalledAt, now)
 	}
 }
+
+func TestConfig_GitTargetFields(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &Config{BaseBranch: "develop", Remote: "upstream", PRRepo: "acme/app", BranchPolicy: "rebase"}
+	if err := Save(dir, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.BaseBranch != "develop" || loaded.Remote != "upstream" || loaded.PRRepo != "acme/app" || loaded.BranchPolicy != "rebase" {
+		t.Errorf("unexpected git target fields: %+v", loaded)
+	}
+}
+
+func TestConfig_PRRules(t *testing.T) {
+	dir := t.TempDir()
+	data := "pr:\n  labels:\n    Bug: bug\n    area:frontend: frontend\n  reviewers:\n    - alice\n    - acme/platform\n  codeowners: true\n"
+	os.WriteFile(filepath.Join(dir, ".devpilot.yaml"), []byte(data), 0644)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !cfg.PR.CodeOwners || len(cfg.PR.Reviewers) != 2 {
+		t.Errorf("unexpected PR rules: %+v", cfg.PR)
+	}
+	if got := cfg.PR.PRLabelFor("bug"); got != "bug" {
+		t.Errorf("PRLabelFor(bug) = %q, want bug (case-insensitive)", got)
+	}
+	if got := cfg.PR.PRLabelFor("area:frontend"); got != "frontend" {
+		t.Errorf("PRLabelFor(area:frontend) = %q, want frontend", got)
+	}
+	if got := cfg.PR.PRLabelFor("P1-high"); got != "" {
+		t.Errorf("PRLabelFor(P1-high) = %q, want empty", got)
+	}
+
+	rules := PRRules{Labels: map[string]string{"bug": "bug", "Bug": "type: bug", "BUG": "defect"}}
+	for range 20 {
+		if got := rules.PRLabelFor("Bug"); got != "type: bug" {
+			t.Fatalf("PRLabelFor(Bug) = %q, want the exact match", got)
+		}
+		if got := rules.PRLabelFor("bUG"); got != "defect" {
+			t.Fatalf("PRLabelFor(bUG) = %q, want the first key in sorted order", got)
+		}
+	}
+}
+
+func TestSaveOmitsEmptyPRRules(t *testing.T) {
+	dir := t.TempDir()
+	if err := Save(dir, &Config{Board: "b"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".devpilot.yaml"))
+	if strings.Contains(string(data), "pr:") {
+		t.Errorf("empty PR rules should be omitted, got %q", data)
+	}
+}
+
+func TestConfig_GitHub(t *testing.T) {
+	dir := t.TempDir()
+	data := "source: github\ngithub:\n  host: ghe.example.com\n  repos: [acme/api, acme/web]\n  workspace: /srv/devpilot\n"
+	os.WriteFile(filepath.Join(dir, ".devpilot.yaml"), []byte(data), 0644)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.GitHub.Host != "ghe.example.com" || len(cfg.GitHub.Repos) != 2 || cfg.GitHub.Workspace != "/srv/devpilot" {
+		t.Errorf("unexpected github config: %+v", cfg.GitHub)
+	}
+}
+
+func TestConfig_GitHubProject(t *testing.T) {
+	dir := t.TempDir()
+	data := "source: github-project\ngithub:\n  project:\n    owner: acme\n    number: 7\n    ready: Todo\n"
+	os.WriteFile(filepath.Join(dir, ".devpilot.yaml"), []byte(data), 0644)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	board := cfg.GitHub.Project.WithDefaults()
+	if board.Owner != "acme" || board.Number != 7 {
+		t.Errorf("unexpected project: %+v", board)
+	}
+	if board.Ready != "Todo" || board.StatusField != "Status" || board.Failed != "Failed" {
+		t.Errorf("unexpected defaults: %+v", board)
+	}
+}
+
+func TestConfig_Runner(t *testing.T) {
+	dir := t.TempDir()
+	data := "runner:\n  id: gpu-1\n  selector: [runner:gpu-free, area:ml]\n  leaseTTL: 5m\n"
+	os.WriteFile(filepath.Join(dir, ".devpilot.yaml"), []byte(data), 0644)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Runner.ID != "gpu-1" || len(cfg.Runner.Selector) != 2 || cfg.Runner.LeaseTTL != 5*time.Minute {
+		t.Errorf("unexpected runner config: %+v", cfg.Runner)
+	}
+}
+
+func TestConfig_Context(t *testing.T) {
+	dir := t.TempDir()
+	data := "context:\n  comments: true\n  attachments: true\n  maxPromptBytes: 8000\n"
+	os.WriteFile(filepath.Join(dir, ".devpilot.yaml"), []byte(data), 0644)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !cfg.Context.Enabled() || !cfg.Context.Attachments || cfg.Context.Checklists || cfg.Context.MaxPromptBytes != 8000 {
+		t.Errorf("unexpected context config: %+v", cfg.Context)
+	}
+	if (ContextConfig{}).Enabled() {
+		t.Error("zero ContextConfig should be disabled")
+	}
+}
+
+func TestConfig_Review(t *testing.T) {
+	dir := t.TempDir()
+	data := `review:
+  policy: majority
+  reviewers:
+    - name: security
+      focus: injection and secrets
+      model: opus
+      veto: true
+    - name: style
+      prompt: .devpilot/prompts/style-review.tmpl
+`
+	os.WriteFile(filepath.Join(dir, ".devpilot.yaml"), []byte(data), 0644)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	r := cfg.Review
+	if r.Policy != "majority" || len(r.Reviewers) != 2 {
+		t.Fatalf("unexpected review config: %+v", r)
+	}
+	if sec := r.Reviewers[0]; sec.Name != "security" || sec.Model != "opus" || !sec.Veto || sec.Focus == "" {
+		t.Errorf("unexpected security reviewer: %+v", sec)
+	}
+	if style := r.Reviewers[1]; style.Veto || style.Prompt != ".devpilot/prompts/style-review.tmpl" {
+		t.Errorf("unexpected style reviewer: %+v", style)
+	}
+}
+
+func TestConfig_Escalation(t *testing.T) {
+	dir := t.TempDir()
+	data := "escalation:\n  ladder: [sonnet, opus]\n  on: [no-commits, review]\n"
+	os.WriteFile(filepath.Join(dir, ".devpilot.yaml"), []byte(data), 0644)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	e := cfg.Escalation
+	if len(e.Ladder) != 2 || e.Ladder[0] != "sonnet" || e.Ladder[1] != "opus" {
+		t.Errorf("ladder = %v", e.Ladder)
+	}
+	if len(e.On) != 2 || e.On[1] != "review" {
+		t.Errorf("on = %v", e.On)
+	}
+}
+
+func TestConfig_Schedule(t *testing.T) {
+	dir := t.TempDir()
+	data := `schedule:
+  timezone: Europe/Berlin
+  activeHours: ["22:00-06:00", "Sat,Sun 00:00-24:00"]
+  quiet: ["Mon 02:00-03:00"]
+  cron: ["0 22 * * 1-5"]
+  drain: true
+`
+	os.WriteFile(filepath.Join(dir, ".devpilot.yaml"), []byte(data), 0644)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	s := cfg.Schedule
+	if s.Timezone != "Europe/Berlin" || len(s.ActiveHours) != 2 || len(s.Quiet) != 1 || len(s.Cron) != 1 || !s.Drain {
+		t.Errorf("unexpected schedule: %+v", s)
+	}
+}
+
+func TestConfig_Notify(t *testing.T) {
+	dir := t.TempDir()
+	data := "notify:\n  slack:\n    channel: \"#devpilot\"\n    events: [card_done, card_failed]\n"
+	os.WriteFile(filepath.Join(dir, ".devpilot.yaml"), []byte(data), 0644)
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if s := cfg.Notify.Slack; s.Channel != "#devpilot" || len(s.Events) != 2 || s.Events[1] != "card_failed" {
+		t.Errorf("unexpected slack notify config: %+v", s)
+	}
+}
+
+func TestLoadUser(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	orig := userConfigPath
+	userConfigPath = func() string { return path }
+	defer func() { userConfigPath = orig }()
+
+	cfg, err := LoadUser()
+	if err != nil || len(cfg.Gmail.Digest) != 0 {
+		t.Fatalf("missing user config: %+v, %v", cfg, err)
+	}
+
+	os.WriteFile(path, []byte("gmail:\n  digest:\n    - type: file\n      path: ~/vault/Inbox\n    - type: email\n"), 0644)
+	cfg, err = LoadUser()
+	if err != nil {
+		t.Fatalf("LoadUser: %v", err)
+	}
+	want := []DigestSink{{Type: "file", Path: "~/vault/Inbox"}, {Type: "email"}}
+	if len(cfg.Gmail.Digest) != 2 || cfg.Gmail.Digest[0] != want[0] || cfg.Gmail.Digest[1] != want[1] {
+		t.Errorf("digest sinks = %+v, want %+v", cfg.Gmail.Digest, want)
+	}
+}
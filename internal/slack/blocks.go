@@ -0,0 +1,62 @@
+package slack
+
+import "strings"
+
+// Block is a Block Kit layout block, encoded as Slack expects it.
+type Block map[string]any
+
+// Header returns a header block with plain text.
+func Header(text string) Block {
+	return Block{"type": "header", "text": Block{"type": "plain_text", "text": text, "emoji": true}}
+}
+
+// Section returns a section block with mrkdwn text.
+func Section(mrkdwn string) Block {
+	return Block{"type": "section", "text": Block{"type": "mrkdwn", "text": mrkdwn}}
+}
+
+// Context returns a context block of mrkdwn elements, rendered small and
+// grey under a message.
+func Context(mrkdwn ...string) Block {
+	elements := make([]Block, len(mrkdwn))
+	for i, text := range mrkdwn {
+		elements[i] = Block{"type": "mrkdwn", "text": text}
+	}
+	return Block{"type": "context", "elements": elements}
+}
+
+// Divider returns a divider block.
+func Divider() Block {
+	return Block{"type": "divider"}
+}
+
+// Escape escapes the characters Slack's mrkdwn treats as control
+// characters.
+func Escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// Link formats a mrkdwn link; the label is escaped.
+func Link(url, label string) string {
+	if url == "" {
+		return Escape(label)
+	}
+	return "<" + url + "|" + Escape(label) + ">"
+}
+
+// Actions returns an actions block holding interactive elements such as
+// buttons.
+func Actions(elements ...Block) Block {
+	return Block{"type": "actions", "elements": elements}
+}
+
+// Button returns a button element. Pressing it sends the bot a command named
+// actionID with value as its arguments.
+func Button(text, actionID, value string) Block {
+	return Block{
+		"type":      "button",
+		"text":      Block{"type": "plain_text", "text": text, "emoji": true},
+		"action_id": actionID,
+		"value":     value,
+	}
+}
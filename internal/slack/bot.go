@@ -0,0 +1,269 @@
+package slack
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRequestAge rejects signed requests older than this, so a captured
+// request cannot be replayed later.
+const maxRequestAge = 5 * time.Minute
+
+// slashReplyWait is how long a slash command may run before the bot
+// acknowledges it and posts the reply to the response URL instead.
+const slashReplyWait = 2 * time.Second
+
+// Command is a slash command ("/devpilot retry 42"), a button press in a
+// message or a message shortcut, reduced to a name and arguments. A message
+// shortcut's name is its callback ID and its argument the timestamp of the
+// thread the message is in.
+type Command struct {
+	Name      string
+	Args      []string
+	UserID    string
+	UserName  string
+	ChannelID string
+}
+
+// Reply answers a Command. Replies are visible only to the user who sent
+// the command unless InChannel is set.
+type Reply struct {
+	Text      string
+	Blocks    []Block
+	InChannel bool
+}
+
+// CommandHandler runs a bot command.
+type CommandHandler func(Command) Reply
+
+// Sign returns the X-Slack-Signature value Slack sends for body at the
+// Unix time ts. Tests and local tools use it to craft signed requests.
+func Sign(signingSecret string, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	fmt.Fprintf(mac, "v0:%d:", ts)
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks that a request body was signed by Slack with
+// signingSecret within the last few minutes of now.
+func VerifySignature(signingSecret string, header http.Header, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(header.Get("X-Slack-Request-Timestamp"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid request timestamp")
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return fmt.Errorf("request timestamp is too old")
+	}
+	want := Sign(signingSecret, ts, body)
+	if !hmac.Equal([]byte(want), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("invalid request signature")
+	}
+	return nil
+}
+
+// interactionPayload is the part of an interactive payload the bot reads.
+type interactionPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	CallbackID string `json:"callback_id"` // message shortcuts
+	Message    struct {
+		TS       string `json:"ts"`
+		ThreadTS string `json:"thread_ts"`
+	} `json:"message"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+type replyPayload struct {
+	ResponseType    string  `json:"response_type"`
+	Text            string  `json:"text,omitempty"`
+	Blocks          []Block `json:"blocks,omitempty"`
+	ReplaceOriginal bool    `json:"replace_original"`
+}
+
+// Bot serves a Slack app's slash command and interactivity request URLs.
+// Both can point at the same address.
+type Bot struct {
+	signingSecret string
+	handle        CommandHandler
+	httpClient    *http.Client
+	logger        *log.Logger
+	now           func() time.Time
+	slashWait     time.Duration // how long a slash command may take to answer inline
+}
+
+// BotOption configures a Bot.
+type BotOption func(*Bot)
+
+// WithBotHTTPClient sets the client used to answer button presses.
+func WithBotHTTPClient(hc *http.Client) BotOption {
+	return func(b *Bot) { b.httpClient = hc }
+}
+
+// WithBotLogger sets where the bot logs rejected requests and failed replies.
+func WithBotLogger(l *log.Logger) BotOption {
+	return func(b *Bot) { b.logger = l }
+}
+
+// NewBot returns a bot that verifies requests with signingSecret and runs
+// their commands with handle.
+func NewBot(signingSecret string, handle CommandHandler, opts ...BotOption) *Bot {
+	b := &Bot{
+		signingSecret: signingSecret,
+		handle:        handle,
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+		logger:        log.New(io.Discard, "", 0),
+		now:           time.Now,
+		slashWait:     slashReplyWait,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+func (b *Bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "read body failed", http.StatusBadRequest)
+		return
+	}
+	if err := VerifySignature(b.signingSecret, r.Header, body, b.now()); err != nil {
+		b.logger.Printf("Slack bot: rejected request: %v", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case form.Get("ssl_check") != "":
+		w.WriteHeader(http.StatusOK)
+	case form.Get("payload") != "":
+		b.serveInteraction(w, form.Get("payload"))
+	default:
+		b.serveSlashCommand(w, form)
+	}
+}
+
+// serveSlashCommand answers a slash command in the response body. A
+// command still running after slashWait is acknowledged instead, and its
+// reply is posted to the response URL when it is done, since Slack wants an
+// answer within three seconds.
+func (b *Bot) serveSlashCommand(w http.ResponseWriter, form url.Values) {
+	fields := strings.Fields(form.Get("text"))
+	cmd := Command{
+		Name:      "help",
+		UserID:    form.Get("user_id"),
+		UserName:  form.Get("user_name"),
+		ChannelID: form.Get("channel_id"),
+	}
+	if len(fields) > 0 {
+		cmd.Name, cmd.Args = strings.ToLower(fields[0]), fields[1:]
+	}
+	done := make(chan Reply, 1)
+	go func() { done <- b.handle(cmd) }()
+	var reply Reply
+	select {
+	case reply = <-done:
+	case <-time.After(b.slashWait):
+		responseURL := form.Get("response_url")
+		go func() { b.post(cmd, responseURL, <-done) }()
+		reply = Reply{Text: "⏳ Working on it…"}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newReplyPayload(reply)) //nolint:errcheck
+}
+
+// serveInteraction runs the command named by a button's action ID, with the
+// button's value as its arguments, and answers through the response URL.
+// Button presses and message shortcuts run in the background, since Slack
+// wants an answer within three seconds and the command may take longer.
+func (b *Bot) serveInteraction(w http.ResponseWriter, raw string) {
+	var p interactionPayload
+	if err := json.Unmarshal([]byte(raw), &p); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	cmd := Command{UserID: p.User.ID, UserName: p.User.Username, ChannelID: p.Channel.ID}
+	switch {
+	case p.Type == "block_actions" && len(p.Actions) > 0:
+		cmd.Name, cmd.Args = p.Actions[0].ActionID, strings.Fields(p.Actions[0].Value)
+		go b.run(cmd, p.ResponseURL)
+	case p.Type == "message_action" && p.CallbackID != "":
+		thread := p.Message.ThreadTS
+		if thread == "" {
+			thread = p.Message.TS
+		}
+		cmd.Name, cmd.Args = p.CallbackID, []string{thread}
+		go b.run(cmd, p.ResponseURL)
+	}
+}
+
+// run handles cmd and posts its reply to responseURL.
+func (b *Bot) run(cmd Command, responseURL string) {
+	b.post(cmd, responseURL, b.handle(cmd))
+}
+
+// post sends the reply to cmd to responseURL, logging failures.
+func (b *Bot) post(cmd Command, responseURL string, reply Reply) {
+	if responseURL == "" {
+		return
+	}
+	if err := b.respond(responseURL, reply); err != nil {
+		b.logger.Printf("Slack bot: reply to %s: %v", cmd.Name, err)
+	}
+}
+
+func (b *Bot) respond(responseURL string, reply Reply) error {
+	data, err := json.Marshal(newReplyPayload(reply))
+	if err != nil {
+		return err
+	}
+	resp, err := b.httpClient.Post(responseURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func newReplyPayload(reply Reply) replyPayload {
+	p := replyPayload{ResponseType: "ephemeral", Text: reply.Text, Blocks: reply.Blocks}
+	if reply.InChannel {
+		p.ResponseType = "in_channel"
+	}
+	return p
+}
@@ -0,0 +1,226 @@
+package slack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+// signedRequest builds a request signed the way Slack signs it.
+func signedRequest(secret string, ts time.Time, form url.Values) *http.Request {
+	body := form.Encode()
+	req := httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", strconv.FormatInt(ts.Unix(), 10))
+	req.Header.Set("X-Slack-Signature", Sign(secret, ts.Unix(), []byte(body)))
+	return req
+}
+
+func TestVerifySignature(t *testing.T) {
+	// The example from Slack's request signing documentation.
+	body := []byte("token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c")
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", "1531420618")
+	header.Set("X-Slack-Signature", "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503")
+	now := time.Unix(1531420618, 0).Add(time.Minute)
+
+	if err := VerifySignature(testSecret, header, body, now); err != nil {
+		t.Errorf("valid signature rejected: %v", err)
+	}
+	if err := VerifySignature("other-secret", header, body, now); err == nil {
+		t.Error("signature with the wrong secret accepted")
+	}
+	if err := VerifySignature(testSecret, header, body, now.Add(time.Hour)); err == nil {
+		t.Error("stale request accepted")
+	}
+}
+
+func TestBot_SlashCommand(t *testing.T) {
+	var got Command
+	bot := NewBot(testSecret, func(cmd Command) Reply {
+		got = cmd
+		return Reply{Text: "Retrying 42", InChannel: true}
+	})
+
+	rec := httptest.NewRecorder()
+	bot.ServeHTTP(rec, signedRequest(testSecret, time.Now(), url.Values{
+		"command":    {"/devpilot"},
+		"text":       {"Retry  42"},
+		"user_id":    {"U001"},
+		"user_name":  {"ada"},
+		"channel_id": {"C001"},
+	}))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	if got.Name != "retry" || len(got.Args) != 1 || got.Args[0] != "42" || got.UserID != "U001" || got.ChannelID != "C001" {
+		t.Errorf("command = %+v", got)
+	}
+	var reply replyPayload
+	json.NewDecoder(rec.Body).Decode(&reply)
+	if reply.ResponseType != "in_channel" || reply.Text != "Retrying 42" {
+		t.Errorf("reply = %+v", reply)
+	}
+}
+
+func TestBot_EmptySlashCommandIsHelp(t *testing.T) {
+	var got Command
+	bot := NewBot(testSecret, func(cmd Command) Reply { got = cmd; return Reply{Text: "usage"} })
+	rec := httptest.NewRecorder()
+	bot.ServeHTTP(rec, signedRequest(testSecret, time.Now(), url.Values{"command": {"/devpilot"}}))
+
+	if got.Name != "help" {
+		t.Errorf("command = %+v, want help", got)
+	}
+	var reply replyPayload
+	json.NewDecoder(rec.Body).Decode(&reply)
+	if reply.ResponseType != "ephemeral" {
+		t.Errorf("response type = %q, want ephemeral", reply.ResponseType)
+	}
+}
+
+func TestBot_RejectsBadSignature(t *testing.T) {
+	called := false
+	bot := NewBot(testSecret, func(Command) Reply { called = true; return Reply{} })
+	rec := httptest.NewRecorder()
+	bot.ServeHTTP(rec, signedRequest("wrong-secret", time.Now(), url.Values{"text": {"pause"}}))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+	if called {
+		t.Error("handler ran for an unsigned request")
+	}
+}
+
+func TestBot_ButtonPress(t *testing.T) {
+	replies := make(chan replyPayload, 1)
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p replyPayload
+		json.NewDecoder(r.Body).Decode(&p)
+		replies <- p
+	}))
+	defer responder.Close()
+
+	commands := make(chan Command, 1)
+	release := make(chan struct{})
+	bot := NewBot(testSecret, func(cmd Command) Reply {
+		commands <- cmd
+		<-release
+		return Reply{Text: "Approved 42"}
+	})
+	payload := `{"type":"block_actions","user":{"id":"U001","username":"ada"},"channel":{"id":"C001"},` +
+		`"response_url":"` + responder.URL + `","actions":[{"action_id":"approve","value":"42"}]}`
+	rec := httptest.NewRecorder()
+	// The handler blocks until released, so the request must be answered
+	// without waiting for it.
+	bot.ServeHTTP(rec, signedRequest(testSecret, time.Now(), url.Values{"payload": {payload}}))
+	close(release)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	select {
+	case got := <-commands:
+		if got.Name != "approve" || len(got.Args) != 1 || got.Args[0] != "42" || got.UserName != "ada" {
+			t.Errorf("command = %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("button press did not run")
+	}
+	select {
+	case reply := <-replies:
+		if reply.Text != "Approved 42" || reply.ResponseType != "ephemeral" {
+			t.Errorf("reply = %+v", reply)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("no reply posted to the response URL")
+	}
+}
+
+func TestBot_SlowSlashCommandRepliesLater(t *testing.T) {
+	replies := make(chan replyPayload, 1)
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p replyPayload
+		json.NewDecoder(r.Body).Decode(&p)
+		replies <- p
+	}))
+	defer responder.Close()
+
+	release := make(chan struct{})
+	bot := NewBot(testSecret, func(cmd Command) Reply {
+		<-release
+		return Reply{Text: "Created task", InChannel: true}
+	})
+	bot.slashWait = 10 * time.Millisecond
+
+	rec := httptest.NewRecorder()
+	bot.ServeHTTP(rec, signedRequest(testSecret, time.Now(), url.Values{
+		"command":      {"/devpilot"},
+		"text":         {"to-task 1700000000.000100"},
+		"response_url": {responder.URL},
+	}))
+	close(release)
+
+	var ack replyPayload
+	json.NewDecoder(rec.Body).Decode(&ack)
+	if rec.Code != http.StatusOK || ack.ResponseType != "ephemeral" || !strings.Contains(ack.Text, "Working on it") {
+		t.Errorf("ack = %d %+v", rec.Code, ack)
+	}
+	select {
+	case reply := <-replies:
+		if reply.Text != "Created task" || reply.ResponseType != "in_channel" {
+			t.Errorf("reply = %+v", reply)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("no reply posted to the response URL")
+	}
+}
+
+func TestBot_MessageShortcut(t *testing.T) {
+	replies := make(chan replyPayload, 1)
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p replyPayload
+		json.NewDecoder(r.Body).Decode(&p)
+		replies <- p
+	}))
+	defer responder.Close()
+
+	commands := make(chan Command, 1)
+	bot := NewBot(testSecret, func(cmd Command) Reply {
+		commands <- cmd
+		return Reply{Text: "Created task"}
+	})
+	payload := `{"type":"message_action","callback_id":"to-task","user":{"id":"U001","username":"ada"},"channel":{"id":"C001"},` +
+		`"message":{"ts":"1700000200.000100","thread_ts":"1700000000.000100"},"response_url":"` + responder.URL + `"}`
+	rec := httptest.NewRecorder()
+	bot.ServeHTTP(rec, signedRequest(testSecret, time.Now(), url.Values{"payload": {payload}}))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	select {
+	case got := <-commands:
+		if got.Name != "to-task" || len(got.Args) != 1 || got.Args[0] != "1700000000.000100" || got.ChannelID != "C001" {
+			t.Errorf("command = %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("shortcut did not run")
+	}
+	select {
+	case reply := <-replies:
+		if reply.Text != "Created task" {
+			t.Errorf("reply = %+v", reply)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("no reply posted to the response URL")
+	}
+}
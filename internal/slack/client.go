@@ -1,6 +1,7 @@
 package slack
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -57,6 +58,45 @@ type conversationsListResponse struct {
 type postMessageResponse struct {
 	OK    bool   `json:"ok"`
 	Error string `json:"error"`
+	TS    string `json:"ts"`
+}
+
+// Message is a chat.postMessage request. With Blocks set, Text is the
+// fallback shown in notifications.
+type Message struct {
+	Channel        string       `json:"channel"`
+	Text           string       `json:"text,omitempty"`
+	Blocks         []Block      `json:"blocks,omitempty"`
+	Attachments    []Attachment `json:"attachments,omitempty"`
+	ThreadTS       string       `json:"thread_ts,omitempty"`       // reply in the thread of this message
+	ReplyBroadcast bool         `json:"reply_broadcast,omitempty"` // also show a thread reply in the channel
+}
+
+// Attachment is a legacy message attachment, still the only way to give a
+// message a colored side bar.
+type Attachment struct {
+	Color     string  `json:"color,omitempty"` // "good", "warning", "danger" or a hex color
+	Fallback  string  `json:"fallback,omitempty"`
+	Pretext   string  `json:"pretext,omitempty"`
+	Title     string  `json:"title,omitempty"`
+	TitleLink string  `json:"title_link,omitempty"`
+	Text      string  `json:"text,omitempty"`
+	Footer    string  `json:"footer,omitempty"`
+	Blocks    []Block `json:"blocks,omitempty"`
+}
+
+// updateRequest is a chat.update request.
+type updateRequest struct {
+	Channel     string       `json:"channel"`
+	TS          string       `json:"ts"`
+	Text        string       `json:"text,omitempty"`
+	Blocks      []Block      `json:"blocks,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
 }
 
 type conversationsOpenResponse struct {
@@ -122,6 +162,24 @@ func (c *Client) ResolveChannel(name string) (string, error) {
 	return "", fmt.Errorf("Channel not found: %s", name)
 }
 
+// ResolveTarget turns a channel name or user ID into a conversation ID and
+// a label for output.
+func ResolveTarget(client *Client, channel string) (id, label string, err error) {
+	if strings.HasPrefix(channel, "U") && !strings.Contains(channel, " ") {
+		dmID, err := client.OpenConversation(channel)
+		if err != nil {
+			return "", "", err
+		}
+		return dmID, "as DM", nil
+	}
+	channelName := strings.TrimPrefix(channel, "#")
+	id, err = client.ResolveChannel(channelName)
+	if err != nil {
+		return "", "", err
+	}
+	return id, "to #" + channelName, nil
+}
+
 func (c *Client) OpenConversation(userID string) (string, error) {
 	params := url.Values{
 		"users": {userID},
@@ -143,7 +201,8 @@ func (c *Client) OpenConversation(userID string) (string, error) {
 	return resp.Channel.ID, nil
 }
 
-func (c *Client) PostMessage(channelID, text string) error {
+// PostMessage posts plain text and returns the message's timestamp.
+func (c *Client) PostMessage(channelID, text string) (string, error) {
 	params := url.Values{
 		"channel": {channelID},
 		"text":    {text},
@@ -151,23 +210,103 @@ func (c *Client) PostMessage(channelID, text string) error {
 
 	body, err := c.doPost("/chat.postMessage", params)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	var resp postMessageResponse
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return fmt.Errorf("parse chat.postMessage response: %w", err)
+		return "", fmt.Errorf("parse chat.postMessage response: %w", err)
 	}
 	if !resp.OK {
-		if resp.Error == "not_in_channel" || resp.Error == "channel_not_found" {
-			return fmt.Errorf("Bot is not a member of the channel. Run: /invite @devpilot in the channel.")
-		}
-		return fmt.Errorf("chat.postMessage failed: %s", resp.Error)
+		return "", postMessageError(resp.Error)
+	}
+
+	return resp.TS, nil
+}
+
+// Send posts msg and returns its timestamp, which identifies the message
+// for thread replies.
+func (c *Client) Send(msg Message) (string, error) {
+	body, err := c.doJSON("/chat.postMessage", msg)
+	if err != nil {
+		return "", err
+	}
+
+	var resp postMessageResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("parse chat.postMessage response: %w", err)
+	}
+	if !resp.OK {
+		return "", postMessageError(resp.Error)
+	}
+	return resp.TS, nil
+}
+
+// Update replaces the text, blocks and attachments of the message at ts in
+// msg.Channel. Thread fields of msg are ignored.
+func (c *Client) Update(ts string, msg Message) error {
+	body, err := c.doJSON("/chat.update", updateRequest{
+		Channel:     msg.Channel,
+		TS:          ts,
+		Text:        msg.Text,
+		Blocks:      msg.Blocks,
+		Attachments: msg.Attachments,
+	})
+	if err != nil {
+		return err
+	}
+
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("parse chat.update response: %w", err)
+	}
+	if !resp.OK {
+		return fmt.Errorf("chat.update failed: %s", resp.Error)
+	}
+	return nil
+}
+
+// AddReaction adds the emoji reaction name (with or without colons) to the
+// message at ts. Adding a reaction that is already there is not an error.
+func (c *Client) AddReaction(channelID, ts, name string) error {
+	return c.react("/reactions.add", channelID, ts, name, "already_reacted")
+}
+
+// RemoveReaction removes the bot's emoji reaction name from the message at
+// ts. Removing a reaction that is not there is not an error.
+func (c *Client) RemoveReaction(channelID, ts, name string) error {
+	return c.react("/reactions.remove", channelID, ts, name, "no_reaction")
+}
+
+func (c *Client) react(path, channelID, ts, name, harmless string) error {
+	params := url.Values{
+		"channel":   {channelID},
+		"timestamp": {ts},
+		"name":      {strings.Trim(name, ":")},
+	}
+
+	body, err := c.doPost(path, params)
+	if err != nil {
+		return err
 	}
 
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("parse %s response: %w", strings.TrimPrefix(path, "/"), err)
+	}
+	if !resp.OK && resp.Error != harmless {
+		return fmt.Errorf("%s failed: %s", strings.TrimPrefix(path, "/"), resp.Error)
+	}
 	return nil
 }
 
+func postMessageError(code string) error {
+	if code == "not_in_channel" || code == "channel_not_found" {
+		return fmt.Errorf("Bot is not a member of the channel. Run: /invite @devpilot in the channel.")
+	}
+	return fmt.Errorf("chat.postMessage failed: %s", code)
+}
+
 func (c *Client) doGet(path string, params url.Values) ([]byte, error) {
 	reqURL := c.baseURL + path
 	if len(params) > 0 {
@@ -223,3 +362,34 @@ func (c *Client) doPost(path string, params url.Values) ([]byte, error) {
 	}
 	return body, nil
 }
+
+// doJSON posts in as a JSON body, which Slack requires for blocks.
+func (c *Client) doJSON(path string, in any) ([]byte, error) {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.botToken)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read body failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, parseSlackError(body))
+	}
+	return body, nil
+}
@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/siyuqian/devpilot/internal/auth"
@@ -178,16 +182,19 @@ func TestPostMessage(t *testing.T) {
 			t.Fatalf("expected 'Hello world', got '%s'", r.PostForm.Get("text"))
 		}
 
-		resp := postMessageResponse{OK: true}
+		resp := postMessageResponse{OK: true, TS: "1700000000.000100"}
 		json.NewEncoder(w).Encode(resp)
 	}))
 	defer srv.Close()
 
 	client := NewClient("test-token", WithBaseURL(srv.URL))
-	err := client.PostMessage("C001", "Hello world")
+	ts, err := client.PostMessage("C001", "Hello world")
 	if err != nil {
 		t.Fatalf("PostMessage error: %v", err)
 	}
+	if ts != "1700000000.000100" {
+		t.Errorf("ts = %q", ts)
+	}
 }
 
 func TestPostMessageNotInChannel(t *testing.T) {
@@ -198,7 +205,7 @@ func TestPostMessageNotInChannel(t *testing.T) {
 	defer srv.Close()
 
 	client := NewClient("test-token", WithBaseURL(srv.URL))
-	err := client.PostMessage("C001", "Hello")
+	_, err := client.PostMessage("C001", "Hello")
 	if err == nil {
 		t.Fatal("expected error for not_in_channel")
 	}
@@ -212,7 +219,7 @@ func TestPostMessageError(t *testing.T) {
 	defer srv.Close()
 
 	client := NewClient("test-token", WithBaseURL(srv.URL))
-	err := client.PostMessage("C001", "Hello")
+	_, err := client.PostMessage("C001", "Hello")
 	if err == nil {
 		t.Fatal("expected error for invalid_auth")
 	}
@@ -265,3 +272,146 @@ func TestParseSlackErrorInvalidJSON(t *testing.T) {
 		t.Fatalf("expected raw body, got '%s'", got)
 	}
 }
+
+func TestSend_BlocksInThread(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.postMessage" || r.Header.Get("Content-Type") != "application/json; charset=utf-8" {
+			t.Fatalf("unexpected request: %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var msg map[string]any
+		json.NewDecoder(r.Body).Decode(&msg)
+		if msg["channel"] != "C001" || msg["thread_ts"] != "1700000000.000100" || msg["reply_broadcast"] != true {
+			t.Errorf("unexpected message: %v", msg)
+		}
+		blocks, _ := msg["blocks"].([]any)
+		if len(blocks) != 2 || blocks[0].(map[string]any)["type"] != "section" {
+			t.Errorf("unexpected blocks: %v", msg["blocks"])
+		}
+		w.Write([]byte(`{"ok":true,"ts":"1700000000.000200"}`))
+	}))
+	defer srv.Close()
+
+	ts, err := NewClient("test-token", WithBaseURL(srv.URL)).Send(Message{
+		Channel:        "C001",
+		Text:           "Done",
+		Blocks:         []Block{Section("*Done* " + Link("https://example.com/1", "a <b>")), Context("5m")},
+		ThreadTS:       "1700000000.000100",
+		ReplyBroadcast: true,
+	})
+	if err != nil {
+		t.Fatalf("Send error: %v", err)
+	}
+	if ts != "1700000000.000200" {
+		t.Errorf("ts = %q", ts)
+	}
+}
+
+func TestLinkEscapesLabel(t *testing.T) {
+	if got := Link("https://x/1", "a <b> & c"); got != "<https://x/1|a &lt;b&gt; &amp; c>" {
+		t.Errorf("Link = %q", got)
+	}
+	if got := Link("", "plain"); got != "plain" {
+		t.Errorf("Link without URL = %q", got)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.update" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		var req map[string]any
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["channel"] != "C001" || req["ts"] != "1700000000.000100" || req["text"] != "Edited" {
+			t.Errorf("unexpected request: %v", req)
+		}
+		if _, ok := req["thread_ts"]; ok {
+			t.Errorf("chat.update should not get thread_ts: %v", req)
+		}
+		attachments, _ := req["attachments"].([]any)
+		if len(attachments) != 1 || attachments[0].(map[string]any)["color"] != "good" {
+			t.Errorf("unexpected attachments: %v", req["attachments"])
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient("test-token", WithBaseURL(srv.URL))
+	err := client.Update("1700000000.000100", Message{
+		Channel:     "C001",
+		Text:        "Edited",
+		Attachments: []Attachment{{Color: "good", Text: "all green"}},
+		ThreadTS:    "1699999999.000100",
+	})
+	if err != nil {
+		t.Fatalf("Update error: %v", err)
+	}
+}
+
+func TestUpdateError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"message_not_found"}`))
+	}))
+	defer srv.Close()
+
+	err := NewClient("test-token", WithBaseURL(srv.URL)).Update("1", Message{Channel: "C001", Text: "x"})
+	if err == nil || !strings.Contains(err.Error(), "message_not_found") {
+		t.Fatalf("expected message_not_found error, got %v", err)
+	}
+}
+
+func TestAddReaction(t *testing.T) {
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reactions.add" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		r.ParseForm()
+		got = r.PostForm
+		w.Write([]byte(`{"ok":false,"error":"already_reacted"}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient("test-token", WithBaseURL(srv.URL))
+	if err := client.AddReaction("C001", "1700000000.000100", ":white_check_mark:"); err != nil {
+		t.Fatalf("AddReaction error: %v", err)
+	}
+	if got.Get("channel") != "C001" || got.Get("timestamp") != "1700000000.000100" || got.Get("name") != "white_check_mark" {
+		t.Errorf("unexpected form: %v", got)
+	}
+}
+
+func TestRemoveReactionError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"invalid_name"}`))
+	}))
+	defer srv.Close()
+
+	err := NewClient("test-token", WithBaseURL(srv.URL)).RemoveReaction("C001", "1", "nope")
+	if err == nil || !strings.Contains(err.Error(), "reactions.remove failed: invalid_name") {
+		t.Fatalf("expected invalid_name error, got %v", err)
+	}
+}
+
+func TestReadBlocksFile(t *testing.T) {
+	dir := t.TempDir()
+	array := filepath.Join(dir, "array.json")
+	os.WriteFile(array, []byte(`[{"type":"divider"},{"type":"section","text":{"type":"mrkdwn","text":"hi"}}]`), 0644)
+	blocks, attachments, err := readBlocksFile(array)
+	if err != nil || len(blocks) != 2 || attachments != nil {
+		t.Fatalf("array: blocks %v attachments %v err %v", blocks, attachments, err)
+	}
+
+	payload := filepath.Join(dir, "payload.json")
+	os.WriteFile(payload, []byte(`{"blocks":[{"type":"divider"}],"attachments":[{"color":"danger","text":"failed"}]}`), 0644)
+	blocks, attachments, err = readBlocksFile(payload)
+	if err != nil || len(blocks) != 1 || len(attachments) != 1 || attachments[0].Color != "danger" {
+		t.Fatalf("payload: blocks %v attachments %v err %v", blocks, attachments, err)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte(`"blocks"`), 0644)
+	if _, _, err := readBlocksFile(bad); err == nil {
+		t.Error("expected error for invalid blocks file")
+	}
+}
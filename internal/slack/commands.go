@@ -1,12 +1,21 @@
 package slack
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/siyuqian/devpilot/internal/generate"
+	"github.com/siyuqian/devpilot/internal/project"
+	"github.com/siyuqian/devpilot/internal/trello"
 )
 
 func RegisterCommands(parent *cobra.Command) {
@@ -17,21 +26,69 @@ func RegisterCommands(parent *cobra.Command) {
 
 	sendCmd.Flags().String("channel", "", "Channel name to send message to (required)")
 	sendCmd.Flags().String("message", "", "Message text (reads from stdin if not provided)")
+	sendCmd.Flags().String("thread", "", "Reply in the thread of the message with this timestamp")
+	sendCmd.Flags().Bool("broadcast", false, "Also show a thread reply in the channel")
+	sendCmd.Flags().String("blocks-file", "", "JSON file with Block Kit blocks (an array, or an object with blocks and attachments)")
+	sendCmd.Flags().String("file", "", "Upload this file, with the message as its comment")
+	sendCmd.Flags().String("update", "", "Edit the message with this timestamp instead of posting")
 	sendCmd.MarkFlagRequired("channel") //nolint:errcheck
 
+	reactCmd.Flags().String("channel", "", "Channel name of the message (required)")
+	reactCmd.Flags().String("ts", "", "Timestamp of the message (required)")
+	reactCmd.Flags().Bool("remove", false, "Remove the reaction instead of adding it")
+	reactCmd.MarkFlagRequired("channel") //nolint:errcheck
+	reactCmd.MarkFlagRequired("ts")      //nolint:errcheck
+
+	summaryCmd.Flags().StringSlice("channel", nil, "Channel names to summarize (repeatable, required)")
+	summaryCmd.Flags().String("since", "24h", "How far back to read, e.g. 24h or 7d")
+	summaryCmd.Flags().Int("min-messages", 1, "Skip channels with fewer messages than this")
+	summaryCmd.Flags().String("dm", "", "Send the digest as a DM to this Slack user ID")
+	summaryCmd.Flags().String("model", "", "Override Claude model")
+	summaryCmd.MarkFlagRequired("channel") //nolint:errcheck
+
+	toTaskCmd.Flags().String("source", "", "Task source: trello or github (default from .devpilot.yaml, fallback to trello)")
+	toTaskCmd.Flags().String("board", "", "Trello board name (default from .devpilot.yaml)")
+	toTaskCmd.Flags().String("list", "Inbox", "Target Trello list name; the runner only takes cards from Ready")
+	toTaskCmd.Flags().String("model", "", "Override Claude model")
+	toTaskCmd.Flags().Bool("dry-run", false, "Print the drafted plan without creating a task")
+
 	slackCmd.AddCommand(sendCmd)
+	slackCmd.AddCommand(reactCmd)
+	slackCmd.AddCommand(summaryCmd)
+	slackCmd.AddCommand(toTaskCmd)
 
 	parent.AddCommand(slackCmd)
 }
 
 func requireSlackLogin() (*Client, error) {
-	token, err := loadBotToken()
+	token, err := LoadBotToken()
 	if err != nil {
 		return nil, err
 	}
 	return NewClient(token), nil
 }
 
+// readBlocksFile reads Block Kit JSON: either a bare array of blocks or an
+// object with blocks and attachments, as the Block Kit Builder exports.
+func readBlocksFile(path string) ([]Block, []Attachment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var blocks []Block
+	if err := json.Unmarshal(data, &blocks); err == nil {
+		return blocks, nil, nil
+	}
+	var payload struct {
+		Blocks      []Block      `json:"blocks"`
+		Attachments []Attachment `json:"attachments"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return payload.Blocks, payload.Attachments, nil
+}
+
 var sendCmd = &cobra.Command{
 	Use:   "send",
 	Short: "Send a message to a Slack channel",
@@ -44,8 +101,27 @@ var sendCmd = &cobra.Command{
 
 		channel, _ := cmd.Flags().GetString("channel")
 		message, _ := cmd.Flags().GetString("message")
+		thread, _ := cmd.Flags().GetString("thread")
+		broadcast, _ := cmd.Flags().GetBool("broadcast")
+		blocksFile, _ := cmd.Flags().GetString("blocks-file")
+		file, _ := cmd.Flags().GetString("file")
+		update, _ := cmd.Flags().GetString("update")
+
+		if file != "" && (blocksFile != "" || update != "") {
+			fmt.Fprintln(os.Stderr, "Error: --file cannot be combined with --blocks-file or --update")
+			os.Exit(1)
+		}
 
-		if message == "" {
+		var blocks []Block
+		var attachments []Attachment
+		if blocksFile != "" {
+			if blocks, attachments, err = readBlocksFile(blocksFile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		if message == "" && blocksFile == "" && file == "" {
 			data, err := io.ReadAll(os.Stdin)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
@@ -54,36 +130,247 @@ var sendCmd = &cobra.Command{
 			message = strings.TrimSpace(string(data))
 		}
 
-		if message == "" {
-			fmt.Fprintln(os.Stderr, "Error: message is required (use --message or pipe via stdin)")
+		if message == "" && len(blocks) == 0 && len(attachments) == 0 && file == "" {
+			fmt.Fprintln(os.Stderr, "Error: message is required (use --message, --blocks-file, --file or pipe via stdin)")
+			os.Exit(1)
+		}
+
+		channelID, label, err := ResolveTarget(client, channel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		var channelID, label string
-		if strings.HasPrefix(channel, "U") && !strings.Contains(channel, " ") {
-			dmID, err := client.OpenConversation(channel)
+		if file != "" {
+			content, err := os.ReadFile(file)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
-			channelID = dmID
-			label = "as DM"
-		} else {
-			channelName := strings.TrimPrefix(channel, "#")
-			id, err := client.ResolveChannel(channelName)
+			uploaded, err := client.UploadFile(FileUpload{
+				Channel:        channelID,
+				ThreadTS:       thread,
+				Filename:       filepath.Base(file),
+				InitialComment: message,
+				Content:        content,
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("File %s uploaded %s.\n", uploaded.Title, label)
+			return
+		}
+
+		msg := Message{
+			Channel:        channelID,
+			Text:           message,
+			Blocks:         blocks,
+			Attachments:    attachments,
+			ThreadTS:       thread,
+			ReplyBroadcast: broadcast,
+		}
+
+		if update != "" {
+			if err := client.Update(update, msg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Message %s updated.\n", update)
+			return
+		}
+
+		ts, err := client.Send(msg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Message sent %s (ts %s).\n", label, ts)
+	},
+}
+
+var summaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize recent Slack channel activity using AI",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireSlackLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		channels, _ := cmd.Flags().GetStringSlice("channel")
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		minMessages, _ := cmd.Flags().GetInt("min-messages")
+		dm, _ := cmd.Flags().GetString("dm")
+		model, _ := cmd.Flags().GetString("model")
+
+		since, err := ParseSince(sinceFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if model == "" {
+			dir, _ := os.Getwd()
+			cfg, _ := project.Load(dir)
+			model = cfg.ModelFor("summary")
+		}
+
+		users := NewUserNames(client)
+		oldest := time.Now().Add(-since)
+		var digests []ChannelDigest
+		for _, name := range channels {
+			name = strings.TrimPrefix(name, "#")
+			id, err := client.ResolveChannel(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Reading #%s...\n", name)
+			digest, err := FetchChannelDigest(client, Channel{ID: id, Name: name}, oldest, users)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if digest.Count() < minMessages {
+				fmt.Printf("  %d message(s); skipping.\n", digest.Count())
+				continue
+			}
+			fmt.Printf("  %d message(s).\n", digest.Count())
+			digests = append(digests, digest)
+		}
+
+		if len(digests) == 0 {
+			fmt.Println("No busy channels to summarize.")
+			return
+		}
+		sort.SliceStable(digests, func(i, j int) bool { return digests[i].Count() > digests[j].Count() })
+
+		fmt.Println("Generating summary with Claude...")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		summary, err := generate.Generate(ctx, BuildSummaryPrompt(digests, since), model)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println()
+		fmt.Println(summary)
+
+		if dm != "" {
+			fmt.Printf("\nSending summary to %s...\n", dm)
+			channelID, err := client.OpenConversation(dm)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
-			channelID = id
-			label = "to #" + channelName
+			if _, err := client.Send(Message{Channel: channelID, Text: summary}); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("Summary sent.")
 		}
+	},
+}
 
-		if err := client.PostMessage(channelID, message); err != nil {
+var toTaskCmd = &cobra.Command{
+	Use:   "to-task <permalink>",
+	Short: "Turn a Slack thread into a task using AI",
+	Long:  "Read the thread a message link points to, have Claude draft a plan from it, and create a Trello card or GitHub Issue that links back to the thread.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ref, err := ParsePermalink(args[0])
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		client, err := requireSlackLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		sourceName, _ := cmd.Flags().GetString("source")
+		boardName, _ := cmd.Flags().GetString("board")
+		listName, _ := cmd.Flags().GetString("list")
+		model, _ := cmd.Flags().GetString("model")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		dir, _ := os.Getwd()
+		cfg, _ := project.Load(dir)
+		if model == "" {
+			model = cfg.ModelFor("to-task")
+		}
+		generateFn := func(ctx context.Context, prompt string) (string, error) {
+			return generate.Generate(ctx, prompt, model)
+		}
 
-		fmt.Printf("Message sent %s.\n", label)
+		fmt.Println("Drafting a plan from the thread with Claude...")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		task, err := DraftTask(ctx, client, ref, generateFn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if dryRun {
+			fmt.Println()
+			fmt.Println(task.Plan)
+			return
+		}
+
+		created, err := trello.CreateTask(cfg, trello.TaskTarget{
+			Source: cfg.ResolveSource(sourceName),
+			Board:  boardName,
+			List:   listName,
+			Dir:    dir,
+			// Like the bot's shortcut, file the task for review rather
+			// than queueing it for the runner.
+			Labels: []string{"slack"},
+		}, task.Plan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Created %s: %s\n", created.Kind, created.Title)
+		if created.URL != "" {
+			fmt.Println(created.URL)
+		}
+	},
+}
+
+var reactCmd = &cobra.Command{
+	Use:   "react <emoji>",
+	Short: "Add an emoji reaction to a Slack message",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := requireSlackLogin()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		channel, _ := cmd.Flags().GetString("channel")
+		ts, _ := cmd.Flags().GetString("ts")
+		remove, _ := cmd.Flags().GetBool("remove")
+
+		channelID, _, err := ResolveTarget(client, channel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if remove {
+			err = client.RemoveReaction(channelID, ts, args[0])
+		} else {
+			err = client.AddReaction(channelID, ts, args[0])
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	},
 }
@@ -0,0 +1,127 @@
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// FileUpload is a file to share in a channel, optionally as a thread reply.
+type FileUpload struct {
+	Channel        string
+	ThreadTS       string
+	Filename       string
+	Title          string // defaults to Filename
+	InitialComment string // message text posted with the file
+	Content        []byte
+}
+
+// File is an uploaded file.
+type File struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Permalink string `json:"permalink"`
+}
+
+type uploadURLResponse struct {
+	OK        bool   `json:"ok"`
+	Error     string `json:"error"`
+	UploadURL string `json:"upload_url"`
+	FileID    string `json:"file_id"`
+}
+
+type fileRef struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+}
+
+type completeUploadRequest struct {
+	Files          []fileRef `json:"files"`
+	ChannelID      string    `json:"channel_id,omitempty"`
+	ThreadTS       string    `json:"thread_ts,omitempty"`
+	InitialComment string    `json:"initial_comment,omitempty"`
+}
+
+type completeUploadResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	Files []File `json:"files"`
+}
+
+// UploadFile shares f the way files.uploadV2 does: it asks Slack for an
+// upload URL, sends the content there, then completes the upload into the
+// channel.
+func (c *Client) UploadFile(f FileUpload) (File, error) {
+	params := url.Values{
+		"filename": {f.Filename},
+		"length":   {strconv.Itoa(len(f.Content))},
+	}
+	body, err := c.doPost("/files.getUploadURLExternal", params)
+	if err != nil {
+		return File{}, err
+	}
+	var target uploadURLResponse
+	if err := json.Unmarshal(body, &target); err != nil {
+		return File{}, fmt.Errorf("parse files.getUploadURLExternal response: %w", err)
+	}
+	if !target.OK {
+		return File{}, fmt.Errorf("files.getUploadURLExternal failed: %s", target.Error)
+	}
+
+	if err := c.uploadContent(target.UploadURL, f.Content); err != nil {
+		return File{}, err
+	}
+
+	title := f.Title
+	if title == "" {
+		title = f.Filename
+	}
+	complete := completeUploadRequest{
+		Files:          []fileRef{{ID: target.FileID, Title: title}},
+		ChannelID:      f.Channel,
+		ThreadTS:       f.ThreadTS,
+		InitialComment: f.InitialComment,
+	}
+
+	body, err = c.doJSON("/files.completeUploadExternal", complete)
+	if err != nil {
+		return File{}, err
+	}
+	var resp completeUploadResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return File{}, fmt.Errorf("parse files.completeUploadExternal response: %w", err)
+	}
+	if !resp.OK {
+		return File{}, fmt.Errorf("files.completeUploadExternal failed: %s", resp.Error)
+	}
+	if len(resp.Files) == 0 {
+		return File{ID: target.FileID, Title: title}, nil
+	}
+	return resp.Files[0], nil
+}
+
+// uploadContent sends file content to an upload URL from
+// files.getUploadURLExternal.
+func (c *Client) uploadContent(uploadURL string, content []byte) error {
+	req, err := http.NewRequest(http.MethodPost, uploadURL, bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("upload failed: HTTP %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package slack
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUploadFile(t *testing.T) {
+	var uploaded string
+	var complete completeUploadRequest
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files.getUploadURLExternal":
+			r.ParseForm()
+			if r.PostForm.Get("filename") != "report.txt" || r.PostForm.Get("length") != "5" {
+				t.Errorf("unexpected form: %v", r.PostForm)
+			}
+			json.NewEncoder(w).Encode(uploadURLResponse{OK: true, UploadURL: srv.URL + "/upload/F001", FileID: "F001"})
+		case "/upload/F001":
+			body, _ := io.ReadAll(r.Body)
+			uploaded = string(body)
+		case "/files.completeUploadExternal":
+			json.NewDecoder(r.Body).Decode(&complete)
+			w.Write([]byte(`{"ok":true,"files":[{"id":"F001","title":"report.txt","permalink":"https://slack.com/files/F001"}]}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient("test-token", WithBaseURL(srv.URL))
+	file, err := client.UploadFile(FileUpload{
+		Channel:        "C001",
+		ThreadTS:       "1700000000.000100",
+		Filename:       "report.txt",
+		InitialComment: "Nightly report",
+		Content:        []byte("hello"),
+	})
+	if err != nil {
+		t.Fatalf("UploadFile error: %v", err)
+	}
+	if uploaded != "hello" {
+		t.Errorf("uploaded %q", uploaded)
+	}
+	if complete.ChannelID != "C001" || complete.ThreadTS != "1700000000.000100" || complete.InitialComment != "Nightly report" {
+		t.Errorf("unexpected completion: %+v", complete)
+	}
+	if len(complete.Files) != 1 || complete.Files[0].ID != "F001" || complete.Files[0].Title != "report.txt" {
+		t.Errorf("unexpected files: %+v", complete.Files)
+	}
+	if file.Permalink != "https://slack.com/files/F001" {
+		t.Errorf("file = %+v", file)
+	}
+}
+
+func TestUploadFileURLError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"missing_scope"}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewClient("test-token", WithBaseURL(srv.URL)).UploadFile(FileUpload{Channel: "C001", Filename: "a.txt"})
+	if err == nil {
+		t.Fatal("expected error for missing_scope")
+	}
+}
@@ -0,0 +1,156 @@
+package slack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChannelMessage is a message read from a channel or thread.
+type ChannelMessage struct {
+	TS         string `json:"ts"`
+	User       string `json:"user"`
+	BotID      string `json:"bot_id"`
+	Username   string `json:"username"` // set on some bot messages
+	Subtype    string `json:"subtype"`
+	Text       string `json:"text"`
+	ThreadTS   string `json:"thread_ts"`
+	ReplyCount int    `json:"reply_count"`
+}
+
+// Time returns when the message was posted.
+func (m ChannelMessage) Time() time.Time {
+	return TSTime(m.TS)
+}
+
+// HistoryOptions bounds a conversations.history or conversations.replies
+// read. Zero times are unbounded.
+type HistoryOptions struct {
+	Oldest time.Time
+	Latest time.Time
+}
+
+// User is a Slack user as returned by users.info.
+type User struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	IsBot    bool   `json:"is_bot"`
+	Profile  struct {
+		DisplayName string `json:"display_name"`
+		RealName    string `json:"real_name"`
+	} `json:"profile"`
+}
+
+// DisplayName returns the name Slack shows for the user.
+func (u User) DisplayName() string {
+	for _, name := range []string{u.Profile.DisplayName, u.Profile.RealName, u.RealName, u.Name} {
+		if name != "" {
+			return name
+		}
+	}
+	return u.ID
+}
+
+type historyResponse struct {
+	OK               bool             `json:"ok"`
+	Error            string           `json:"error"`
+	Messages         []ChannelMessage `json:"messages"`
+	HasMore          bool             `json:"has_more"`
+	ResponseMetadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+type userInfoResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	User  User   `json:"user"`
+}
+
+// TSTime converts a message timestamp such as "1700000000.000100" to a time.
+func TSTime(ts string) time.Time {
+	sec, frac, _ := strings.Cut(ts, ".")
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	var usec int64
+	if frac != "" {
+		usec, _ = strconv.ParseInt((frac + "000000")[:6], 10, 64)
+	}
+	return time.Unix(s, usec*1000)
+}
+
+// FormatTS converts a time to a message timestamp for bounding reads.
+func FormatTS(t time.Time) string {
+	return fmt.Sprintf("%d.%06d", t.Unix(), t.Nanosecond()/1000)
+}
+
+// History returns a channel's top-level messages, newest first, following
+// pagination until the range is exhausted.
+func (c *Client) History(channelID string, opts HistoryOptions) ([]ChannelMessage, error) {
+	return c.readMessages("/conversations.history", url.Values{"channel": {channelID}}, opts)
+}
+
+// Replies returns a thread's messages, starting with its parent, following
+// pagination until the range is exhausted.
+func (c *Client) Replies(channelID, threadTS string, opts HistoryOptions) ([]ChannelMessage, error) {
+	return c.readMessages("/conversations.replies", url.Values{"channel": {channelID}, "ts": {threadTS}}, opts)
+}
+
+func (c *Client) readMessages(path string, params url.Values, opts HistoryOptions) ([]ChannelMessage, error) {
+	method := strings.TrimPrefix(path, "/")
+	params.Set("limit", "200")
+	if !opts.Oldest.IsZero() {
+		params.Set("oldest", FormatTS(opts.Oldest))
+	}
+	if !opts.Latest.IsZero() {
+		params.Set("latest", FormatTS(opts.Latest))
+	}
+
+	var all []ChannelMessage
+	for {
+		body, err := c.doGet(path, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp historyResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("parse %s response: %w", method, err)
+		}
+		if !resp.OK {
+			return nil, fmt.Errorf("%s failed: %s", method, resp.Error)
+		}
+
+		all = append(all, resp.Messages...)
+
+		if resp.ResponseMetadata.NextCursor == "" {
+			break
+		}
+		params.Set("cursor", resp.ResponseMetadata.NextCursor)
+	}
+
+	return all, nil
+}
+
+// UserInfo looks up a user by ID.
+func (c *Client) UserInfo(userID string) (*User, error) {
+	body, err := c.doGet("/users.info", url.Values{"user": {userID}})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp userInfoResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parse users.info response: %w", err)
+	}
+	if !resp.OK {
+		return nil, fmt.Errorf("users.info failed: %s", resp.Error)
+	}
+	return &resp.User, nil
+}
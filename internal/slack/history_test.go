@@ -0,0 +1,100 @@
+package slack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHistoryPaginated(t *testing.T) {
+	since := time.Unix(1700000000, 0)
+	call := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call++
+		q := r.URL.Query()
+		if r.URL.Path != "/conversations.history" || q.Get("channel") != "C001" || q.Get("oldest") != "1700000000.000000" {
+			t.Fatalf("unexpected request: %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		if call == 1 {
+			w.Write([]byte(`{"ok":true,"messages":[{"ts":"1700000300.000100","user":"U1","text":"second"}],"has_more":true,"response_metadata":{"next_cursor":"c2"}}`))
+			return
+		}
+		if q.Get("cursor") != "c2" {
+			t.Fatalf("expected cursor c2, got %q", q.Get("cursor"))
+		}
+		w.Write([]byte(`{"ok":true,"messages":[{"ts":"1700000100.000100","user":"U2","text":"first","reply_count":2}]}`))
+	}))
+	defer srv.Close()
+
+	messages, err := NewClient("test-token", WithBaseURL(srv.URL)).History("C001", HistoryOptions{Oldest: since})
+	if err != nil {
+		t.Fatalf("History error: %v", err)
+	}
+	if len(messages) != 2 || messages[1].Text != "first" || messages[1].ReplyCount != 2 {
+		t.Fatalf("messages = %+v", messages)
+	}
+}
+
+func TestReplies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/conversations.replies" || q.Get("ts") != "1700000100.000100" {
+			t.Fatalf("unexpected request: %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"ok":true,"messages":[{"ts":"1700000100.000100","text":"parent"},{"ts":"1700000200.000100","thread_ts":"1700000100.000100","text":"reply"}]}`))
+	}))
+	defer srv.Close()
+
+	messages, err := NewClient("test-token", WithBaseURL(srv.URL)).Replies("C001", "1700000100.000100", HistoryOptions{})
+	if err != nil {
+		t.Fatalf("Replies error: %v", err)
+	}
+	if len(messages) != 2 || messages[1].ThreadTS != "1700000100.000100" {
+		t.Fatalf("messages = %+v", messages)
+	}
+}
+
+func TestHistoryError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"not_in_channel"}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewClient("test-token", WithBaseURL(srv.URL)).History("C001", HistoryOptions{})
+	if err == nil || err.Error() != "conversations.history failed: not_in_channel" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestUserInfo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users.info" || r.URL.Query().Get("user") != "U1" {
+			t.Fatalf("unexpected request: %s?%s", r.URL.Path, r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"ok":true,"user":{"id":"U1","name":"ada","real_name":"Ada Lovelace","profile":{"display_name":""}}}`))
+	}))
+	defer srv.Close()
+
+	user, err := NewClient("test-token", WithBaseURL(srv.URL)).UserInfo("U1")
+	if err != nil {
+		t.Fatalf("UserInfo error: %v", err)
+	}
+	if user.DisplayName() != "Ada Lovelace" {
+		t.Errorf("DisplayName = %q", user.DisplayName())
+	}
+}
+
+func TestTSTime(t *testing.T) {
+	ts := "1700000000.000100"
+	got := TSTime(ts)
+	if !got.Equal(time.Unix(1700000000, 100*1000)) {
+		t.Errorf("TSTime(%q) = %v", ts, got)
+	}
+	if FormatTS(got) != ts {
+		t.Errorf("FormatTS = %q, want %q", FormatTS(got), ts)
+	}
+	if !TSTime("bogus").IsZero() {
+		t.Error("invalid ts should give the zero time")
+	}
+}
@@ -11,10 +11,14 @@ import (
 )
 
 const (
-	slackAuthURL   = "https://slack.com/oauth/v2/authorize"
-	slackTokenURL  = "https://slack.com/api/oauth.v2.access"
-	slackScopeChat = "chat:write"
-	slackScopeRead = "channels:read"
+	slackAuthURL      = "https://slack.com/oauth/v2/authorize"
+	slackTokenURL     = "https://slack.com/api/oauth.v2.access"
+	slackScopeChat    = "chat:write"
+	slackScopeRead    = "channels:read"
+	slackScopeReact   = "reactions:write"
+	slackScopeFiles   = "files:write"
+	slackScopeHistory = "channels:history"
+	slackScopeUsers   = "users:read"
 )
 
 func init() {
@@ -56,11 +60,18 @@ func (s *SlackService) Login() error {
 		return fmt.Errorf("both Client ID and Client Secret are required")
 	}
 
+	fmt.Print("Signing Secret (optional, for slash commands): ")
+	signingSecret, _ := reader.ReadString('\n')
+	signingSecret = strings.TrimSpace(signingSecret)
+
 	// Save client credentials first so oauthConfig() can read them.
 	creds := auth.ServiceCredentials{
 		"client_id":     clientID,
 		"client_secret": clientSecret,
 	}
+	if signingSecret != "" {
+		creds["signing_secret"] = signingSecret
+	}
 	if err := auth.Save(s.Name(), creds); err != nil {
 		return fmt.Errorf("failed to save credentials: %w", err)
 	}
@@ -102,13 +113,14 @@ func (s *SlackService) oauthConfig() auth.OAuthConfig {
 		TokenURL:     slackTokenURL,
 		ClientID:     creds["client_id"],
 		ClientSecret: creds["client_secret"],
-		Scopes:       []string{slackScopeChat, slackScopeRead},
+		Scopes:       []string{slackScopeChat, slackScopeRead, slackScopeReact, slackScopeFiles, slackScopeHistory, slackScopeUsers},
 		UseTLS:       true,
 		RedirectPort: 17321,
 	}
 }
 
-func loadBotToken() (string, error) {
+// LoadBotToken returns the saved Slack bot token.
+func LoadBotToken() (string, error) {
 	creds, err := auth.Load("slack")
 	if err != nil {
 		return "", fmt.Errorf("Not logged in to Slack. Run: devpilot login slack")
@@ -120,6 +132,19 @@ func loadBotToken() (string, error) {
 	return token, nil
 }
 
+// LoadSigningSecret returns the Slack app's signing secret from the
+// SLACK_SIGNING_SECRET environment variable or the saved credentials.
+func LoadSigningSecret() (string, error) {
+	if secret := os.Getenv("SLACK_SIGNING_SECRET"); secret != "" {
+		return secret, nil
+	}
+	creds, err := auth.Load("slack")
+	if err == nil && creds["signing_secret"] != "" {
+		return creds["signing_secret"], nil
+	}
+	return "", fmt.Errorf("No Slack signing secret. Set SLACK_SIGNING_SECRET or run: devpilot login slack")
+}
+
 // parseSlackError extracts an error message from a Slack API error response.
 func parseSlackError(body []byte) string {
 	var resp struct {
@@ -0,0 +1,177 @@
+package slack
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxDigestText caps each message's text in the summary prompt.
+const maxDigestText = 600
+
+// skippedSubtypes are channel housekeeping messages a digest leaves out.
+var skippedSubtypes = map[string]bool{
+	"channel_join": true, "channel_leave": true, "channel_topic": true, "channel_purpose": true,
+	"channel_name": true, "channel_archive": true, "channel_unarchive": true, "pinned_item": true,
+}
+
+var mentionRe = regexp.MustCompile(`<@([UW][A-Z0-9]+)(?:\|[^>]*)?>`)
+
+// DigestMessage is a message prepared for a channel digest.
+type DigestMessage struct {
+	Time    time.Time
+	Author  string
+	Text    string
+	Replies []DigestMessage
+}
+
+// ChannelDigest is a channel's recent conversation, oldest message first.
+type ChannelDigest struct {
+	Name     string
+	Messages []DigestMessage
+}
+
+// Count returns the number of messages including thread replies.
+func (d ChannelDigest) Count() int {
+	n := len(d.Messages)
+	for _, m := range d.Messages {
+		n += len(m.Replies)
+	}
+	return n
+}
+
+// UserNames resolves user IDs to display names, asking Slack once per user.
+type UserNames struct {
+	client *Client
+	names  map[string]string
+}
+
+// NewUserNames returns a resolver using client.
+func NewUserNames(client *Client) *UserNames {
+	return &UserNames{client: client, names: map[string]string{}}
+}
+
+// Name returns the display name of a user, or the ID when it cannot be
+// looked up.
+func (u *UserNames) Name(id string) string {
+	if name, ok := u.names[id]; ok {
+		return name
+	}
+	name := id
+	if user, err := u.client.UserInfo(id); err == nil {
+		name = user.DisplayName()
+	}
+	u.names[id] = name
+	return name
+}
+
+// FetchChannelDigest reads a channel's messages since the given time,
+// including replies in their threads.
+func FetchChannelDigest(client *Client, ch Channel, since time.Time, users *UserNames) (ChannelDigest, error) {
+	digest := ChannelDigest{Name: ch.Name}
+	messages, err := client.History(ch.ID, HistoryOptions{Oldest: since})
+	if err != nil {
+		return digest, fmt.Errorf("read #%s: %w", ch.Name, err)
+	}
+	for _, m := range messages {
+		if skippedSubtypes[m.Subtype] {
+			continue
+		}
+		dm := digestMessage(m, users, maxDigestText)
+		if m.ReplyCount > 0 {
+			replies, err := client.Replies(ch.ID, m.TS, HistoryOptions{Oldest: since})
+			if err != nil {
+				return digest, fmt.Errorf("read thread in #%s: %w", ch.Name, err)
+			}
+			for _, r := range replies {
+				if r.TS != m.TS && !skippedSubtypes[r.Subtype] {
+					dm.Replies = append(dm.Replies, digestMessage(r, users, maxDigestText))
+				}
+			}
+			sort.Slice(dm.Replies, func(i, j int) bool { return dm.Replies[i].Time.Before(dm.Replies[j].Time) })
+		}
+		digest.Messages = append(digest.Messages, dm)
+	}
+	// History is newest first; read the conversation in order.
+	sort.Slice(digest.Messages, func(i, j int) bool { return digest.Messages[i].Time.Before(digest.Messages[j].Time) })
+	return digest, nil
+}
+
+func digestMessage(m ChannelMessage, users *UserNames, maxText int) DigestMessage {
+	author := m.Username
+	if m.User != "" {
+		author = users.Name(m.User)
+	}
+	if author == "" {
+		author = "bot"
+	}
+	text := mentionRe.ReplaceAllStringFunc(m.Text, func(mention string) string {
+		return "@" + users.Name(mentionRe.FindStringSubmatch(mention)[1])
+	})
+	return DigestMessage{Time: m.Time(), Author: author, Text: clipText(text, maxText)}
+}
+
+func clipText(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "[truncated]"
+}
+
+// BuildSummaryPrompt constructs the prompt for a digest of the given
+// channels' recent conversations.
+func BuildSummaryPrompt(digests []ChannelDigest, since time.Duration) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "You are a team assistant. Summarize the last %s of the following Slack channels into a concise digest for someone catching up.\n", formatSince(since))
+	sb.WriteString("For each channel, in order of activity:\n")
+	sb.WriteString("- KEY DISCUSSIONS: what was discussed, one line each\n")
+	sb.WriteString("- DECISIONS: anything agreed or settled\n")
+	sb.WriteString("- OPEN QUESTIONS / ACTION ITEMS: who needs to do or answer what\n\n")
+	sb.WriteString("Skip small talk. Keep each line to ~100 chars. Format for Slack: *bold* channel headings and - bullets, no tables.\n\n")
+
+	for _, d := range digests {
+		fmt.Fprintf(&sb, "--- #%s (%d messages) ---\n\n", d.Name, d.Count())
+		for _, m := range d.Messages {
+			fmt.Fprintf(&sb, "[%s] %s: %s\n", m.Time.Format("Mon 15:04"), m.Author, m.Text)
+			for _, r := range m.Replies {
+				fmt.Fprintf(&sb, "    ↳ [%s] %s: %s\n", r.Time.Format("Mon 15:04"), r.Author, r.Text)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// ParseSince parses a look-back period such as "24h", "90m" or "7d".
+func ParseSince(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid period %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid period %q (use e.g. 24h or 7d)", s)
+	}
+	return d, nil
+}
+
+func formatSince(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		if days := int(d / (24 * time.Hour)); days > 1 {
+			return fmt.Sprintf("%d days", days)
+		}
+		return "24 hours"
+	}
+	return d.String()
+}
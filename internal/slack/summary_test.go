@@ -0,0 +1,83 @@
+package slack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchChannelDigest(t *testing.T) {
+	userCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conversations.history":
+			w.Write([]byte(`{"ok":true,"messages":[
+				{"ts":"1700000300.000100","user":"U2","text":"Shipping it <@U1|ada>"},
+				{"ts":"1700000200.000100","user":"U1","subtype":"channel_join","text":"joined"},
+				{"ts":"1700000100.000100","user":"U1","text":"Release tonight?","reply_count":1}]}`))
+		case "/conversations.replies":
+			w.Write([]byte(`{"ok":true,"messages":[
+				{"ts":"1700000100.000100","user":"U1","text":"Release tonight?"},
+				{"ts":"1700000150.000100","user":"U2","text":"Yes"}]}`))
+		case "/users.info":
+			userCalls++
+			names := map[string]string{"U1": "ada", "U2": "grace"}
+			w.Write([]byte(`{"ok":true,"user":{"id":"x","name":"` + names[r.URL.Query().Get("user")] + `"}}`))
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient("test-token", WithBaseURL(srv.URL))
+	digest, err := FetchChannelDigest(client, Channel{ID: "C001", Name: "release"}, time.Unix(1700000000, 0), NewUserNames(client))
+	if err != nil {
+		t.Fatalf("FetchChannelDigest error: %v", err)
+	}
+	if len(digest.Messages) != 2 || digest.Count() != 3 {
+		t.Fatalf("digest = %+v", digest)
+	}
+	first := digest.Messages[0]
+	if first.Author != "ada" || first.Text != "Release tonight?" || len(first.Replies) != 1 || first.Replies[0].Author != "grace" {
+		t.Errorf("first message = %+v", first)
+	}
+	if digest.Messages[1].Text != "Shipping it @ada" {
+		t.Errorf("mention not resolved: %q", digest.Messages[1].Text)
+	}
+	if userCalls != 2 {
+		t.Errorf("users.info called %d times, want once per user", userCalls)
+	}
+}
+
+func TestBuildSummaryPrompt(t *testing.T) {
+	at := time.Date(2026, 3, 2, 9, 30, 0, 0, time.Local)
+	prompt := BuildSummaryPrompt([]ChannelDigest{{
+		Name: "release",
+		Messages: []DigestMessage{{
+			Time: at, Author: "ada", Text: "Release tonight?",
+			Replies: []DigestMessage{{Time: at.Add(time.Minute), Author: "grace", Text: "Yes"}},
+		}},
+	}}, 7*24*time.Hour)
+
+	for _, want := range []string{"last 7 days", "--- #release (2 messages) ---", "[Mon 09:30] ada: Release tonight?", "↳ [Mon 09:31] grace: Yes"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	tests := map[string]time.Duration{"24h": 24 * time.Hour, "90m": 90 * time.Minute, "7d": 7 * 24 * time.Hour}
+	for in, want := range tests {
+		if got, err := ParseSince(in); err != nil || got != want {
+			t.Errorf("ParseSince(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "0d", "-1h", "soon"} {
+		if _, err := ParseSince(bad); err == nil {
+			t.Errorf("ParseSince(%q) should fail", bad)
+		}
+	}
+}
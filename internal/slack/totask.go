@@ -0,0 +1,166 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/siyuqian/devpilot/internal/trello"
+)
+
+// maxThreadText caps each message's text in the task drafting prompt. It
+// is higher than a digest's because the thread is the whole task.
+const maxThreadText = 4000
+
+var permalinkRe = regexp.MustCompile(`^/archives/([CGD][A-Z0-9]+)/p(\d{10})(\d{6})$`)
+
+// ThreadRef identifies a thread by its channel and parent message.
+type ThreadRef struct {
+	ChannelID string
+	TS        string
+}
+
+// ParsePermalink reads a message link as copied from Slack, e.g.
+// https://acme.slack.com/archives/C0123ABCD/p1700000000000100. A link to
+// a reply refers to the thread it is in.
+func ParsePermalink(link string) (ThreadRef, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || !strings.HasSuffix(u.Host, "slack.com") {
+		return ThreadRef{}, fmt.Errorf("not a Slack message link: %q", link)
+	}
+	m := permalinkRe.FindStringSubmatch(u.Path)
+	if m == nil {
+		return ThreadRef{}, fmt.Errorf("not a Slack message link: %q", link)
+	}
+	ref := ThreadRef{ChannelID: m[1], TS: m[2] + "." + m[3]}
+	if parent := u.Query().Get("thread_ts"); parent != "" {
+		ref.TS = parent
+	}
+	return ref, nil
+}
+
+type permalinkResponse struct {
+	OK        bool   `json:"ok"`
+	Error     string `json:"error"`
+	Permalink string `json:"permalink"`
+}
+
+// Permalink returns the link to a message.
+func (c *Client) Permalink(channelID, ts string) (string, error) {
+	body, err := c.doGet("/chat.getPermalink", url.Values{"channel": {channelID}, "message_ts": {ts}})
+	if err != nil {
+		return "", err
+	}
+	var resp permalinkResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("parse chat.getPermalink response: %w", err)
+	}
+	if !resp.OK {
+		return "", fmt.Errorf("chat.getPermalink failed: %s", resp.Error)
+	}
+	return resp.Permalink, nil
+}
+
+// FetchThread reads a thread's messages, parent first.
+func FetchThread(client *Client, ref ThreadRef, users *UserNames) ([]DigestMessage, error) {
+	messages, err := client.Replies(ref.ChannelID, ref.TS, HistoryOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("read thread: %w", err)
+	}
+	var thread []DigestMessage
+	for _, m := range messages {
+		if !skippedSubtypes[m.Subtype] {
+			thread = append(thread, digestMessage(m, users, maxThreadText))
+		}
+	}
+	return thread, nil
+}
+
+// BuildTaskPrompt constructs the prompt that turns a thread into a task
+// plan in the format devpilot push and the runner expect.
+func BuildTaskPrompt(thread []DigestMessage) string {
+	var sb strings.Builder
+	sb.WriteString("You are turning a Slack discussion into a task plan for an autonomous coding agent that will execute it without asking questions.\n\n")
+	sb.WriteString("Write the plan in markdown:\n")
+	sb.WriteString("- Start with \"# \" followed by a short imperative task title.\n")
+	sb.WriteString("- A \"## Context\" section summarizing the problem and what the thread agreed on.\n")
+	sb.WriteString("- A \"## Steps\" section of numbered steps.\n")
+	sb.WriteString("- An \"## Acceptance Criteria\" section with checks that prove the task is done.\n\n")
+	sb.WriteString("Only include requirements the thread states or clearly implies. List anything left undecided under \"## Open Questions\".\n")
+	sb.WriteString("Output ONLY the plan, with no preamble.\n\n")
+	sb.WriteString("<thread>\n")
+	for _, m := range thread {
+		fmt.Fprintf(&sb, "[%s] %s: %s\n", m.Time.Format("2006-01-02 15:04"), m.Author, m.Text)
+	}
+	sb.WriteString("</thread>\n")
+	return sb.String()
+}
+
+// TaskFiler files a plan as a task and returns the task's URL.
+type TaskFiler func(title, plan string) (string, error)
+
+// ThreadTask is a task filed from a thread.
+type ThreadTask struct {
+	Title     string
+	Plan      string
+	URL       string
+	Permalink string // the thread the task came from
+}
+
+// DraftTask reads a thread and has generate draft a plan from it. The plan
+// ends with a link back to the thread.
+func DraftTask(ctx context.Context, client *Client, ref ThreadRef, generate func(context.Context, string) (string, error)) (*ThreadTask, error) {
+	thread, err := FetchThread(client, ref, NewUserNames(client))
+	if err != nil {
+		return nil, err
+	}
+	if len(thread) == 0 {
+		return nil, fmt.Errorf("the thread has no messages")
+	}
+	permalink, err := client.Permalink(ref.ChannelID, ref.TS)
+	if err != nil {
+		return nil, err
+	}
+	draft, err := generate(ctx, BuildTaskPrompt(thread))
+	if err != nil {
+		return nil, fmt.Errorf("draft plan: %w", err)
+	}
+	draft = stripFence(draft)
+	title := trello.ExtractTitle(draft)
+	if title == "" {
+		return nil, fmt.Errorf("the drafted plan has no # title heading")
+	}
+	return &ThreadTask{
+		Title:     title,
+		Plan:      draft + "\n\n---\n\nFrom Slack thread: " + permalink + "\n",
+		Permalink: permalink,
+	}, nil
+}
+
+// ThreadToTask drafts a plan from a thread and files it with file.
+func ThreadToTask(ctx context.Context, client *Client, ref ThreadRef, generate func(context.Context, string) (string, error), file TaskFiler) (*ThreadTask, error) {
+	task, err := DraftTask(ctx, client, ref, generate)
+	if err != nil {
+		return nil, err
+	}
+	if task.URL, err = file(task.Title, task.Plan); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// stripFence removes a ``` fence Claude sometimes wraps its whole answer in.
+func stripFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") {
+		return s
+	}
+	_, body, ok := strings.Cut(s, "\n")
+	if !ok {
+		return s
+	}
+	return strings.TrimSpace(strings.TrimSuffix(body, "```"))
+}
@@ -0,0 +1,107 @@
+package slack
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParsePermalink(t *testing.T) {
+	tests := []struct {
+		link    string
+		want    ThreadRef
+		wantErr bool
+	}{
+		{link: "https://acme.slack.com/archives/C0123ABCD/p1700000000000100", want: ThreadRef{ChannelID: "C0123ABCD", TS: "1700000000.000100"}},
+		{link: "https://acme.slack.com/archives/C0123ABCD/p1700000500000200?thread_ts=1700000000.000100&cid=C0123ABCD", want: ThreadRef{ChannelID: "C0123ABCD", TS: "1700000000.000100"}},
+		{link: "https://acme.slack.com/archives/C0123ABCD", wantErr: true},
+		{link: "https://example.com/archives/C0123ABCD/p1700000000000100", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePermalink(tt.link)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePermalink(%q) error = %v, wantErr %v", tt.link, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePermalink(%q) = %+v, want %+v", tt.link, got, tt.want)
+		}
+	}
+}
+
+// threadServer serves a two-message thread, its authors and its permalink.
+func threadServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conversations.replies":
+			w.Write([]byte(`{"ok":true,"messages":[` +
+				`{"ts":"1700000000.000100","user":"U1","text":"Login breaks when the session expires"},` +
+				`{"ts":"1700000100.000100","user":"U2","subtype":"channel_join","text":"joined"},` +
+				`{"ts":"1700000200.000100","user":"U2","text":"<@U1> let's redirect to /login instead"}]}`))
+		case "/users.info":
+			name := map[string]string{"U1": "ada", "U2": "grace"}[r.URL.Query().Get("user")]
+			w.Write([]byte(`{"ok":true,"user":{"name":"` + name + `"}}`))
+		case "/chat.getPermalink":
+			w.Write([]byte(`{"ok":true,"permalink":"https://acme.slack.com/archives/C001/p1700000000000100"}`))
+		default:
+			t.Fatalf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestThreadToTask(t *testing.T) {
+	srv := threadServer(t)
+	defer srv.Close()
+
+	var prompt string
+	generate := func(_ context.Context, p string) (string, error) {
+		prompt = p
+		return "```markdown\n# Redirect expired sessions to login\n\n## Steps\n1. Do it\n```", nil
+	}
+	var filedTitle, filedPlan string
+	file := func(title, plan string) (string, error) {
+		filedTitle, filedPlan = title, plan
+		return "https://trello.com/c/abc", nil
+	}
+
+	task, err := ThreadToTask(context.Background(), NewClient("test-token", WithBaseURL(srv.URL)),
+		ThreadRef{ChannelID: "C001", TS: "1700000000.000100"}, generate, file)
+	if err != nil {
+		t.Fatalf("ThreadToTask error: %v", err)
+	}
+
+	if !strings.Contains(prompt, "ada: Login breaks") || !strings.Contains(prompt, "grace: @ada let's redirect") {
+		t.Errorf("prompt is missing the thread:\n%s", prompt)
+	}
+	if strings.Contains(prompt, "joined") {
+		t.Error("prompt includes a channel_join message")
+	}
+	if filedTitle != "Redirect expired sessions to login" || task.Title != filedTitle {
+		t.Errorf("title = %q", filedTitle)
+	}
+	if !strings.HasPrefix(filedPlan, "# Redirect") || !strings.HasSuffix(filedPlan, "From Slack thread: https://acme.slack.com/archives/C001/p1700000000000100\n") {
+		t.Errorf("plan = %q", filedPlan)
+	}
+	if task.URL != "https://trello.com/c/abc" {
+		t.Errorf("URL = %q", task.URL)
+	}
+}
+
+func TestThreadToTask_NoTitle(t *testing.T) {
+	srv := threadServer(t)
+	defer srv.Close()
+
+	generate := func(context.Context, string) (string, error) { return "Sure! Here is a plan.", nil }
+	file := func(string, string) (string, error) {
+		t.Fatal("filed a plan without a title")
+		return "", nil
+	}
+	_, err := ThreadToTask(context.Background(), NewClient("test-token", WithBaseURL(srv.URL)),
+		ThreadRef{ChannelID: "C001", TS: "1700000000.000100"}, generate, file)
+	if err == nil || !strings.Contains(err.Error(), "# title") {
+		t.Errorf("error = %v, want a missing title error", err)
+	}
+}
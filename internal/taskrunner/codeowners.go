@@ -0,0 +1,103 @@
+package taskrunner
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// codeOwnersPaths are the locations GitHub reads CODEOWNERS from, in order.
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+type codeOwnersRule struct {
+	pattern string
+	owners  []string
+}
+
+// loadCodeOwners parses the first CODEOWNERS file found in dir. It returns
+// nil when the repository has none.
+func loadCodeOwners(dir string) []codeOwnersRule {
+	for _, p := range codeOwnersPaths {
+		data, err := os.ReadFile(filepath.Join(dir, p))
+		if err == nil {
+			return parseCodeOwners(string(data))
+		}
+	}
+	return nil
+}
+
+func parseCodeOwners(content string) []codeOwnersRule {
+	var rules []codeOwnersRule
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		var owners []string
+		for _, o := range fields[1:] {
+			// Email owners cannot be requested as reviewers through gh.
+			if login, ok := strings.CutPrefix(o, "@"); ok {
+				owners = append(owners, login)
+			}
+		}
+		rules = append(rules, codeOwnersRule{pattern: fields[0], owners: owners})
+	}
+	return rules
+}
+
+// codeOwnersFor returns the owners of files. As in GitHub, the last matching
+// rule for a file wins.
+func codeOwnersFor(rules []codeOwnersRule, files []string) []string {
+	var owners []string
+	for _, f := range files {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if matchCodeOwnersPattern(rules[i].pattern, f) {
+				for _, o := range rules[i].owners {
+					owners = addUnique(owners, o)
+				}
+				break
+			}
+		}
+	}
+	return owners
+}
+
+// matchCodeOwnersPattern reports whether a CODEOWNERS pattern matches file
+// (a slash-separated path relative to the repository root). It supports the
+// common gitignore-style forms: "*", anchored "/path", directory "dir/",
+// and globs within a path segment.
+func matchCodeOwnersPattern(pattern, file string) bool {
+	if pattern == "*" {
+		return true
+	}
+	anchored := strings.HasPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/**")
+	if !anchored && !strings.Contains(pattern, "/") {
+		// Unanchored name: match any path component.
+		parts := strings.Split(file, "/")
+		for i, part := range parts {
+			if ok, _ := path.Match(pattern, part); ok && (!dirOnly || i < len(parts)-1) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Path pattern: match the file itself or any of its parent directories.
+	for p := file; p != "." && p != ""; p = path.Dir(p) {
+		if ok, _ := path.Match(pattern, p); ok && (!dirOnly || p != file) {
+			return true
+		}
+		if !strings.Contains(p, "/") {
+			break
+		}
+	}
+	return false
+}
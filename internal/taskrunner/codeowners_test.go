@@ -0,0 +1,57 @@
+package taskrunner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchCodeOwnersPattern(t *testing.T) {
+	cases := []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"*", "main.go", true},
+		{"*.go", "internal/x/y.go", true},
+		{"*.go", "README.md", false},
+		{"/docs/", "docs/a.md", true},
+		{"/docs/", "sub/docs/a.md", false},
+		{"docs/", "sub/docs/a.md", true},
+		{"/internal/slack/**", "internal/slack/client.go", true},
+		{"internal/slack", "internal/slack/client.go", true},
+		{"internal/slack", "internal/slackbot/x.go", false},
+		{"Makefile", "build/Makefile", true},
+	}
+	for _, c := range cases {
+		if got := matchCodeOwnersPattern(c.pattern, c.file); got != c.want {
+			t.Errorf("match(%q, %q) = %v, want %v", c.pattern, c.file, got, c.want)
+		}
+	}
+}
+
+func TestCodeOwnersFor_LastMatchWins(t *testing.T) {
+	rules := parseCodeOwners(`# owners
+*            @org/core
+/docs/       @alice  # docs team
+*.md         @bob
+internal/gmail/ @carol dave@example.com
+`)
+	got := codeOwnersFor(rules, []string{"docs/guide.md", "internal/gmail/client.go", "main.go"})
+	want := []string{"bob", "carol", "org/core"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("owners = %v, want %v", got, want)
+	}
+}
+
+func TestLoadCodeOwners(t *testing.T) {
+	dir := t.TempDir()
+	if rules := loadCodeOwners(dir); rules != nil {
+		t.Errorf("expected no rules without a CODEOWNERS file, got %v", rules)
+	}
+	os.MkdirAll(filepath.Join(dir, ".github"), 0o755)
+	os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte("* @team\n"), 0o644)
+	if rules := loadCodeOwners(dir); len(rules) != 1 {
+		t.Errorf("expected 1 rule from .github/CODEOWNERS, got %v", rules)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"regexp"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -13,20 +14,34 @@ import (
 	"golang.org/x/term"
 
 	"github.com/siyuqian/devpilot/internal/auth"
+	"github.com/siyuqian/devpilot/internal/generate"
+	"github.com/siyuqian/devpilot/internal/github"
 	"github.com/siyuqian/devpilot/internal/openspec"
 	"github.com/siyuqian/devpilot/internal/project"
+	"github.com/siyuqian/devpilot/internal/slack"
 	"github.com/siyuqian/devpilot/internal/trello"
 )
 
 func RegisterCommands(parent *cobra.Command) {
 	runCmd.Flags().String("board", "", "Trello board name (required for trello source)")
-	runCmd.Flags().String("source", "", "Task source: trello or github (default from .devpilot.yaml, fallback to trello)")
+	runCmd.Flags().String("source", "", "Task source: trello, github or github-project (default from .devpilot.yaml, fallback to trello)")
 	runCmd.Flags().Int("interval", 300, "Poll interval in seconds")
 	runCmd.Flags().Int("timeout", 30, "Per-task timeout in minutes")
 	runCmd.Flags().Int("review-timeout", 10, "Code review timeout in minutes (0 to disable)")
 	runCmd.Flags().Bool("once", false, "Process one card and exit")
+	runCmd.Flags().Bool("drain", false, "Process every ready card, then exit (default from .devpilot.yaml)")
+	runCmd.Flags().Bool("ignore-schedule", false, "Start tasks now, ignoring the schedule in .devpilot.yaml")
+	runCmd.Flags().String("slack-listen", "", "Serve the /devpilot Slack command on this address, e.g. :3000")
 	runCmd.Flags().Bool("dry-run", false, "Print actions without executing")
 	runCmd.Flags().Bool("no-tui", false, "Disable TUI, use plain text output")
+	runCmd.Flags().String("base", "", "Base branch for task branches and PRs (default from .devpilot.yaml, fallback to main/master)")
+	runCmd.Flags().String("remote", "", "Git remote to push task branches to (default from .devpilot.yaml, fallback to origin)")
+	runCmd.Flags().String("repo", "", "Repository (owner/repo) to open PRs against (default from .devpilot.yaml)")
+	runCmd.Flags().Bool("stacked", false, "Split OpenSpec changes into stacked PRs, one per task group in tasks.md")
+	runCmd.Flags().String("branch-policy", "", "What to do with a task branch left by an earlier run: reset or rebase (default from .devpilot.yaml, fallback to reset)")
+	runCmd.Flags().String("plan-gate", "", "Check plans before running them: lint (fail bad plans) or refine (rewrite them with Claude) (default from .devpilot.yaml, fallback to off)")
+	runCmd.Flags().String("runner-id", "", "Name this runner records when claiming tasks (default from .devpilot.yaml, fallback to <hostname>-<pid>)")
+	runCmd.Flags().StringSlice("selector", nil, "Only take tasks carrying all of these labels, e.g. runner:gpu-free (default from .devpilot.yaml)")
 	parent.AddCommand(runCmd)
 }
 
@@ -41,8 +56,19 @@ var runCmd = &cobra.Command{
 		timeout, _ := cmd.Flags().GetInt("timeout")
 		reviewTimeout, _ := cmd.Flags().GetInt("review-timeout")
 		once, _ := cmd.Flags().GetBool("once")
+		drain, _ := cmd.Flags().GetBool("drain")
+		ignoreSchedule, _ := cmd.Flags().GetBool("ignore-schedule")
+		slackListen, _ := cmd.Flags().GetString("slack-listen")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		noTUI, _ := cmd.Flags().GetBool("no-tui")
+		baseBranch, _ := cmd.Flags().GetString("base")
+		remote, _ := cmd.Flags().GetString("remote")
+		prRepo, _ := cmd.Flags().GetString("repo")
+		stacked, _ := cmd.Flags().GetBool("stacked")
+		branchPolicy, _ := cmd.Flags().GetString("branch-policy")
+		planGate, _ := cmd.Flags().GetString("plan-gate")
+		runnerID, _ := cmd.Flags().GetString("runner-id")
+		selector, _ := cmd.Flags().GetStringSlice("selector")
 
 		dir, err := os.Getwd()
 		if err != nil {
@@ -60,6 +86,88 @@ var runCmd = &cobra.Command{
 
 		sourceName = projectCfg.ResolveSource(sourceName)
 
+		if baseBranch == "" {
+			baseBranch = projectCfg.BaseBranch
+		}
+		if remote == "" {
+			remote = projectCfg.Remote
+		}
+		if prRepo == "" {
+			prRepo = projectCfg.PRRepo
+		}
+		if !cmd.Flags().Changed("stacked") {
+			stacked = projectCfg.StackedPRs
+		}
+		if branchPolicy == "" {
+			branchPolicy = projectCfg.BranchPolicy
+		}
+		switch branchPolicy {
+		case "", BranchPolicyReset, BranchPolicyRebase:
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown branch policy %q (want %s or %s)\n", branchPolicy, BranchPolicyReset, BranchPolicyRebase)
+			os.Exit(1)
+		}
+
+		if planGate == "" {
+			planGate = projectCfg.PlanGate
+		}
+		switch planGate {
+		case "", PlanGateLint, PlanGateRefine:
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown plan gate %q (want %s or %s)\n", planGate, PlanGateLint, PlanGateRefine)
+			os.Exit(1)
+		}
+		var schedule *Schedule
+		if !ignoreSchedule {
+			if schedule, err = NewSchedule(projectCfg.Schedule); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if !cmd.Flags().Changed("drain") {
+			drain = projectCfg.Schedule.Drain
+		}
+		switch projectCfg.Review.Policy {
+		case "", PanelPolicyAll, PanelPolicyMajority:
+		default:
+			fmt.Fprintf(os.Stderr, "Error: unknown review policy %q (want %s or %s)\n", projectCfg.Review.Policy, PanelPolicyAll, PanelPolicyMajority)
+			os.Exit(1)
+		}
+		if runnerID == "" {
+			runnerID = projectCfg.Runner.ID
+		}
+		if runnerID == "" {
+			runnerID = DefaultRunnerID()
+		}
+		if !cmd.Flags().Changed("selector") {
+			selector = projectCfg.Runner.Selector
+		}
+
+		// PRs go through the GitHub API whichever source tasks come from,
+		// but only the GitHub sources need a token up front: a Trello
+		// runner without one fails a task when it first opens a PR.
+		ghHost := projectCfg.GitHub.Host
+		var ghClient *github.Client
+		token, tokenErr := github.LoadToken(ghHost)
+		if tokenErr == nil {
+			ghClient = github.NewClient(token, github.WithHost(ghHost))
+		} else if sourceName != "trello" {
+			fmt.Fprintln(os.Stderr, tokenErr)
+			os.Exit(1)
+		} else if !dryRun {
+			fmt.Fprintf(os.Stderr, "Warning: %v\nTasks will fail when they open a PR.\n", tokenErr)
+		}
+
+		// A configured repo list makes this a multi-repo runner: each task
+		// runs in a workspace checkout of its own repository.
+		var workspace string
+		if len(projectCfg.GitHub.Repos) > 0 {
+			workspace = projectCfg.GitHub.Workspace
+			if workspace == "" {
+				workspace = DefaultWorkspaceRoot()
+			}
+		}
+
 		var source TaskSource
 		switch sourceName {
 		case "trello":
@@ -75,9 +183,20 @@ var runCmd = &cobra.Command{
 			trelloClient := trello.NewClient(creds["api_key"], creds["token"])
 			source = NewTrelloSource(trelloClient, boardName)
 		case "github":
-			source = NewGitHubSource()
+			repos := projectCfg.GitHub.Repos
+			if len(repos) == 0 {
+				repo, err := github.ResolveRepo(dir, prRepo, remote)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "Error: cannot determine the GitHub repository (set prRepo or github.repos in .devpilot.yaml, or pass --repo):", err)
+					os.Exit(1)
+				}
+				repos = []string{repo}
+			}
+			source = NewGitHubSource(ghClient, repos...)
+		case "github-project":
+			source = NewGitHubProjectSource(ghClient, projectCfg.GitHub.Project)
 		default:
-			fmt.Fprintf(os.Stderr, "Unknown source %q. Must be trello or github.\n", sourceName)
+			fmt.Fprintf(os.Stderr, "Unknown source %q. Must be trello, github or github-project.\n", sourceName)
 			os.Exit(1)
 		}
 
@@ -89,27 +208,119 @@ var runCmd = &cobra.Command{
 		}
 
 		cfg := Config{
-			BoardName:     boardName,
-			Interval:      time.Duration(interval) * time.Second,
-			Timeout:       time.Duration(timeout) * time.Minute,
-			ReviewTimeout: time.Duration(reviewTimeout) * time.Minute,
-			Once:          once,
-			DryRun:        dryRun,
-			WorkDir:       dir,
-			UseOpenSpec:   useOpenSpec,
+			BoardName:       boardName,
+			Interval:        time.Duration(interval) * time.Second,
+			Timeout:         time.Duration(timeout) * time.Minute,
+			ReviewTimeout:   time.Duration(reviewTimeout) * time.Minute,
+			Once:            once,
+			DryRun:          dryRun,
+			WorkDir:         dir,
+			UseOpenSpec:     useOpenSpec,
+			BaseBranch:      baseBranch,
+			Remote:          remote,
+			PRRepo:          prRepo,
+			StackedPRs:      stacked,
+			PRTemplate:      projectCfg.PRTemplate,
+			PRRules:         projectCfg.PR,
+			BranchPolicy:    branchPolicy,
+			Workspace:       workspace,
+			GitHubHost:      ghHost,
+			RunnerID:        runnerID,
+			Selector:        selector,
+			LeaseTTL:        projectCfg.Runner.LeaseTTL,
+			PlanGate:        planGate,
+			Context:         projectCfg.Context,
+			ReviewPanel:     projectCfg.Review,
+			Escalation:      projectCfg.Escalation,
+			Schedule:        schedule,
+			Drain:           drain,
+			RequireApproval: projectCfg.Runner.Approval,
+			Project:         projectCfg,
+		}
+
+		prModel := projectCfg.ModelFor("pr")
+		summarizer := func(ctx context.Context, prompt string) (string, error) {
+			return generate.Generate(ctx, prompt, prModel)
 		}
 
 		isInteractive := term.IsTerminal(int(os.Stdout.Fd()))
 
-		if isInteractive && !noTUI {
-			runWithTUI(cfg, source, boardName)
+		refineModel := projectCfg.ModelFor("refine")
+		refiner := func(ctx context.Context, prompt string) (string, error) {
+			return generate.Generate(ctx, prompt, refineModel)
+		}
+
+		opts := []RunnerOption{WithSummarizer(summarizer), WithGitHubClient(ghClient), WithPlanRefiner(refiner)}
+		useTUI := isInteractive && !noTUI
+		var notifier *SlackNotifier
+		if projectCfg.Notify.Slack.Channel != "" {
+			notifier, err = newSlackNotifier(projectCfg.Notify.Slack)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			if !useTUI {
+				notifier.logf = log.New(os.Stderr, "", log.LstdFlags).Printf
+			}
+			opts = append(opts, WithNotifier(notifier.Notify))
+		}
+		if slackListen == "" {
+			slackListen = projectCfg.Runner.SlackListen
+		}
+		if slackListen != "" {
+			secret, err := slack.LoadSigningSecret()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			// The to-task shortcut reads threads with the bot token; without
+			// one the bot still serves slash commands and buttons.
+			var botClient *slack.Client
+			if token, err := slack.LoadBotToken(); err == nil {
+				botClient = slack.NewClient(token)
+			}
+			toTaskModel := projectCfg.ModelFor("to-task")
+			draft := func(ctx context.Context, prompt string) (string, error) {
+				return generate.Generate(ctx, prompt, toTaskModel)
+			}
+			opts = append(opts, WithSlackBot(slackListen, secret, botClient, draft))
+		}
+		if useTUI {
+			err = runWithTUI(cfg, source, boardName, opts...)
 		} else {
-			runPlainText(cfg, source)
+			err = runPlainText(cfg, source, opts...)
+		}
+		if notifier != nil {
+			notifier.Close() // post what is still queued
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Runner error:", err)
+			os.Exit(1)
 		}
 	},
 }
 
-func runWithTUI(cfg Config, source TaskSource, boardName string) {
+// slackChannelIDRe matches a Slack conversation ID, which needs no lookup.
+var slackChannelIDRe = regexp.MustCompile(`^[CGD][A-Z0-9]{6,}$`)
+
+// newSlackNotifier connects the notify.slack configuration to Slack.
+func newSlackNotifier(cfg project.SlackNotify) (*SlackNotifier, error) {
+	token, err := slack.LoadBotToken()
+	if err != nil {
+		return nil, err
+	}
+	client := slack.NewClient(token)
+	channelID := cfg.Channel
+	if !slackChannelIDRe.MatchString(channelID) {
+		if channelID, err = client.ResolveChannel(channelID); err != nil {
+			return nil, fmt.Errorf("notify.slack.channel: %w", err)
+		}
+	}
+	return NewSlackNotifier(client, channelID, cfg.Events)
+}
+
+// runWithTUI runs the runner behind the terminal UI and returns its error.
+func runWithTUI(cfg Config, source TaskSource, boardName string, opts ...RunnerOption) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -118,7 +329,7 @@ func runWithTUI(cfg Config, source TaskSource, boardName string) {
 		eventCh <- e
 	}
 
-	r := New(cfg, source, WithEventHandler(handler))
+	r := New(cfg, source, append(opts, WithEventHandler(handler))...)
 	model := NewTUIModel(boardName, eventCh, cancel)
 
 	p := tea.NewProgram(model, tea.WithAltScreen())
@@ -136,17 +347,19 @@ func runWithTUI(cfg Config, source TaskSource, boardName string) {
 		fmt.Fprintln(os.Stderr, "TUI error:", err)
 		os.Exit(1)
 	}
-
-	if runErr != nil {
-		fmt.Fprintln(os.Stderr, "Runner error:", runErr)
-		os.Exit(1)
-	}
+	return runErr
 }
 
-func runPlainText(cfg Config, source TaskSource) {
+// runPlainText runs the runner logging events to stdout and returns its error.
+func runPlainText(cfg Config, source TaskSource, opts ...RunnerOption) error {
 	logger := log.New(os.Stdout, "", log.LstdFlags)
 
+	var inputTokens, outputTokens int // for the current task
 	handler := func(e Event) {
+		if ev, ok := e.(StatsUpdateEvent); ok {
+			inputTokens += ev.InputTokens
+			outputTokens += ev.OutputTokens
+		}
 		switch ev := e.(type) {
 		case RunnerStartedEvent:
 			logger.Printf("Board: %s (%s)", ev.BoardName, ev.BoardID)
@@ -157,7 +370,14 @@ func runPlainText(cfg Config, source TaskSource) {
 			logger.Printf("Polling for tasks...")
 		case NoTasksEvent:
 			logger.Printf("No tasks. Next poll in %s", ev.NextPoll)
+		case ScheduleWaitEvent:
+			logger.Printf("[schedule] Waiting for %s at %s", ev.Reason, ev.Until.Format("Mon 15:04"))
+		case PausedEvent:
+			logger.Printf("Paused. Waiting to be resumed...")
+		case ApprovalNeededEvent:
+			logger.Printf("[approval] %q waits for approval (id %s)", ev.CardName, ev.CardID)
 		case CardStartedEvent:
+			inputTokens, outputTokens = 0, 0
 			logger.Printf("[card] Started: %q on branch %s", ev.CardName, ev.Branch)
 		case ToolStartEvent:
 			summary := toolSummary(ev.ToolName, ev.Input)
@@ -168,20 +388,30 @@ func runPlainText(cfg Config, source TaskSource) {
 			logger.Printf("[text] %s", truncate(ev.Text, 120))
 		case StatsUpdateEvent:
 			if ev.Turns > 0 {
-				logger.Printf("[stats] ↑%s ↓%s turns:%d", formatTokens(ev.InputTokens), formatTokens(ev.OutputTokens), ev.Turns)
+				logger.Printf("[stats] ↑%s ↓%s turns:%d", formatTokens(inputTokens), formatTokens(outputTokens), ev.Turns)
 			}
 		case CardDoneEvent:
 			logger.Printf("[card] Done: %q (%s) PR: %s", ev.CardName, ev.Duration, ev.PRURL)
 		case CardFailedEvent:
 			logger.Printf("[card] Failed: %q — %s", ev.CardName, ev.ErrMsg)
 		case ReviewStartedEvent:
-			logger.Printf("[review] Starting code review for %s", ev.PRURL)
+			if ev.Reviewer != "" {
+				logger.Printf("[review] Starting %s review for %s", ev.Reviewer, ev.PRURL)
+			} else {
+				logger.Printf("[review] Starting code review for %s", ev.PRURL)
+			}
 		case ReviewDoneEvent:
-			logger.Printf("[review] Done (exit %d)", ev.ExitCode)
+			if ev.Reviewer != "" {
+				logger.Printf("[review] %s done (exit %d, approved %t)", ev.Reviewer, ev.ExitCode, ev.Approved)
+			} else {
+				logger.Printf("[review] Done (exit %d)", ev.ExitCode)
+			}
 		case FixStartedEvent:
 			logger.Printf("[fix] Attempting fix for %s (attempt %d)", ev.PRURL, ev.Attempt)
 		case FixDoneEvent:
 			logger.Printf("[fix] Fix done (attempt %d, exit %d)", ev.Attempt, ev.ExitCode)
+		case EscalatedEvent:
+			logger.Printf("[escalate] %q: %s on %s, retrying on %s", ev.CardName, ev.Reason, modelName(ev.From), ev.To)
 		case RunnerStoppedEvent:
 			logger.Printf("Runner stopped.")
 		case RunnerErrorEvent:
@@ -191,7 +421,7 @@ func runPlainText(cfg Config, source TaskSource) {
 		}
 	}
 
-	r := New(cfg, source, WithEventHandler(handler))
+	r := New(cfg, source, append(opts, WithEventHandler(handler))...)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -203,8 +433,5 @@ func runPlainText(cfg Config, source TaskSource) {
 		cancel()
 	}()
 
-	if err := r.Run(ctx); err != nil {
-		fmt.Fprintln(os.Stderr, "Runner error:", err)
-		os.Exit(1)
-	}
+	return r.Run(ctx)
 }
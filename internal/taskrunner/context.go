@@ -0,0 +1,234 @@
+package taskrunner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+const (
+	defaultMaxContextBytes    = 16000
+	defaultMaxAttachmentBytes = 10 << 20
+	maxCommentBytes           = 2000 // per comment or linked issue body
+	attachmentsDir            = ".devpilot/attachments"
+)
+
+// TaskContext is material gathered around a task's plan: discussion,
+// checklists, attachments and linked issues.
+type TaskContext struct {
+	Comments    []TaskComment
+	Checklists  []Checklist
+	Attachments []Attachment
+	Linked      []LinkedIssue
+}
+
+type TaskComment struct {
+	Author    string
+	Body      string
+	CreatedAt time.Time
+}
+
+type Checklist struct {
+	Name  string
+	Items []ChecklistItem
+}
+
+type ChecklistItem struct {
+	Text string
+	Done bool
+}
+
+// Attachment is a file or link attached to a task. Path is set once the
+// file has been downloaded into the checkout.
+type Attachment struct {
+	Name string
+	URL  string
+	Size int64 // bytes; 0 when unknown
+	Path string
+
+	// open fetches the file; nil for links that are not downloadable.
+	open func() (io.ReadCloser, error)
+}
+
+// LinkedIssue is an issue referenced from a task.
+type LinkedIssue struct {
+	Ref   string // e.g. "owner/repo#12"
+	Title string
+	State string
+	Body  string
+}
+
+// ContextGatherer is implemented by task sources that can collect extra
+// context for a task beyond its plan.
+type ContextGatherer interface {
+	GatherContext(task Task, want project.ContextConfig) (*TaskContext, error)
+}
+
+// gatherContext collects the configured context for task and downloads its
+// attachments into git's checkout. Failures are logged and never fail the
+// task: the plan alone is still worth running.
+func (r *Runner) gatherContext(task *Task, git *GitOps) {
+	want := r.config.Context
+	g, ok := r.source.(ContextGatherer)
+	if !ok || !want.Enabled() {
+		return
+	}
+	c, err := g.GatherContext(*task, want)
+	if err != nil {
+		r.logger.Printf("Failed to gather context for %q: %v", task.Name, err)
+		return
+	}
+	if want.Attachments && len(c.Attachments) > 0 {
+		r.downloadAttachments(task.ID, git, c.Attachments)
+	}
+	task.Context = c
+}
+
+func (r *Runner) downloadAttachments(id string, git *GitOps, attachments []Attachment) {
+	limit := r.config.Context.MaxAttachmentBytes
+	if limit <= 0 {
+		limit = defaultMaxAttachmentBytes
+	}
+	dir := filepath.Join(git.dir, attachmentsDir, safeID(id))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		r.logger.Printf("Failed to create attachments directory: %v", err)
+		return
+	}
+	if err := git.Exclude("/" + attachmentsDir + "/"); err != nil {
+		r.logger.Printf("Failed to exclude attachments from git: %v", err)
+	}
+	used := map[string]bool{}
+	for i := range attachments {
+		a := &attachments[i]
+		if a.open == nil || (a.Size > 0 && a.Size > limit) {
+			continue
+		}
+		path := filepath.Join(dir, uniqueFileName(safeFileName(a.Name, i), used))
+		if err := download(a.open, path, limit); err != nil {
+			r.logger.Printf("Failed to download attachment %q: %v", a.Name, err)
+			continue
+		}
+		a.Path = path
+	}
+}
+
+// download copies at most limit bytes from open into path, removing the
+// file if the attachment turns out to be larger.
+func download(open func() (io.ReadCloser, error), path string, limit int64) error {
+	body, err := open()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(body, limit+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > limit {
+		err = fmt.Errorf("larger than %d bytes", limit)
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func safeFileName(name string, i int) string {
+	name = strings.Trim(unsafeFileChars.ReplaceAllString(filepath.Base(name), "-"), "-.")
+	if name == "" {
+		name = fmt.Sprintf("attachment-%d", i+1)
+	}
+	return name
+}
+
+// uniqueFileName returns name, or name with "-2", "-3", ... before its
+// extension if used already has it, and records the result in used. Names
+// are compared case-insensitively for case-insensitive file systems.
+func uniqueFileName(name string, used map[string]bool) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	candidate := name
+	for n := 2; used[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// formatContext renders c for the execution prompt, truncated to maxBytes.
+func formatContext(c *TaskContext, dir string, maxBytes int) string {
+	if c == nil {
+		return ""
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxContextBytes
+	}
+	var b strings.Builder
+	for _, cl := range c.Checklists {
+		fmt.Fprintf(&b, "\nChecklist: %s\n", cl.Name)
+		for _, item := range cl.Items {
+			mark := " "
+			if item.Done {
+				mark = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] %s\n", mark, item.Text)
+		}
+	}
+	if len(c.Comments) > 0 {
+		b.WriteString("\nComments (oldest first):\n")
+		for _, cm := range c.Comments {
+			fmt.Fprintf(&b, "\n%s wrote:\n%s\n", cm.Author, clip(cm.Body, maxCommentBytes))
+		}
+	}
+	if len(c.Linked) > 0 {
+		b.WriteString("\nLinked issues:\n")
+		for _, l := range c.Linked {
+			fmt.Fprintf(&b, "\n%s: %s (%s)\n%s\n", l.Ref, l.Title, l.State, clip(l.Body, maxCommentBytes))
+		}
+	}
+	if len(c.Attachments) > 0 {
+		b.WriteString("\nAttachments:\n")
+		for _, a := range c.Attachments {
+			if a.Path != "" {
+				rel, err := filepath.Rel(dir, a.Path)
+				if err != nil {
+					rel = a.Path
+				}
+				fmt.Fprintf(&b, "- %s (downloaded to %s; read it if relevant, do not commit it)\n", a.Name, rel)
+			} else {
+				fmt.Fprintf(&b, "- %s: %s\n", a.Name, a.URL)
+			}
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "Additional context from the task:\n" + clip(b.String(), maxBytes)
+}
+
+// clip shortens s to at most n bytes on a rune boundary, marking the cut.
+func clip(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	const marker = "\n…(truncated)"
+	cut := n - len(marker)
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:max(cut, 0)] + marker
+}
@@ -0,0 +1,146 @@
+package taskrunner
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+// contextSource gathers a fixed context.
+type contextSource struct {
+	planSource
+	ctx *TaskContext
+}
+
+func (s *contextSource) GatherContext(Task, project.ContextConfig) (*TaskContext, error) {
+	return s.ctx, nil
+}
+
+func opener(content string) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(content)), nil }
+}
+
+func TestGatherContext_DownloadsAttachments(t *testing.T) {
+	dir := setupGitRepo(t)
+	src := &contextSource{ctx: &TaskContext{
+		Comments: []TaskComment{{Author: "alice", Body: "Use the v2 endpoint."}},
+		Attachments: []Attachment{
+			{Name: "spec v1.pdf", URL: "https://x/spec", open: opener("spec")},
+			{Name: "huge.bin", URL: "https://x/huge", open: opener(strings.Repeat("x", 100))},
+			{Name: "Design doc", URL: "https://docs.example.com/d"},
+			{Name: "broken", URL: "https://x/broken", open: func() (io.ReadCloser, error) { return nil, errors.New("404") }},
+		},
+	}}
+	r := &Runner{
+		config: Config{WorkDir: dir, Context: project.ContextConfig{Comments: true, Attachments: true, MaxAttachmentBytes: 10}},
+		source: src,
+		git:    NewGitOps(dir),
+		logger: log.New(io.Discard, "", 0),
+	}
+	task := Task{ID: "owner/repo#7", Name: "T"}
+	r.gatherContext(&task, r.git)
+	if task.Context == nil {
+		t.Fatal("context not set")
+	}
+
+	got := task.Context.Attachments
+	want := filepath.Join(dir, ".devpilot", "attachments", "owner-repo-7", "spec-v1.pdf")
+	if got[0].Path != want {
+		t.Errorf("attachment path = %q, want %q", got[0].Path, want)
+	}
+	if data, _ := os.ReadFile(want); string(data) != "spec" {
+		t.Errorf("attachment content = %q", data)
+	}
+	for _, a := range got[1:] {
+		if a.Path != "" {
+			t.Errorf("%s should not be downloaded", a.Name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(want), "huge.bin")); !os.IsNotExist(err) {
+		t.Error("oversized attachment left on disk")
+	}
+
+	// Downloaded attachments must not show up as changes to commit.
+	if clean, err := r.git.IsClean(); err != nil || !clean {
+		t.Errorf("attachments dirtied the checkout: clean=%v err=%v", clean, err)
+	}
+
+	prompt := r.buildPrompt(Task{Name: "T", Description: "plan", Context: task.Context}, nil, "", false)
+	for _, s := range []string{"alice wrote:\nUse the v2 endpoint.", "spec v1.pdf (downloaded to .devpilot/attachments/owner-repo-7/spec-v1.pdf", "Design doc: https://docs.example.com/d"} {
+		if !strings.Contains(prompt, s) {
+			t.Errorf("prompt missing %q:\n%s", s, prompt)
+		}
+	}
+}
+
+func TestGatherContext_KeepsAttachmentsWithTheSameName(t *testing.T) {
+	dir := setupGitRepo(t)
+	src := &contextSource{ctx: &TaskContext{
+		Attachments: []Attachment{
+			{Name: "screenshot.png", open: opener("first")},
+			{Name: "screenshot.png", open: opener("second")},
+			{Name: "Screenshot.png", open: opener("third")},
+		},
+	}}
+	r := &Runner{
+		config: Config{WorkDir: dir, Context: project.ContextConfig{Attachments: true}},
+		source: src,
+		git:    NewGitOps(dir),
+		logger: log.New(io.Discard, "", 0),
+	}
+	task := Task{ID: "7", Name: "T"}
+	r.gatherContext(&task, r.git)
+
+	attachDir := filepath.Join(dir, ".devpilot", "attachments", "7")
+	for i, want := range []struct{ file, content string }{
+		{"screenshot.png", "first"},
+		{"screenshot-2.png", "second"},
+		{"Screenshot-3.png", "third"},
+	} {
+		path := filepath.Join(attachDir, want.file)
+		if got := task.Context.Attachments[i].Path; got != path {
+			t.Errorf("attachment %d path = %q, want %q", i, got, path)
+		}
+		if data, _ := os.ReadFile(path); string(data) != want.content {
+			t.Errorf("%s content = %q, want %q", want.file, data, want.content)
+		}
+	}
+}
+
+func TestGatherContext_Disabled(t *testing.T) {
+	src := &contextSource{ctx: &TaskContext{Comments: []TaskComment{{Body: "x"}}}}
+	r := &Runner{source: src, logger: log.New(io.Discard, "", 0)}
+	task := Task{ID: "1"}
+	r.gatherContext(&task, nil)
+	if task.Context != nil {
+		t.Error("context gathered although nothing is enabled")
+	}
+}
+
+func TestFormatContext(t *testing.T) {
+	c := &TaskContext{
+		Checklists: []Checklist{{Name: "QA", Items: []ChecklistItem{{Text: "mobile", Done: true}, {Text: "desktop"}}}},
+		Linked:     []LinkedIssue{{Ref: "o/r#3", Title: "Old bug", State: "closed", Body: "repro"}},
+	}
+	got := formatContext(c, "/w", 0)
+	for _, s := range []string{"Checklist: QA\n- [x] mobile\n- [ ] desktop", "o/r#3: Old bug (closed)\nrepro"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("missing %q in:\n%s", s, got)
+		}
+	}
+
+	long := &TaskContext{Comments: []TaskComment{{Author: "a", Body: strings.Repeat("é", 5000)}}}
+	got = formatContext(long, "/w", 500)
+	if len(got) > 600 || !strings.HasSuffix(got, "(truncated)") {
+		t.Errorf("context not clipped: %d bytes", len(got))
+	}
+	if formatContext(&TaskContext{}, "/w", 0) != "" || formatContext(nil, "/w", 0) != "" {
+		t.Error("empty context should render nothing")
+	}
+}
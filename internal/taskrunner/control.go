@@ -0,0 +1,231 @@
+package taskrunner
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Requeuer is implemented by task sources that can put a failed task back
+// in the ready queue.
+type Requeuer interface {
+	Requeue(id string) error
+}
+
+// RunnerStatus is a snapshot of what a runner is doing.
+type RunnerStatus struct {
+	Paused   bool
+	Current  *Task     // the task being worked on, if any
+	Since    time.Time // when Current started
+	Done     int
+	Failed   int
+	Awaiting []Task // tasks waiting for approval
+}
+
+// control holds the state operators change while the runner works, e.g.
+// from Slack. Its zero value is ready to use and all of it is safe for
+// concurrent use.
+type control struct {
+	mu       sync.Mutex
+	paused   bool
+	resumed  chan struct{} // closed when a pause ends
+	wake     chan struct{} // cuts an idle sleep short
+	approved map[string]bool
+	awaiting map[string]Task // announced as waiting for approval
+	current  *Task
+	since    time.Time
+	done     int
+	failed   int
+}
+
+// observe follows the runner's events to know the current task and counts.
+func (c *control) observe(e Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch ev := e.(type) {
+	case CardStartedEvent:
+		c.current = &Task{ID: ev.CardID, Name: ev.CardName, URL: ev.URL}
+		c.since = time.Now()
+	case CardDoneEvent:
+		c.done++
+		c.finish(ev.CardID)
+	case CardFailedEvent:
+		c.failed++
+		c.finish(ev.CardID)
+	}
+}
+
+func (c *control) finish(id string) {
+	c.current = nil
+	delete(c.approved, id)
+}
+
+func (c *control) wakeChan() chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.wake == nil {
+		c.wake = make(chan struct{}, 1)
+	}
+	return c.wake
+}
+
+// nudge wakes the runner if it is idling between polls.
+func (c *control) nudge() {
+	select {
+	case c.wakeChan() <- struct{}{}:
+	default:
+	}
+}
+
+// Status reports what the runner is doing.
+func (r *Runner) Status() RunnerStatus {
+	c := &r.control
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := RunnerStatus{Paused: c.paused, Since: c.since, Done: c.done, Failed: c.failed}
+	if c.current != nil {
+		cur := *c.current
+		s.Current = &cur
+	}
+	for _, t := range c.awaiting {
+		s.Awaiting = append(s.Awaiting, t)
+	}
+	slices.SortFunc(s.Awaiting, func(a, b Task) int { return cmp.Compare(a.ID, b.ID) })
+	return s
+}
+
+// Queue returns the ready tasks this runner would take, in the order it
+// would take them.
+func (r *Runner) Queue() ([]Task, error) {
+	tasks, err := r.source.FetchReady()
+	if err != nil {
+		return nil, err
+	}
+	var mine []Task
+	for _, t := range tasks {
+		if matchesSelector(t, r.config.Selector) {
+			mine = append(mine, t)
+		}
+	}
+	SortByPriority(mine)
+	return mine, nil
+}
+
+// Pause stops the runner from starting new tasks; the current one finishes.
+func (r *Runner) Pause() {
+	c := &r.control
+	c.mu.Lock()
+	if !c.paused {
+		c.paused = true
+		c.resumed = make(chan struct{})
+	}
+	c.mu.Unlock()
+	c.nudge()
+}
+
+// Resume lets a paused runner start tasks again.
+func (r *Runner) Resume() {
+	c := &r.control
+	c.mu.Lock()
+	if c.paused {
+		c.paused = false
+		close(c.resumed)
+	}
+	c.mu.Unlock()
+	c.nudge()
+}
+
+// Approve lets the task with the given ID start on a runner that requires
+// approval.
+func (r *Runner) Approve(id string) error {
+	if !r.config.RequireApproval {
+		return fmt.Errorf("this runner does not require approval")
+	}
+	c := &r.control
+	c.mu.Lock()
+	if c.approved == nil {
+		c.approved = map[string]bool{}
+	}
+	c.approved[id] = true
+	delete(c.awaiting, id)
+	c.mu.Unlock()
+	c.nudge()
+	return nil
+}
+
+// Retry puts a failed task back in the ready queue.
+func (r *Runner) Retry(id string) error {
+	requeuer, ok := r.source.(Requeuer)
+	if !ok {
+		return fmt.Errorf("this task source cannot requeue tasks")
+	}
+	if err := requeuer.Requeue(id); err != nil {
+		return err
+	}
+	r.control.nudge()
+	return nil
+}
+
+// approvedToStart reports whether task may start, announcing a task that
+// waits for approval the first time it is seen.
+func (r *Runner) approvedToStart(task Task) bool {
+	if !r.config.RequireApproval {
+		return true
+	}
+	c := &r.control
+	c.mu.Lock()
+	if c.approved[task.ID] {
+		c.mu.Unlock()
+		return true
+	}
+	_, announced := c.awaiting[task.ID]
+	if !announced {
+		if c.awaiting == nil {
+			c.awaiting = map[string]Task{}
+		}
+		c.awaiting[task.ID] = task
+	}
+	c.mu.Unlock()
+	if !announced {
+		r.logger.Printf("Task %q waits for approval", task.Name)
+		r.emit(ApprovalNeededEvent{CardID: task.ID, CardName: task.Name, URL: task.URL})
+	}
+	return false
+}
+
+// awaitResume blocks while the runner is paused. It returns false when ctx
+// is done.
+func (r *Runner) awaitResume(ctx context.Context) bool {
+	c := &r.control
+	c.mu.Lock()
+	paused, resumed := c.paused, c.resumed
+	c.mu.Unlock()
+	if !paused {
+		return true
+	}
+	r.logger.Println("Paused. Waiting to be resumed...")
+	r.emit(PausedEvent{})
+	select {
+	case <-ctx.Done():
+		return false
+	case <-resumed:
+		r.logger.Println("Resumed.")
+		return true
+	}
+}
+
+// idle sleeps between polls like sleep, but wakes early when an operator
+// approves, retries or resumes something.
+func (r *Runner) idle(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	case <-r.control.wakeChan():
+		return true
+	}
+}
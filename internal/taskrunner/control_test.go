@@ -0,0 +1,145 @@
+package taskrunner
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+// requeueSource records requeued task IDs.
+type requeueSource struct {
+	planSource
+	requeued []string
+}
+
+func (s *requeueSource) Requeue(id string) error {
+	s.requeued = append(s.requeued, id)
+	return nil
+}
+
+func TestRun_PausedRunnerDoesNotPoll(t *testing.T) {
+	dir := setupGitRepo(t)
+	src := &queueSource{batches: [][]Task{{{ID: "1", Name: "one", Description: "plan"}}}}
+	paused := make(chan struct{}, 1)
+	r := &Runner{
+		config: Config{DryRun: true, Drain: true, Interval: time.Hour, WorkDir: dir},
+		source: src,
+		git:    NewGitOps(dir),
+		logger: log.New(io.Discard, "", 0),
+		eventHandler: func(e Event) {
+			if _, ok := e.(PausedEvent); ok {
+				paused <- struct{}{}
+			}
+		},
+	}
+	r.Pause()
+
+	done := make(chan error, 1)
+	go func() { done <- r.Run(context.Background()) }()
+	select {
+	case <-paused:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runner did not report the pause")
+	}
+	if src.polls != 0 {
+		t.Fatalf("paused runner polled %d times", src.polls)
+	}
+	if !r.Status().Paused {
+		t.Error("Status should report the pause")
+	}
+
+	r.Resume()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("resumed runner did not drain the queue")
+	}
+	if src.polls != 2 {
+		t.Errorf("polled %d times, want 2 (one batch, then empty)", src.polls)
+	}
+}
+
+func TestClaimNext_RequiresApproval(t *testing.T) {
+	var announced []string
+	r := &Runner{
+		config: Config{RequireApproval: true},
+		source: &planSource{},
+		logger: log.New(io.Discard, "", 0),
+		eventHandler: func(e Event) {
+			if ev, ok := e.(ApprovalNeededEvent); ok {
+				announced = append(announced, ev.CardID)
+			}
+		},
+	}
+	tasks := []Task{{ID: "1", Name: "one"}, {ID: "2", Name: "two"}}
+
+	for range 2 {
+		if _, ok := r.claimNext(tasks); ok {
+			t.Fatal("claimed a task nobody approved")
+		}
+	}
+	if len(announced) != 2 {
+		t.Errorf("announced %v, want each task once", announced)
+	}
+	if got := r.Status().Awaiting; len(got) != 2 {
+		t.Errorf("awaiting %v, want both tasks", got)
+	}
+
+	if err := r.Approve("2"); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	task, ok := r.claimNext(tasks)
+	if !ok || task.ID != "2" {
+		t.Fatalf("claimNext = %q, %v; want the approved task", task.ID, ok)
+	}
+	if got := r.Status().Awaiting; len(got) != 1 || got[0].ID != "1" {
+		t.Errorf("awaiting %v, want only task 1", got)
+	}
+
+	r.emit(CardDoneEvent{CardID: "2"})
+	if _, ok := r.claimNext(tasks[1:]); ok {
+		t.Error("an approval should cover one run of the task")
+	}
+}
+
+func TestApprove_NotRequired(t *testing.T) {
+	r := &Runner{}
+	if err := r.Approve("1"); err == nil {
+		t.Error("expected error when the runner does not require approval")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	src := &requeueSource{}
+	r := &Runner{source: src}
+	if err := r.Retry("42"); err != nil {
+		t.Fatalf("Retry: %v", err)
+	}
+	if len(src.requeued) != 1 || src.requeued[0] != "42" {
+		t.Errorf("requeued %v", src.requeued)
+	}
+
+	if err := (&Runner{source: &planSource{}}).Retry("42"); err == nil {
+		t.Error("expected error for a source that cannot requeue")
+	}
+}
+
+func TestStatus_TracksCurrentTask(t *testing.T) {
+	r := &Runner{}
+	r.emit(CardStartedEvent{CardID: "1", CardName: "one", URL: "https://example.com/1"})
+	if s := r.Status(); s.Current == nil || s.Current.ID != "1" {
+		t.Fatalf("Current = %+v", s.Current)
+	}
+	r.emit(CardFailedEvent{CardID: "1"})
+	r.emit(CardStartedEvent{CardID: "2"})
+	r.emit(CardDoneEvent{CardID: "2"})
+	s := r.Status()
+	if s.Current != nil || s.Done != 1 || s.Failed != 1 {
+		t.Errorf("status = %+v, want idle with one done and one failed", s)
+	}
+}
@@ -0,0 +1,6 @@
+package taskrunner
+
+import "embed"
+
+//go:embed templates
+var templatesFS embed.FS
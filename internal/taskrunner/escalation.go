@@ -0,0 +1,157 @@
+package taskrunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Failures that move a task up the model ladder.
+const (
+	EscalateNoCommits = "no-commits" // Claude finished without committing anything
+	EscalateExecution = "execution"  // Claude exited with an error or timed out
+	EscalateReview    = "review"     // the code review kept rejecting the PR
+)
+
+// Attempt outcomes besides the escalation reasons.
+const (
+	outcomeDone  = "done"
+	outcomeError = "error" // failed for a reason a stronger model would not fix, e.g. a push
+)
+
+// attemptsFile collects every attempt's record, relative to the log directory.
+const attemptsFile = "attempts.jsonl"
+
+// attemptResult is how one attempt at a task ended. Reason is empty on
+// success.
+type attemptResult struct {
+	PRURL  string
+	Reason string // an Escalate* reason or outcomeError
+	Err    string
+}
+
+// Attempt records one run of a task on one model, for tuning the ladder.
+type Attempt struct {
+	Time         time.Time `json:"time"`
+	TaskID       string    `json:"task"`
+	TaskName     string    `json:"name"`
+	Tier         int       `json:"tier"`
+	Model        string    `json:"model,omitempty"` // empty means the CLI default
+	Outcome      string    `json:"outcome"`
+	Error        string    `json:"error,omitempty"`
+	InputTokens  int       `json:"inputTokens"`
+	OutputTokens int       `json:"outputTokens"`
+	Turns        int       `json:"turns"`
+	Seconds      int       `json:"seconds"`
+}
+
+// reviewRejectedError reports a code review that never approved.
+type reviewRejectedError struct{ attempts int }
+
+func (e reviewRejectedError) Error() string {
+	return fmt.Sprintf("code review failed after %d attempts", e.attempts)
+}
+
+// ladder returns the models to try in order; a single empty entry runs the
+// CLI default model once.
+func (r *Runner) ladder() []string {
+	if len(r.config.Escalation.Ladder) == 0 {
+		return []string{""}
+	}
+	return r.config.Escalation.Ladder
+}
+
+// escalates reports whether a failure for reason moves up the ladder.
+func (r *Runner) escalates(reason string) bool {
+	if reason == outcomeError {
+		return false
+	}
+	on := r.config.Escalation.On
+	return len(on) == 0 || slices.Contains(on, reason)
+}
+
+// climbLadder runs attempt on each model of the ladder until one succeeds or
+// fails for a reason that does not escalate, recording every attempt. It
+// returns the last attempt's result and the models tried.
+func (r *Runner) climbLadder(task Task, attempt func(tier int, model string) attemptResult) (attemptResult, []string) {
+	ladder := r.ladder()
+	var tried []string
+	for tier := 0; ; tier++ {
+		model := ladder[tier]
+		start := time.Now()
+		r.stats = taskStats{}
+		res := attempt(tier, model)
+		tried = append(tried, model)
+
+		outcome := res.Reason
+		if outcome == "" {
+			outcome = outcomeDone
+		}
+		r.recordAttempt(Attempt{
+			Time:         start,
+			TaskID:       task.ID,
+			TaskName:     task.Name,
+			Tier:         tier,
+			Model:        model,
+			Outcome:      outcome,
+			Error:        res.Err,
+			InputTokens:  r.stats.inputTokens,
+			OutputTokens: r.stats.outputTokens,
+			Turns:        r.stats.turns,
+			Seconds:      int(time.Since(start).Seconds()),
+		})
+
+		if res.Reason == "" || tier == len(ladder)-1 || !r.escalates(res.Reason) {
+			return res, tried
+		}
+		next := ladder[tier+1]
+		r.logger.Printf("Attempt on %s failed (%s: %s); escalating to %s", modelName(model), res.Reason, res.Err, modelName(next))
+		r.emit(EscalatedEvent{CardID: task.ID, CardName: task.Name, From: model, To: next, Reason: res.Reason})
+	}
+}
+
+// recordAttempt appends a to the attempts log; failures are only logged.
+func (r *Runner) recordAttempt(a Attempt) {
+	logDir := filepath.Join(r.projectDir, ".devpilot", "logs")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		r.logger.Printf("Failed to create log directory: %v", err)
+		return
+	}
+	line, err := json.Marshal(a)
+	if err != nil {
+		r.logger.Printf("Failed to encode attempt: %v", err)
+		return
+	}
+	f, err := os.OpenFile(filepath.Join(logDir, attemptsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		r.logger.Printf("Failed to record attempt: %v", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		r.logger.Printf("Failed to record attempt: %v", err)
+	}
+}
+
+func modelName(model string) string {
+	if model == "" {
+		return "the default model"
+	}
+	return model
+}
+
+// modelTrail describes the models a task ran on, e.g. "sonnet → opus".
+func modelTrail(models []string) string {
+	names := make([]string, len(models))
+	for i, m := range models {
+		names[i] = m
+		if m == "" {
+			names[i] = "default"
+		}
+	}
+	return strings.Join(names, " → ")
+}
@@ -0,0 +1,175 @@
+package taskrunner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+func escalationRunner(t *testing.T, cfg project.EscalationConfig) (*Runner, *[]Event) {
+	t.Helper()
+	var events []Event
+	r := &Runner{
+		config:       Config{Escalation: cfg, Timeout: time.Minute},
+		projectDir:   t.TempDir(),
+		logger:       log.New(io.Discard, "", 0),
+		eventHandler: func(e Event) { events = append(events, e) },
+	}
+	return r, &events
+}
+
+func readAttempts(t *testing.T, r *Runner) []Attempt {
+	t.Helper()
+	f, err := os.Open(filepath.Join(r.projectDir, ".devpilot", "logs", attemptsFile))
+	if err != nil {
+		t.Fatalf("open attempts: %v", err)
+	}
+	defer f.Close()
+	var attempts []Attempt
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var a Attempt
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			t.Fatalf("decode %q: %v", scanner.Text(), err)
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts
+}
+
+func TestClimbLadder_EscalatesAndRecords(t *testing.T) {
+	r, events := escalationRunner(t, project.EscalationConfig{Ladder: []string{"sonnet", "opus"}})
+	task := Task{ID: "42", Name: "add-auth"}
+
+	var models []string
+	res, tried := r.climbLadder(task, func(tier int, model string) attemptResult {
+		models = append(models, model)
+		r.emit(StatsUpdateEvent{InputTokens: 100 * (tier + 1), OutputTokens: 10, Turns: 3})
+		if tier == 0 {
+			return attemptResult{Reason: EscalateNoCommits, Err: "claude produced no commits on task branch"}
+		}
+		return attemptResult{PRURL: "https://github.com/o/r/pull/1"}
+	})
+
+	if res.Reason != "" || res.PRURL == "" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if modelTrail(tried) != "sonnet → opus" || len(models) != 2 || models[1] != "opus" {
+		t.Errorf("tried %v, attempts ran on %v", tried, models)
+	}
+
+	attempts := readAttempts(t, r)
+	if len(attempts) != 2 {
+		t.Fatalf("recorded %d attempts, want 2", len(attempts))
+	}
+	first, second := attempts[0], attempts[1]
+	if first.Model != "sonnet" || first.Outcome != EscalateNoCommits || first.InputTokens != 100 || first.TaskID != "42" {
+		t.Errorf("unexpected first attempt: %+v", first)
+	}
+	if second.Model != "opus" || second.Tier != 1 || second.Outcome != outcomeDone || second.InputTokens != 200 {
+		t.Errorf("unexpected second attempt: %+v", second)
+	}
+
+	var escalated *EscalatedEvent
+	for _, e := range *events {
+		if ev, ok := e.(EscalatedEvent); ok {
+			escalated = &ev
+		}
+	}
+	if escalated == nil || escalated.From != "sonnet" || escalated.To != "opus" || escalated.Reason != EscalateNoCommits {
+		t.Errorf("unexpected escalation event: %+v", escalated)
+	}
+}
+
+func TestClimbLadder_StopsWithoutEscalating(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    project.EscalationConfig
+		reason string
+		runs   int
+	}{
+		{"reason not configured", project.EscalationConfig{Ladder: []string{"sonnet", "opus"}, On: []string{EscalateReview}}, EscalateNoCommits, 1},
+		{"configured reason", project.EscalationConfig{Ladder: []string{"sonnet", "opus"}, On: []string{EscalateReview}}, EscalateReview, 2},
+		{"infrastructure error", project.EscalationConfig{Ladder: []string{"sonnet", "opus"}}, outcomeError, 1},
+		{"no ladder", project.EscalationConfig{}, EscalateExecution, 1},
+		{"top of ladder", project.EscalationConfig{Ladder: []string{"opus"}}, EscalateExecution, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := escalationRunner(t, tt.cfg)
+			runs := 0
+			res, _ := r.climbLadder(Task{ID: "1"}, func(int, string) attemptResult {
+				runs++
+				return attemptResult{Reason: tt.reason, Err: "failed"}
+			})
+			if runs != tt.runs || res.Reason != tt.reason {
+				t.Errorf("runs = %d, reason = %q; want %d runs", runs, res.Reason, tt.runs)
+			}
+			if got := len(readAttempts(t, r)); got != tt.runs {
+				t.Errorf("recorded %d attempts, want %d", got, tt.runs)
+			}
+		})
+	}
+}
+
+func TestRunAttempt_FailureReasons(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		reason  string
+	}{
+		{"no commits", "true", EscalateNoCommits},
+		{"claude failed", "false", EscalateExecution},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := setupGitRepo(t)
+			git := NewGitOps(dir)
+			git.CreateBranch("task/1-x")
+			r, _ := escalationRunner(t, project.EscalationConfig{})
+			r.config.WorkDir = dir
+			r.executor = NewExecutor(WithCommand(tt.command))
+
+			res := r.runAttempt(context.Background(), Task{ID: "1", Name: "x", Description: "plan"}, git, "task/1-x", "opus", false, time.Now())
+			if res.Reason != tt.reason {
+				t.Errorf("reason = %q (%s), want %q", res.Reason, res.Err, tt.reason)
+			}
+		})
+	}
+}
+
+func TestReviewGate_ReviewErrorDoesNotEscalate(t *testing.T) {
+	for _, command := range []string{"false", "devpilot-no-such-reviewer"} {
+		t.Run(command, func(t *testing.T) {
+			r, events := escalationRunner(t, project.EscalationConfig{Ladder: []string{"sonnet", "opus"}, On: []string{EscalateReview}})
+			r.config.ReviewTimeout = time.Minute
+			r.reviewer = NewReviewer(WithCommand(command))
+			git := NewGitOps(t.TempDir())
+
+			runs := 0
+			res, _ := r.climbLadder(Task{ID: "1", Name: "x"}, func(int, string) attemptResult {
+				runs++
+				return reviewOutcome("https://github.com/o/r/pull/1", r.reviewGate(context.Background(), Task{ID: "1"}, git, "task/1-x", "https://github.com/o/r/pull/1"))
+			})
+			if runs != 1 || res.Reason != outcomeError {
+				t.Errorf("runs = %d, reason = %q (%s); want one run ending in an error", runs, res.Reason, res.Err)
+			}
+			for _, e := range *events {
+				if _, ok := e.(EscalatedEvent); ok {
+					t.Errorf("a failed review escalated: %+v", e)
+				}
+			}
+			if a := readAttempts(t, r); len(a) != 1 || a[0].Outcome == EscalateReview {
+				t.Errorf("attempts = %+v, want one not recorded as a review rejection", a)
+			}
+		})
+	}
+}
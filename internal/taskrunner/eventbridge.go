@@ -4,9 +4,15 @@ import "github.com/charmbracelet/x/ansi"
 
 // eventBridge converts ClaudeEvents from the stream parser into runner Events.
 // It tracks in-flight tool use IDs to map results back to tool names.
+//
+// The token counts of the StatsUpdateEvents it emits are increments, so
+// consumers add them up. A run's result carries the run's totals, which
+// include the messages already reported; the bridge emits only the rest.
 type eventBridge struct {
 	emit          EventHandler
 	inflightTools map[string]string // tool_use_id -> tool name
+
+	runInput, runOutput int // tokens reported for the current run so far
 }
 
 func newEventBridge(emit EventHandler) *eventBridge {
@@ -18,8 +24,13 @@ func newEventBridge(emit EventHandler) *eventBridge {
 
 func (b *eventBridge) Handle(ce ClaudeEvent) {
 	switch msg := ce.(type) {
+	case ClaudeSystemMsg:
+		// A new run starts; one cut short never sent its result.
+		b.runInput, b.runOutput = 0, 0
 	case ClaudeAssistantMsg:
 		if msg.InputTokens > 0 || msg.OutputTokens > 0 {
+			b.runInput += msg.InputTokens
+			b.runOutput += msg.OutputTokens
 			b.emit(StatsUpdateEvent{
 				InputTokens:  msg.InputTokens,
 				OutputTokens: msg.OutputTokens,
@@ -44,14 +55,16 @@ func (b *eventBridge) Handle(ce ClaudeEvent) {
 				ToolName:   toolName,
 				DurationMs: tr.DurationMs,
 				Truncated:  tr.Truncated,
+				Output:     tr.Content,
 			})
 		}
 	case ClaudeResultMsg:
 		b.emit(StatsUpdateEvent{
-			InputTokens:  msg.InputTokens,
-			OutputTokens: msg.OutputTokens,
+			InputTokens:  max(msg.InputTokens-b.runInput, 0),
+			OutputTokens: max(msg.OutputTokens-b.runOutput, 0),
 			Turns:        msg.Turns,
 		})
+		b.runInput, b.runOutput = 0, 0
 	case RawOutputMsg:
 		if msg.Text != "" {
 			b.emit(TextOutputEvent{Text: ansi.Strip(msg.Text)})
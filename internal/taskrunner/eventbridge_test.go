@@ -102,7 +102,7 @@ func TestEventBridge_ToolResultEmitsToolResult(t *testing.T) {
 	// Then send the result
 	bridge.Handle(ClaudeUserMsg{
 		ToolResults: []ToolResult{
-			{ToolUseID: "t1", DurationMs: 3400},
+			{ToolUseID: "t1", Content: "ok  pkg  0.2s", DurationMs: 3400},
 		},
 	})
 
@@ -124,6 +124,9 @@ func TestEventBridge_ToolResultEmitsToolResult(t *testing.T) {
 	if toolResults[0].DurationMs != 3400 {
 		t.Errorf("DurationMs = %d, want 3400", toolResults[0].DurationMs)
 	}
+	if toolResults[0].Output != "ok  pkg  0.2s" {
+		t.Errorf("Output = %q, want tool result content", toolResults[0].Output)
+	}
 }
 
 func TestEventBridge_ResultEmitsFinalStats(t *testing.T) {
@@ -211,3 +214,27 @@ func TestEventBridge_EmptyTextIgnored(t *testing.T) {
 		t.Errorf("expected 0 events for empty text, got %d", len(events))
 	}
 }
+
+func TestEventBridge_ResultDoesNotRecountTokens(t *testing.T) {
+	var s taskStats
+	bridge := newEventBridge(s.observe)
+
+	// A complete run: its result repeats the per-message counts.
+	bridge.Handle(ClaudeSystemMsg{})
+	bridge.Handle(ClaudeAssistantMsg{InputTokens: 100, OutputTokens: 10})
+	bridge.Handle(ClaudeAssistantMsg{InputTokens: 200, OutputTokens: 20})
+	bridge.Handle(ClaudeResultMsg{Turns: 2, InputTokens: 300, OutputTokens: 30})
+	if s.inputTokens != 300 || s.outputTokens != 30 || s.turns != 2 {
+		t.Fatalf("after one run: %d in, %d out, %d turns; want 300, 30, 2", s.inputTokens, s.outputTokens, s.turns)
+	}
+
+	// A run cut short before its result, then a run on a higher tier.
+	bridge.Handle(ClaudeSystemMsg{})
+	bridge.Handle(ClaudeAssistantMsg{InputTokens: 50, OutputTokens: 5})
+	bridge.Handle(ClaudeSystemMsg{})
+	bridge.Handle(ClaudeAssistantMsg{InputTokens: 70, OutputTokens: 7})
+	bridge.Handle(ClaudeResultMsg{Turns: 1, InputTokens: 90, OutputTokens: 9})
+	if s.inputTokens != 440 || s.outputTokens != 44 {
+		t.Errorf("after three runs: %d in, %d out; want 440 and 44", s.inputTokens, s.outputTokens)
+	}
+}
@@ -28,9 +28,35 @@ type NoTasksEvent struct {
 
 func (e NoTasksEvent) eventType() string { return "no_tasks" }
 
+// ScheduleWaitEvent reports the runner waiting for its schedule before
+// starting another task.
+type ScheduleWaitEvent struct {
+	Until  time.Time
+	Reason string // ScheduleWaitTrigger or ScheduleWaitWindow
+}
+
+func (e ScheduleWaitEvent) eventType() string { return "schedule_wait" }
+
+// PausedEvent reports the runner holding off new tasks until an operator
+// resumes it.
+type PausedEvent struct{}
+
+func (e PausedEvent) eventType() string { return "paused" }
+
+// ApprovalNeededEvent reports a ready task that will not start until an
+// operator approves it.
+type ApprovalNeededEvent struct {
+	CardID   string
+	CardName string
+	URL      string
+}
+
+func (e ApprovalNeededEvent) eventType() string { return "approval_needed" }
+
 type CardStartedEvent struct {
 	CardID   string
 	CardName string
+	URL      string // link to the card or issue; empty when the source has none
 	Branch   string
 }
 
@@ -50,19 +76,23 @@ type CardFailedEvent struct {
 	CardName string
 	ErrMsg   string
 	Duration time.Duration
+	LogPath  string // the task's execution log; it may not exist if the task failed early
 }
 
 func (e CardFailedEvent) eventType() string { return "card_failed" }
 
 type ReviewStartedEvent struct {
-	PRURL string
+	PRURL    string
+	Reviewer string // panel reviewer; empty for the single code review
 }
 
 func (e ReviewStartedEvent) eventType() string { return "review_started" }
 
 type ReviewDoneEvent struct {
 	PRURL    string
+	Reviewer string // panel reviewer; empty for the single code review
 	ExitCode int
+	Approved bool
 }
 
 func (e ReviewDoneEvent) eventType() string { return "review_done" }
@@ -82,6 +112,18 @@ type FixDoneEvent struct {
 
 func (e FixDoneEvent) eventType() string { return "fix_done" }
 
+// EscalatedEvent reports a task being retried on the next model of the
+// escalation ladder.
+type EscalatedEvent struct {
+	CardID   string
+	CardName string
+	From     string // empty means the CLI default model
+	To       string
+	Reason   string // EscalateNoCommits, EscalateExecution or EscalateReview
+}
+
+func (e EscalatedEvent) eventType() string { return "escalated" }
+
 type RunnerStoppedEvent struct{}
 
 func (e RunnerStoppedEvent) eventType() string { return "runner_stopped" }
@@ -103,6 +145,7 @@ type ToolResultEvent struct {
 	ToolName   string
 	DurationMs int
 	Truncated  bool
+	Output     string
 }
 
 func (e ToolResultEvent) eventType() string { return "tool_result" }
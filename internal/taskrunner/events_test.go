@@ -14,6 +14,9 @@ func TestEventTypes(t *testing.T) {
 		{"RunnerStarted", RunnerStartedEvent{BoardName: "B", BoardID: "1", Lists: map[string]string{"Ready": "r1"}}, "runner_started"},
 		{"Polling", PollingEvent{}, "polling"},
 		{"NoTasks", NoTasksEvent{NextPoll: 5 * time.Second}, "no_tasks"},
+		{"ScheduleWait", ScheduleWaitEvent{Until: time.Now(), Reason: ScheduleWaitTrigger}, "schedule_wait"},
+		{"Paused", PausedEvent{}, "paused"},
+		{"ApprovalNeeded", ApprovalNeededEvent{CardID: "c1", CardName: "Fix bug"}, "approval_needed"},
 		{"CardStarted", CardStartedEvent{CardID: "c1", CardName: "Fix bug", Branch: "task/c1-fix"}, "card_started"},
 		{"CardDone", CardDoneEvent{CardID: "c1", CardName: "Fix bug", PRURL: "http://pr", Duration: time.Minute}, "card_done"},
 		{"CardFailed", CardFailedEvent{CardID: "c1", CardName: "Fix bug", ErrMsg: "oops", Duration: time.Minute}, "card_failed"},
@@ -21,6 +24,7 @@ func TestEventTypes(t *testing.T) {
 		{"ReviewDone", ReviewDoneEvent{PRURL: "http://pr", ExitCode: 0}, "review_done"},
 		{"FixStarted", FixStartedEvent{PRURL: "http://pr", Attempt: 1}, "fix_started"},
 		{"FixDone", FixDoneEvent{PRURL: "http://pr", Attempt: 1, ExitCode: 0}, "fix_done"},
+		{"Escalated", EscalatedEvent{CardID: "c1", From: "sonnet", To: "opus", Reason: EscalateNoCommits}, "escalated"},
 		{"RunnerStopped", RunnerStoppedEvent{}, "runner_stopped"},
 		{"RunnerError", RunnerErrorEvent{Err: nil}, "runner_error"},
 		{"ToolStart", ToolStartEvent{ToolName: "Read", Input: map[string]any{"file_path": "/tmp/f.go"}}, "tool_start"},
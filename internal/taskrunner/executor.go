@@ -33,6 +33,8 @@ type ClaudeEventHandler func(event ClaudeEvent)
 type Executor struct {
 	command            string
 	args               []string
+	dir                string // working directory; empty means the current directory
+	model              string // claude --model; empty uses the CLI default
 	outputHandler      OutputHandler
 	claudeEventHandler ClaudeEventHandler
 }
@@ -58,6 +60,13 @@ func WithClaudeEventHandler(handler ClaudeEventHandler) ExecutorOption {
 	}
 }
 
+// WithModel selects the Claude model. It is ignored for test commands.
+func WithModel(model string) ExecutorOption {
+	return func(e *Executor) {
+		e.model = model
+	}
+}
+
 func NewExecutor(opts ...ExecutorOption) *Executor {
 	e := &Executor{
 		command: "claude",
@@ -69,16 +78,34 @@ func NewExecutor(opts ...ExecutorOption) *Executor {
 	return e
 }
 
+// InDir returns a copy of e that runs in dir.
+func (e *Executor) InDir(dir string) *Executor {
+	cp := *e
+	cp.dir = dir
+	return &cp
+}
+
+// withModel returns a copy of e that runs model.
+func (e *Executor) withModel(model string) *Executor {
+	cp := *e
+	cp.model = model
+	return &cp
+}
+
 func (e *Executor) Run(ctx context.Context, prompt string) (*ExecuteResult, error) {
 	args := make([]string, len(e.args))
 	copy(args, e.args)
 
 	// Only append prompt if using claude (not test commands)
 	if e.command == "claude" {
+		if e.model != "" {
+			args = append(args, "--model", e.model)
+		}
 		args = append(args, prompt)
 	}
 
 	cmd := exec.CommandContext(ctx, e.command, args...)
+	cmd.Dir = e.dir
 
 	if e.outputHandler == nil && e.claudeEventHandler == nil {
 		return e.runBuffered(ctx, cmd)
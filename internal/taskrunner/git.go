@@ -1,18 +1,90 @@
 package taskrunner
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/siyuqian/devpilot/internal/github"
 )
 
+const defaultRemote = "origin"
+
 type GitOps struct {
-	dir string
+	dir    string
+	base   string // base branch; empty means auto-detect main, then master
+	remote string // remote to fetch from and push to
+	repo   string // PR target repository (owner/repo); empty uses the remote's repository
+	gh     *github.Client
+}
+
+// GitOption configures a GitOps.
+type GitOption func(*GitOps)
+
+// WithBaseBranch sets the branch tasks are branched from and PRs target.
+func WithBaseBranch(branch string) GitOption {
+	return func(g *GitOps) {
+		g.base = branch
+	}
+}
+
+// WithRemote sets the git remote used for fetch, pull and push.
+func WithRemote(remote string) GitOption {
+	return func(g *GitOps) {
+		if remote != "" {
+			g.remote = remote
+		}
+	}
+}
+
+// WithPRRepo sets the repository (owner/repo) that PRs are opened against,
+// e.g. the upstream repository in a fork-based workflow.
+func WithPRRepo(repo string) GitOption {
+	return func(g *GitOps) {
+		g.repo = repo
+	}
+}
+
+// WithGitHub sets the client used for PR operations.
+func WithGitHub(c *github.Client) GitOption {
+	return func(g *GitOps) {
+		g.gh = c
+	}
+}
+
+func NewGitOps(dir string, opts ...GitOption) *GitOps {
+	g := &GitOps{dir: dir, remote: defaultRemote}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// ForBase returns a copy of g that targets base instead of the configured
+// base branch. An empty base returns g itself.
+func (g *GitOps) ForBase(base string) *GitOps {
+	if base == "" {
+		return g
+	}
+	cp := *g
+	cp.base = base
+	return &cp
 }
 
-func NewGitOps(dir string) *GitOps {
-	return &GitOps{dir: dir}
+// Base returns the effective base branch: the configured one, or main if it
+// exists locally, falling back to master.
+func (g *GitOps) Base() string {
+	if g.base != "" {
+		return g.base
+	}
+	if _, err := g.run("rev-parse", "--verify", "--quiet", "refs/heads/main"); err == nil {
+		return "main"
+	}
+	return "master"
 }
 
 func (g *GitOps) run(args ...string) (string, error) {
@@ -25,25 +97,73 @@ func (g *GitOps) run(args ...string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
+// Exclude adds pattern to the repository's info/exclude file, so files
+// devpilot leaves in the checkout are never committed.
+func (g *GitOps) Exclude(pattern string) error {
+	path, err := g.run("rev-parse", "--git-path", "info/exclude")
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(g.dir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		pattern = "\n" + pattern
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(pattern + "\n")
+	return err
+}
+
 func (g *GitOps) CreateBranch(name string) error {
 	_, err := g.run("checkout", "-B", name)
 	return err
 }
 
-func (g *GitOps) CheckoutMain() error {
-	if _, err := g.run("checkout", "main"); err != nil {
-		_, err = g.run("checkout", "master")
+// CheckoutBase checks out the base branch, creating a local tracking branch
+// from the remote when it only exists there (e.g. a release branch).
+func (g *GitOps) CheckoutBase() error {
+	base := g.Base()
+	if _, err := g.run("checkout", base); err == nil {
+		return nil
+	}
+	if _, err := g.run("fetch", g.remote, base); err != nil {
 		return err
 	}
-	return nil
+	_, err := g.run("checkout", "-b", base, "--track", g.remote+"/"+base)
+	return err
 }
 
+// Pull fast-forwards the current branch from the base branch on the remote.
 func (g *GitOps) Pull() error {
-	_, err := g.run("pull", "--ff-only")
+	_, err := g.run("pull", "--ff-only", g.remote, g.Base())
 	return err
 }
 
+// safeID makes a task ID usable in branch and file names: qualified GitHub
+// IDs like "owner/repo#42" become "owner-repo-42".
+func safeID(id string) string {
+	return strings.NewReplacer("/", "-", "#", "-").Replace(id)
+}
+
 func (g *GitOps) BranchName(cardID, cardName string) string {
+	cardID = safeID(cardID)
 	slug := Slugify(cardName)
 	if len(slug) > 40 {
 		slug = slug[:40]
@@ -56,28 +176,273 @@ func (g *GitOps) BranchName(cardID, cardName string) string {
 }
 
 func (g *GitOps) Push(branch string) error {
-	_, err := g.run("push", "-u", "origin", branch)
+	_, err := g.run("push", "-u", g.remote, branch)
 	return err
 }
 
-func (g *GitOps) CreatePR(title, body string) (string, error) {
-	cmd := exec.Command("gh", "pr", "create", "--title", title, "--body", body)
-	cmd.Dir = g.dir
-	out, err := cmd.CombinedOutput()
+// ForcePush pushes branch over the copy an earlier run left on the remote.
+// --force-with-lease refuses if the remote moved since it was last fetched.
+func (g *GitOps) ForcePush(branch string) error {
+	_, err := g.run("push", "--force-with-lease", "-u", g.remote, branch)
+	return err
+}
+
+// BranchExists reports whether branch exists locally.
+func (g *GitOps) BranchExists(branch string) bool {
+	_, err := g.run("rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	return err == nil
+}
+
+// FetchBranch updates the remote-tracking ref for branch and reports whether
+// the branch exists on the remote.
+func (g *GitOps) FetchBranch(branch string) bool {
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, g.remote, branch)
+	_, err := g.run("fetch", g.remote, refspec)
+	return err == nil
+}
+
+// RebaseBranch checks out an existing task branch (the local one if present,
+// otherwise the remote copy) and rebases it onto the base branch. A
+// conflicting rebase is aborted and returned as an error.
+func (g *GitOps) RebaseBranch(branch string) error {
+	if g.BranchExists(branch) {
+		if _, err := g.run("checkout", branch); err != nil {
+			return err
+		}
+	} else if _, err := g.run("checkout", "-B", branch, g.remote+"/"+branch); err != nil {
+		return err
+	}
+	if _, err := g.run("rebase", g.Base()); err != nil {
+		g.run("rebase", "--abort")
+		return err
+	}
+	return nil
+}
+
+// prRepo returns the repository PRs are opened against: the configured PR
+// repository, or the one the remote points at.
+func (g *GitOps) prRepo() (string, error) {
+	if g.repo != "" {
+		return g.repo, nil
+	}
+	return github.RepoFromRemote(g.dir, g.remote)
+}
+
+// prHead returns the PR head for branch, qualified with the owner of the
+// pushed-to repository ("owner:branch") so it also works from a fork.
+func (g *GitOps) prHead(branch string) (string, error) {
+	remoteRepo, err := github.RepoFromRemote(g.dir, g.remote)
 	if err != nil {
-		return "", fmt.Errorf("gh pr create: %s %w", string(out), err)
+		return "", err
 	}
-	return strings.TrimSpace(string(out)), nil
+	owner, _, _ := strings.Cut(remoteRepo, "/")
+	return owner + ":" + branch, nil
 }
 
-func (g *GitOps) MergePR() error {
-	cmd := exec.Command("gh", "pr", "merge", "--squash", "--auto")
-	cmd.Dir = g.dir
-	out, err := cmd.CombinedOutput()
+// client returns the GitHub client, failing clearly when none is configured.
+func (g *GitOps) client() (*github.Client, error) {
+	if g.gh == nil {
+		return nil, fmt.Errorf("not logged in to GitHub, which opening and merging PRs needs. Run: devpilot login github")
+	}
+	return g.gh, nil
+}
+
+// pr resolves prURL to its repository and number along with the client.
+func (g *GitOps) pr(prURL string) (*github.Client, string, int, error) {
+	c, err := g.client()
 	if err != nil {
-		return fmt.Errorf("gh pr merge: %s %w", string(out), err)
+		return nil, "", 0, err
 	}
-	return nil
+	repo, number, err := github.ParseIssueURL(prURL)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return c, repo, number, nil
+}
+
+// CreatePR opens a PR from branch against the base branch and returns its URL.
+func (g *GitOps) CreatePR(branch, title, body string) (string, error) {
+	c, err := g.client()
+	if err != nil {
+		return "", err
+	}
+	repo, err := g.prRepo()
+	if err != nil {
+		return "", err
+	}
+	head, err := g.prHead(branch)
+	if err != nil {
+		return "", err
+	}
+	pr, err := c.CreatePullRequest(repo, github.NewPullRequest{Title: title, Body: body, Head: head, Base: g.Base()})
+	if err != nil {
+		return "", err
+	}
+	return pr.HTMLURL, nil
+}
+
+// FindPR returns the URL of the open PR whose head is branch, or "" if there
+// is none.
+func (g *GitOps) FindPR(branch string) (string, error) {
+	c, err := g.client()
+	if err != nil {
+		return "", err
+	}
+	repo, err := g.prRepo()
+	if err != nil {
+		return "", err
+	}
+	head, err := g.prHead(branch)
+	if err != nil {
+		return "", err
+	}
+	prs, err := c.ListPullRequests(repo, head, "open")
+	if err != nil || len(prs) == 0 {
+		return "", err
+	}
+	return prs[0].HTMLURL, nil
+}
+
+// UpdatePR replaces the title and body of the PR at prURL and points it at
+// the base branch, which may have changed since the PR was opened.
+func (g *GitOps) UpdatePR(prURL, title, body string) error {
+	c, repo, number, err := g.pr(prURL)
+	if err != nil {
+		return err
+	}
+	base := g.Base()
+	_, err = c.EditPullRequest(repo, number, github.PullRequestEdit{Title: &title, Body: &body, Base: &base})
+	return err
+}
+
+// MergePR enables squash auto-merge on the PR at prURL. Addressing the PR by
+// URL keeps this working when PRs target a repository other than the
+// current checkout's default.
+func (g *GitOps) MergePR(prURL string) error {
+	c, repo, number, err := g.pr(prURL)
+	if err != nil {
+		return err
+	}
+	return c.AutoMerge(repo, number, "squash")
+}
+
+// MergeStackedPR enables auto-merge on a PR that is part of a stack. It uses
+// a merge commit rather than a squash so the next PR in the stack still
+// shows only its own changes once it is retargeted.
+func (g *GitOps) MergeStackedPR(prURL string) error {
+	c, repo, number, err := g.pr(prURL)
+	if err != nil {
+		return err
+	}
+	return c.AutoMerge(repo, number, "merge")
+}
+
+// EditPRBody replaces the description of the PR at prURL.
+func (g *GitOps) EditPRBody(prURL, body string) error {
+	c, repo, number, err := g.pr(prURL)
+	if err != nil {
+		return err
+	}
+	_, err = c.EditPullRequest(repo, number, github.PullRequestEdit{Body: &body})
+	return err
+}
+
+// PostReview submits body as a comment review on the PR at prURL.
+func (g *GitOps) PostReview(prURL, body string) error {
+	c, repo, number, err := g.pr(prURL)
+	if err != nil {
+		return err
+	}
+	_, err = c.CreateReview(repo, number, github.NewReview{Body: body, Event: github.ReviewComment})
+	return err
+}
+
+// PRMetadata is task metadata carried over to a PR.
+type PRMetadata struct {
+	Labels    []string
+	Reviewers []string
+	Assignees []string
+	Milestone string
+}
+
+// ApplyPRMetadata adds labels, reviewers, assignees and the milestone to the
+// PR at prURL. Each is applied independently so one failure (e.g. an
+// unknown label) does not prevent the rest; the errors are joined.
+func (g *GitOps) ApplyPRMetadata(prURL string, m PRMetadata) error {
+	if len(m.Labels) == 0 && len(m.Reviewers) == 0 && len(m.Assignees) == 0 && m.Milestone == "" {
+		return nil
+	}
+	c, repo, number, err := g.pr(prURL)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	if len(m.Labels) > 0 {
+		if err := c.AddLabels(repo, number, m.Labels...); err != nil {
+			errs = append(errs, fmt.Errorf("add labels: %w", err))
+		}
+	}
+	if len(m.Reviewers) > 0 {
+		if err := c.RequestReviewers(repo, number, m.Reviewers...); err != nil {
+			errs = append(errs, fmt.Errorf("request reviewers: %w", err))
+		}
+	}
+	if len(m.Assignees) > 0 {
+		if err := c.AddAssignees(repo, number, m.Assignees...); err != nil {
+			errs = append(errs, fmt.Errorf("add assignees: %w", err))
+		}
+	}
+	if m.Milestone != "" {
+		if err := c.SetMilestone(repo, number, m.Milestone); err != nil {
+			errs = append(errs, fmt.Errorf("set milestone: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RetargetPR changes the base branch of the PR at prURL.
+func (g *GitOps) RetargetPR(prURL, base string) error {
+	c, repo, number, err := g.pr(prURL)
+	if err != nil {
+		return err
+	}
+	_, err = c.EditPullRequest(repo, number, github.PullRequestEdit{Base: &base})
+	return err
+}
+
+// PRState returns the state of the PR at prURL: OPEN, CLOSED or MERGED.
+func (g *GitOps) PRState(prURL string) (string, error) {
+	c, repo, number, err := g.pr(prURL)
+	if err != nil {
+		return "", err
+	}
+	pr, err := c.GetPullRequest(repo, number)
+	if err != nil {
+		return "", err
+	}
+	if pr.Merged {
+		return "MERGED", nil
+	}
+	return strings.ToUpper(pr.State), nil
+}
+
+// DiffStat returns the diffstat of branch relative to the base branch.
+func (g *GitOps) DiffStat(branch string) (string, error) {
+	return g.run("diff", "--stat", g.Base()+"..."+branch)
+}
+
+// Diff returns the full diff of branch relative to the base branch.
+func (g *GitOps) Diff(branch string) (string, error) {
+	return g.run("diff", g.Base()+"..."+branch)
+}
+
+// ChangedFiles lists the files branch changes relative to the base branch.
+func (g *GitOps) ChangedFiles(branch string) ([]string, error) {
+	out, err := g.run("diff", "--name-only", g.Base()+"..."+branch)
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
 }
 
 // IsClean returns true if the working directory has no uncommitted changes.
@@ -89,14 +454,11 @@ func (g *GitOps) IsClean() (bool, error) {
 	return out == "", nil
 }
 
-// HasNewCommits returns true if branch has commits not on main (or master).
+// HasNewCommits returns true if branch has commits not on the base branch.
 func (g *GitOps) HasNewCommits(branch string) (bool, error) {
-	out, err := g.run("rev-list", "--count", "main.."+branch)
+	out, err := g.run("rev-list", "--count", g.Base()+".."+branch)
 	if err != nil {
-		out, err = g.run("rev-list", "--count", "master.."+branch)
-		if err != nil {
-			return false, err
-		}
+		return false, err
 	}
 	return strings.TrimSpace(out) != "0", nil
 }
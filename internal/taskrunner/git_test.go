@@ -1,10 +1,16 @@
 package taskrunner
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
+
+	"github.com/siyuqian/devpilot/internal/github"
 )
 
 func setupGitRepo(t *testing.T) string {
@@ -44,13 +50,13 @@ func TestCreateBranch(t *testing.T) {
 	}
 }
 
-func TestCheckoutMain(t *testing.T) {
+func TestCheckoutBase(t *testing.T) {
 	dir := setupGitRepo(t)
 	git := NewGitOps(dir)
 
 	git.CreateBranch("task/test")
 
-	err := git.CheckoutMain()
+	err := git.CheckoutBase()
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -175,7 +181,7 @@ func TestCreateBranchAlreadyExists(t *testing.T) {
 	}
 
 	// Go back to main
-	if err := git.CheckoutMain(); err != nil {
+	if err := git.CheckoutBase(); err != nil {
 		t.Fatalf("checkout main: %v", err)
 	}
 
@@ -184,3 +190,144 @@ func TestCreateBranchAlreadyExists(t *testing.T) {
 		t.Errorf("second create should succeed with -B, got: %v", err)
 	}
 }
+
+func TestCheckoutBase_Configured(t *testing.T) {
+	dir := setupGitRepo(t)
+	cmd := exec.Command("git", "branch", "develop")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("create develop: %s %v", out, err)
+	}
+
+	git := NewGitOps(dir, WithBaseBranch("develop"))
+	if err := git.CreateBranch("task/test"); err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+	if err := git.CheckoutBase(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cmd = exec.Command("git", "branch", "--show-current")
+	cmd.Dir = dir
+	out, _ := cmd.Output()
+	if string(out) != "develop\n" {
+		t.Errorf("expected develop, got %q", out)
+	}
+}
+
+func TestHasNewCommits_ConfiguredBase(t *testing.T) {
+	dir := setupGitRepo(t)
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s %v", args, out, err)
+		}
+	}
+	run("checkout", "-b", "develop")
+	run("commit", "--allow-empty", "-m", "develop work")
+
+	git := NewGitOps(dir, WithBaseBranch("develop"))
+	if err := git.CreateBranch("task/test"); err != nil {
+		t.Fatalf("create branch: %v", err)
+	}
+
+	// develop's own commit must not count as task work.
+	has, err := git.HasNewCommits("task/test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if has {
+		t.Error("expected no new commits relative to develop")
+	}
+}
+
+func TestForBase(t *testing.T) {
+	git := NewGitOps("/tmp", WithBaseBranch("develop"), WithRemote("upstream"))
+
+	if got := git.ForBase(""); got != git {
+		t.Error("ForBase(\"\") should return the receiver")
+	}
+
+	task := git.ForBase("release-1.2")
+	if task.Base() != "release-1.2" {
+		t.Errorf("Base() = %q, want release-1.2", task.Base())
+	}
+	if task.remote != "upstream" {
+		t.Errorf("remote = %q, want upstream", task.remote)
+	}
+	if git.Base() != "develop" {
+		t.Errorf("original Base() = %q, want develop (must not be mutated)", git.Base())
+	}
+}
+
+func TestCreatePR_ForkHeadAndBase(t *testing.T) {
+	dir := setupGitRepo(t)
+	cmd := exec.Command("git", "remote", "add", "origin", "git@github.com:fork/project.git")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("add remote: %s %v", out, err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/upstream/project/pulls" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var in github.NewPullRequest
+		json.NewDecoder(r.Body).Decode(&in)
+		if in.Head != "fork:task/1-x" || in.Base != "develop" {
+			t.Errorf("unexpected head/base: %+v", in)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number":5,"html_url":"https://github.com/upstream/project/pull/5"}`)
+	}))
+	defer srv.Close()
+
+	git := NewGitOps(dir, WithBaseBranch("develop"), WithPRRepo("upstream/project"),
+		WithGitHub(github.NewClient("t", github.WithBaseURL(srv.URL))))
+	url, err := git.CreatePR("task/1-x", "Title", "Body")
+	if err != nil {
+		t.Fatalf("CreatePR: %v", err)
+	}
+	if url != "https://github.com/upstream/project/pull/5" {
+		t.Errorf("url = %q", url)
+	}
+}
+
+func TestPRState(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/pulls/1":
+			fmt.Fprint(w, `{"state":"closed","merged":true}`)
+		case "/repos/o/r/pulls/2":
+			fmt.Fprint(w, `{"state":"open"}`)
+		}
+	}))
+	defer srv.Close()
+
+	git := NewGitOps("/tmp", WithGitHub(github.NewClient("t", github.WithBaseURL(srv.URL))))
+	for url, want := range map[string]string{
+		"https://github.com/o/r/pull/1": "MERGED",
+		"https://github.com/o/r/pull/2": "OPEN",
+	} {
+		if got, err := git.PRState(url); err != nil || got != want {
+			t.Errorf("PRState(%s) = %q, %v; want %s", url, got, err, want)
+		}
+	}
+}
+
+func TestPROperations_RequireClient(t *testing.T) {
+	if _, err := NewGitOps("/tmp").CreatePR("b", "t", "b"); err == nil {
+		t.Error("expected an error without a GitHub client")
+	}
+}
+
+func TestNewGitOps_DefaultRemote(t *testing.T) {
+	if got := NewGitOps("/tmp").remote; got != "origin" {
+		t.Errorf("remote = %q, want origin", got)
+	}
+	if got := NewGitOps("/tmp", WithRemote("")).remote; got != "origin" {
+		t.Errorf("remote = %q, want origin for empty override", got)
+	}
+}
@@ -0,0 +1,237 @@
+package taskrunner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/github"
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+// GitHubProjectSource implements TaskSource on a GitHub Projects (v2) board.
+// Tasks are items whose Status field is the configured Ready option; state
+// changes move the item between Status options through GraphQL. Both issues
+// and draft items are supported; task IDs are project item node IDs.
+type GitHubProjectSource struct {
+	client *github.Client
+	board  project.ProjectBoard
+
+	project  *github.Project
+	status   *github.ProjectField
+	priority string // the priority field's name as the project spells it; "" when it has none
+}
+
+func NewGitHubProjectSource(client *github.Client, board project.ProjectBoard) *GitHubProjectSource {
+	return &GitHubProjectSource{client: client, board: board.WithDefaults()}
+}
+
+func (s *GitHubProjectSource) Init() (SourceInfo, error) {
+	if s.board.Owner == "" || s.board.Number == 0 {
+		return SourceInfo{}, fmt.Errorf("no GitHub project configured (set github.project.owner and github.project.number in .devpilot.yaml)")
+	}
+	p, err := s.client.GetProject(s.board.Owner, s.board.Number)
+	if err != nil {
+		return SourceInfo{}, fmt.Errorf("load project %s/%d: %w", s.board.Owner, s.board.Number, err)
+	}
+	status := p.Field(s.board.StatusField)
+	if status == nil {
+		return SourceInfo{}, fmt.Errorf("project %q has no %q field", p.Title, s.board.StatusField)
+	}
+	for _, name := range []string{s.board.Ready, s.board.InProgress, s.board.Done, s.board.Failed} {
+		if status.Option(name) == nil {
+			return SourceInfo{}, fmt.Errorf("project %q: %s field has no %q option", p.Title, status.Name, name)
+		}
+	}
+	s.project, s.status = p, status
+	// Item field values are keyed by the project's spelling of the name.
+	if f := p.Field(s.board.PriorityField); f != nil {
+		s.priority = f.Name
+	}
+	return SourceInfo{DisplayName: p.Title}, nil
+}
+
+func (s *GitHubProjectSource) FetchReady() ([]Task, error) {
+	if s.project == nil {
+		return nil, fmt.Errorf("project source not initialized")
+	}
+	items, err := s.client.ListProjectItems(s.project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list project items: %w", err)
+	}
+	var tasks []Task
+	for _, item := range items {
+		if item.Type != "ISSUE" && item.Type != "DRAFT_ISSUE" {
+			continue
+		}
+		if !strings.EqualFold(item.Fields[s.status.Name], s.board.Ready) {
+			continue
+		}
+		tasks = append(tasks, s.itemToTask(item))
+	}
+	return tasks, nil
+}
+
+func (s *GitHubProjectSource) itemToTask(item github.ProjectItem) Task {
+	c := item.Content
+	task := Task{
+		ID:          item.ID,
+		Name:        c.Title,
+		Description: c.Body,
+		URL:         c.URL,
+		Priority:    priorityFromFieldValue(item.Fields[s.priority]),
+		CreatedAt:   c.CreatedAt.Unix(),
+	}
+	if item.Type == "DRAFT_ISSUE" {
+		// Drafts have no page of their own; link to the board instead.
+		task.URL = s.project.URL
+		return task
+	}
+	task.Labels = c.Labels
+	task.BaseBranch = baseFromLabelNames(c.Labels)
+	task.Assignees = c.Assignees
+	task.Milestone = c.Milestone
+	task.Repo = c.Repo
+	task.Closes = fmt.Sprintf("%s#%d", c.Repo, c.Number)
+	return task
+}
+
+// priorityFromFieldValue maps a Priority field value to 0–2. It accepts
+// P0/P1/P2-prefixed options (like priority labels) and plain numbers.
+func priorityFromFieldValue(v string) int {
+	if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n >= 0 && n <= 2 {
+		return n
+	}
+	return priorityFromLabelNames([]string{v})
+}
+
+func (s *GitHubProjectSource) MarkInProgress(id string) error {
+	return s.setStatus(id, s.board.InProgress)
+}
+
+func (s *GitHubProjectSource) MarkDone(id, comment string) error {
+	if err := s.setStatus(id, s.board.Done); err != nil {
+		return err
+	}
+	return s.addComment(id, comment)
+}
+
+func (s *GitHubProjectSource) MarkFailed(id, comment string) error {
+	if err := s.setStatus(id, s.board.Failed); err != nil {
+		return err
+	}
+	return s.addComment(id, comment)
+}
+
+// Requeue sets the item's status back to Ready.
+func (s *GitHubProjectSource) Requeue(id string) error {
+	return s.setStatus(id, s.board.Ready)
+}
+
+func (s *GitHubProjectSource) setStatus(id, name string) error {
+	if s.project == nil {
+		return fmt.Errorf("project source not initialized")
+	}
+	opt := s.status.Option(name)
+	if opt == nil {
+		return fmt.Errorf("%s field has no %q option", s.status.Name, name)
+	}
+	if err := s.client.SetProjectItemOption(s.project.ID, id, s.status.ID, opt.ID); err != nil {
+		return fmt.Errorf("set %s of item %s to %q: %w", s.status.Name, id, name, err)
+	}
+	return nil
+}
+
+// addComment comments on the item's issue. Draft items cannot take
+// comments, so the comment is appended to the draft's body instead.
+func (s *GitHubProjectSource) addComment(id, comment string) error {
+	item, err := s.client.GetProjectItem(id)
+	if err != nil {
+		return fmt.Errorf("get project item %s: %w", id, err)
+	}
+	c := item.Content
+	switch item.Type {
+	case "ISSUE":
+		if _, err := s.client.CreateComment(c.Repo, c.Number, comment); err != nil {
+			return fmt.Errorf("add comment to issue %s#%d: %w", c.Repo, c.Number, err)
+		}
+	case "DRAFT_ISSUE":
+		body := strings.TrimRight(c.Body, "\n") + "\n\n---\n\n" + comment
+		if err := s.client.UpdateDraftIssueBody(c.ID, body); err != nil {
+			return fmt.Errorf("update draft item %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *GitHubProjectSource) UpdatePlan(id, plan string) error {
+	item, err := s.client.GetProjectItem(id)
+	if err != nil {
+		return fmt.Errorf("get project item %s: %w", id, err)
+	}
+	c := item.Content
+	switch item.Type {
+	case "ISSUE":
+		if _, err := s.client.EditIssue(c.Repo, c.Number, github.IssueEdit{Body: &plan}); err != nil {
+			return fmt.Errorf("update issue %s#%d: %w", c.Repo, c.Number, err)
+		}
+	case "DRAFT_ISSUE":
+		if err := s.client.UpdateDraftIssueBody(c.ID, plan); err != nil {
+			return fmt.Errorf("update draft item %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// GatherContext gathers the issue context of issue items. Draft items have
+// nothing beyond their body.
+func (s *GitHubProjectSource) GatherContext(task Task, want project.ContextConfig) (*TaskContext, error) {
+	item, err := s.client.GetProjectItem(task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get project item %s: %w", task.ID, err)
+	}
+	if item.Type != "ISSUE" {
+		return &TaskContext{}, nil
+	}
+	return gatherIssueContext(s.client, item.Content.Repo, item.Content.Number, task.Description, want)
+}
+
+// Claim leases issue items through a comment on the issue. Draft items
+// cannot take comments, so their claim always succeeds; boards shared by
+// several runners should convert drafts to issues.
+func (s *GitHubProjectSource) Claim(id string, lease Lease) (bool, error) {
+	t, err := s.thread(id)
+	if err != nil || t == nil {
+		return err == nil, err
+	}
+	return claimByComment(t, lease, time.Now())
+}
+
+func (s *GitHubProjectSource) Renew(id string, lease Lease) error {
+	t, err := s.thread(id)
+	if err != nil || t == nil {
+		return err
+	}
+	return renewByComment(t, lease, time.Now())
+}
+
+func (s *GitHubProjectSource) Release(id, runnerID string) error {
+	t, err := s.thread(id)
+	if err != nil || t == nil {
+		return err
+	}
+	return releaseByComment(t, runnerID)
+}
+
+// thread returns the comment thread of an issue item, or nil for drafts.
+func (s *GitHubProjectSource) thread(id string) (commentThread, error) {
+	item, err := s.client.GetProjectItem(id)
+	if err != nil {
+		return nil, fmt.Errorf("get project item %s: %w", id, err)
+	}
+	if item.Type != "ISSUE" {
+		return nil, nil
+	}
+	return ghCommentThread{client: s.client, repo: item.Content.Repo, number: item.Content.Number}, nil
+}
@@ -0,0 +1,173 @@
+package taskrunner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siyuqian/devpilot/internal/github"
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+const testProjectJSON = `{"data":{"repositoryOwner":{"projectV2":{
+  "id":"PVT_1","title":"Roadmap","number":3,"url":"https://github.com/orgs/acme/projects/3",
+  "fields":{"nodes":[
+    {"id":"F_title","name":"Title"},
+    {"id":"F_status","name":"Status","options":[
+      {"id":"o_ready","name":"Ready"},{"id":"o_wip","name":"In Progress"},
+      {"id":"o_done","name":"Done"},{"id":"o_failed","name":"Failed"}]},
+    {"id":"F_prio","name":"Priority","options":[{"id":"p0","name":"P0"},{"id":"p1","name":"P1"}]}
+  ]}}}}}`
+
+const testProjectItemsJSON = `{"data":{"node":{"items":{"pageInfo":{"hasNextPage":false},"nodes":[
+  {"id":"PVTI_issue","type":"ISSUE",
+   "fieldValues":{"nodes":[{"name":"Ready","field":{"name":"Status"}},{"name":"P1","field":{"name":"Priority"}}]},
+   "content":{"id":"I_1","number":12,"title":"Fix login","body":"details","url":"https://github.com/acme/api/issues/12",
+     "createdAt":"2024-06-01T12:00:00Z","repository":{"nameWithOwner":"acme/api"},
+     "labels":{"nodes":[{"name":"bug"},{"name":"base:develop"}]},"assignees":{"nodes":[{"login":"alice"}]},"milestone":{"title":"v2"}}},
+  {"id":"PVTI_draft","type":"DRAFT_ISSUE",
+   "fieldValues":{"nodes":[{"name":"Ready","field":{"name":"Status"}},{"name":"P0","field":{"name":"Priority"}}]},
+   "content":{"id":"DI_1","title":"Draft idea","body":"plan","createdAt":"2024-06-02T12:00:00Z"}},
+  {"id":"PVTI_wip","type":"ISSUE",
+   "fieldValues":{"nodes":[{"name":"In Progress","field":{"name":"Status"}}]},
+   "content":{"id":"I_2","number":13,"title":"Busy","repository":{"nameWithOwner":"acme/api"}}},
+  {"id":"PVTI_pr","type":"PULL_REQUEST",
+   "fieldValues":{"nodes":[{"name":"Ready","field":{"name":"Status"}}]},"content":{}}
+]}}}}`
+
+type projectRecorder struct {
+	mutations []map[string]any
+	comments  []string
+}
+
+func newProjectServer(t *testing.T, rec *projectRecorder) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/acme/api/issues/12/comments" {
+			var body struct{ Body string }
+			json.NewDecoder(r.Body).Decode(&body)
+			rec.comments = append(rec.comments, body.Body)
+			w.Write([]byte(`{"id":1}`))
+			return
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case strings.Contains(req.Query, "repositoryOwner"):
+			w.Write([]byte(testProjectJSON))
+		case strings.Contains(req.Query, "items(first"):
+			w.Write([]byte(testProjectItemsJSON))
+		case strings.Contains(req.Query, "... on ProjectV2Item"):
+			if req.Variables["id"] == "PVTI_draft" {
+				w.Write([]byte(`{"data":{"node":{"id":"PVTI_draft","type":"DRAFT_ISSUE","content":{"id":"DI_1","title":"Draft idea","body":"plan"}}}}`))
+				return
+			}
+			w.Write([]byte(`{"data":{"node":{"id":"PVTI_issue","type":"ISSUE","content":{"id":"I_1","number":12,"repository":{"nameWithOwner":"acme/api"}}}}}`))
+		case strings.HasPrefix(strings.TrimSpace(req.Query), "mutation"):
+			rec.mutations = append(rec.mutations, req.Variables)
+			w.Write([]byte(`{"data":{}}`))
+		default:
+			t.Errorf("unexpected query: %s", req.Query)
+		}
+	}))
+}
+
+func TestGitHubProjectSource_FetchReady(t *testing.T) {
+	srv := newProjectServer(t, &projectRecorder{})
+	defer srv.Close()
+
+	src := NewGitHubProjectSource(github.NewClient("tok", github.WithBaseURL(srv.URL)), project.ProjectBoard{Owner: "acme", Number: 3})
+	info, err := src.Init()
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if info.DisplayName != "Roadmap" {
+		t.Errorf("DisplayName = %q", info.DisplayName)
+	}
+
+	tasks, err := src.FetchReady()
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 ready tasks, got %+v", tasks)
+	}
+	issue, draft := tasks[0], tasks[1]
+	if issue.ID != "PVTI_issue" || issue.Priority != 1 || issue.Repo != "acme/api" || issue.Closes != "acme/api#12" ||
+		issue.BaseBranch != "develop" || issue.Milestone != "v2" || len(issue.Assignees) != 1 {
+		t.Errorf("unexpected issue task: %+v", issue)
+	}
+	if draft.ID != "PVTI_draft" || draft.Priority != 0 || draft.Repo != "" || draft.Closes != "" ||
+		draft.URL != "https://github.com/orgs/acme/projects/3" {
+		t.Errorf("unexpected draft task: %+v", draft)
+	}
+
+	// The configured field name matches the project's case-insensitively.
+	src = NewGitHubProjectSource(github.NewClient("tok", github.WithBaseURL(srv.URL)), project.ProjectBoard{Owner: "acme", Number: 3, PriorityField: "priority"})
+	if _, err := src.Init(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if tasks, _ := src.FetchReady(); len(tasks) != 2 || tasks[0].Priority != 1 || tasks[1].Priority != 0 {
+		t.Errorf("priorities with a lower-case field name: %+v", tasks)
+	}
+}
+
+func TestGitHubProjectSource_Mark(t *testing.T) {
+	rec := &projectRecorder{}
+	srv := newProjectServer(t, rec)
+	defer srv.Close()
+
+	src := NewGitHubProjectSource(github.NewClient("tok", github.WithBaseURL(srv.URL)), project.ProjectBoard{Owner: "acme", Number: 3})
+	if _, err := src.Init(); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if err := src.MarkInProgress("PVTI_issue"); err != nil {
+		t.Fatalf("in progress: %v", err)
+	}
+	if err := src.MarkDone("PVTI_issue", "PR opened"); err != nil {
+		t.Fatalf("done: %v", err)
+	}
+	if err := src.MarkFailed("PVTI_draft", "boom"); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if len(rec.mutations) != 4 {
+		t.Fatalf("expected 4 mutations, got %v", rec.mutations)
+	}
+	for i, want := range []string{"o_wip", "o_done", "o_failed"} {
+		if rec.mutations[i]["option"] != want || rec.mutations[i]["field"] != "F_status" {
+			t.Errorf("mutation %d = %v, want option %s", i, rec.mutations[i], want)
+		}
+	}
+	if body, _ := rec.mutations[3]["body"].(string); rec.mutations[3]["id"] != "DI_1" || !strings.HasSuffix(body, "boom") {
+		t.Errorf("draft update = %v", rec.mutations[3])
+	}
+	if len(rec.comments) != 1 || rec.comments[0] != "PR opened" {
+		t.Errorf("comments = %v", rec.comments)
+	}
+}
+
+func TestGitHubProjectSource_InitMissingOption(t *testing.T) {
+	srv := newProjectServer(t, &projectRecorder{})
+	defer srv.Close()
+
+	src := NewGitHubProjectSource(github.NewClient("tok", github.WithBaseURL(srv.URL)),
+		project.ProjectBoard{Owner: "acme", Number: 3, Ready: "Todo"})
+	if _, err := src.Init(); err == nil || !strings.Contains(err.Error(), `"Todo"`) {
+		t.Errorf("expected missing option error, got %v", err)
+	}
+}
+
+func TestPriorityFromFieldValue(t *testing.T) {
+	cases := map[string]int{"P0": 0, "p1 - high": 1, "1": 1, "0": 0, "": 2, "Urgent": 2, "7": 2}
+	for v, want := range cases {
+		if got := priorityFromFieldValue(v); got != want {
+			t.Errorf("priorityFromFieldValue(%q) = %d, want %d", v, got, want)
+		}
+	}
+}
@@ -1,126 +1,429 @@
 package taskrunner
 
 import (
-	"encoding/json"
 	"fmt"
-	"os/exec"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/siyuqian/devpilot/internal/github"
+	"github.com/siyuqian/devpilot/internal/project"
 )
 
 const (
 	ghLabelDevpilot   = "devpilot"
 	ghLabelInProgress = "in-progress"
 	ghLabelFailed     = "failed"
+	// ghLabelSlack marks issues filed from Slack threads. They lack the
+	// devpilot label, so they wait for someone to review and queue them.
+	ghLabelSlack = "slack"
 )
 
-// GitHubSource implements TaskSource using the gh CLI.
-// Authentication is handled by gh (run 'gh auth login' separately).
-type GitHubSource struct{}
+// GitHubSource implements TaskSource on GitHub Issues in one or more
+// repositories. With a single repository task IDs are plain issue numbers;
+// with several they are qualified as "owner/repo#42".
+type GitHubSource struct {
+	client *github.Client
+	repos  []string // owner/repo
+}
 
-func NewGitHubSource() *GitHubSource {
-	return &GitHubSource{}
+func NewGitHubSource(client *github.Client, repos ...string) *GitHubSource {
+	return &GitHubSource{client: client, repos: repos}
 }
 
 func (s *GitHubSource) Init() (SourceInfo, error) {
-	out, err := exec.Command("gh", "repo", "view", "--json", "nameWithOwner", "--jq", ".nameWithOwner").Output()
-	if err != nil {
-		return SourceInfo{}, fmt.Errorf("detect repo: %w (run 'gh auth login' if not authenticated)", err)
+	if len(s.repos) == 0 {
+		return SourceInfo{}, fmt.Errorf("no GitHub repository configured")
 	}
-	repo := strings.TrimSpace(string(out))
-	return SourceInfo{DisplayName: repo}, nil
-}
-
-type ghLabel struct {
-	Name string `json:"name"`
-}
-
-type ghIssue struct {
-	Number    int       `json:"number"`
-	Title     string    `json:"title"`
-	Body      string    `json:"body"`
-	URL       string    `json:"url"`
-	Labels    []ghLabel `json:"labels"`
-	CreatedAt time.Time `json:"createdAt"`
+	return SourceInfo{DisplayName: strings.Join(s.repos, ", ")}, nil
 }
 
 func (s *GitHubSource) FetchReady() ([]Task, error) {
-	// "sort:created-asc" asks the GitHub API to return issues oldest-first.
-	// Combined with the stable priority sort in SortByPriority, this gives a
-	// deterministic FIFO queue within each priority tier — without requiring
-	// any extra configuration from the user.
-	out, err := exec.Command("gh", "issue", "list",
-		"--label", ghLabelDevpilot,
-		"--state", "open",
-		"--search", "sort:created-asc",
-		"--json", "number,title,body,url,labels,createdAt",
-		"--limit", "25",
-	).Output()
-	if err != nil {
-		return nil, fmt.Errorf("gh issue list: %w", err)
-	}
-	var issues []ghIssue
-	if err := json.Unmarshal(out, &issues); err != nil {
-		return nil, fmt.Errorf("parse issues: %w", err)
+	// Oldest-first, combined with the stable priority sort in
+	// SortByPriority, gives a deterministic FIFO queue within each priority
+	// tier — without requiring any extra configuration from the user.
+	var tasks []Task
+	for _, repo := range s.repos {
+		issues, err := s.client.ListIssues(repo, github.IssueListOptions{
+			Labels:    []string{ghLabelDevpilot},
+			State:     "open",
+			Sort:      "created",
+			Direction: "asc",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list issues in %s: %w", repo, err)
+		}
+		for _, task := range issuesToReadyTasks(issues) {
+			task.Repo = repo
+			if len(s.repos) > 1 {
+				task.ID = repo + "#" + task.ID
+			}
+			tasks = append(tasks, task)
+		}
 	}
-	return issuesToReadyTasks(issues), nil
+	return tasks, nil
 }
 
 // issuesToReadyTasks filters out in-progress and failed issues, maps the rest to Tasks.
-func issuesToReadyTasks(issues []ghIssue) []Task {
+func issuesToReadyTasks(issues []github.Issue) []Task {
 	var tasks []Task
 	for _, issue := range issues {
 		if ghHasLabel(issue, ghLabelInProgress) || ghHasLabel(issue, ghLabelFailed) {
 			continue
 		}
+		labels := ghLabelNames(issue)
+		var assignees []string
+		for _, a := range issue.Assignees {
+			assignees = append(assignees, a.Login)
+		}
+		var milestone string
+		if issue.Milestone != nil {
+			milestone = issue.Milestone.Title
+		}
 		tasks = append(tasks, Task{
 			ID:          fmt.Sprintf("%d", issue.Number),
 			Name:        issue.Title,
 			Description: issue.Body,
-			URL:         issue.URL,
-			Priority:    ghPriority(issue),
+			URL:         issue.HTMLURL,
+			Priority:    priorityFromLabelNames(labels),
 			CreatedAt:   issue.CreatedAt.Unix(),
+			Labels:      labels,
+			BaseBranch:  baseFromLabelNames(labels),
+			Assignees:   assignees,
+			Milestone:   milestone,
+			Closes:      ghIssueRef(issue),
 		})
 	}
 	return tasks
 }
 
+// ghIssueRef returns a cross-repository reference like "owner/repo#42" for
+// issue, so "Closes" works even when PRs target a different repository.
+// It falls back to "#42" when the URL cannot be parsed.
+func ghIssueRef(issue github.Issue) string {
+	if repo, _, err := github.ParseIssueURL(issue.HTMLURL); err == nil {
+		return fmt.Sprintf("%s#%d", repo, issue.Number)
+	}
+	return fmt.Sprintf("#%d", issue.Number)
+}
+
 func (s *GitHubSource) MarkInProgress(id string) error {
-	_, err := exec.Command("gh", "issue", "edit", id, "--add-label", ghLabelInProgress).Output()
+	repo, number, err := s.issue(id)
 	if err != nil {
+		return err
+	}
+	if err := s.client.AddLabels(repo, number, ghLabelInProgress); err != nil {
 		return fmt.Errorf("add in-progress label to issue %s: %w", id, err)
 	}
 	return nil
 }
 
 func (s *GitHubSource) MarkDone(id, comment string) error {
-	_, err := exec.Command("gh", "issue", "close", id).Output()
+	repo, number, err := s.issue(id)
 	if err != nil {
+		return err
+	}
+	if err := s.client.CloseIssue(repo, number); err != nil {
 		return fmt.Errorf("close issue %s: %w", id, err)
 	}
-	return s.addComment(id, comment)
+	return s.addComment(repo, number, comment)
 }
 
 func (s *GitHubSource) MarkFailed(id, comment string) error {
-	_, err := exec.Command("gh", "issue", "edit", id,
-		"--remove-label", ghLabelInProgress,
-		"--add-label", ghLabelFailed,
-	).Output()
+	repo, number, err := s.issue(id)
 	if err != nil {
+		return err
+	}
+	if err := s.client.RemoveLabel(repo, number, ghLabelInProgress); err != nil {
+		return fmt.Errorf("update labels on issue %s: %w", id, err)
+	}
+	if err := s.client.AddLabels(repo, number, ghLabelFailed); err != nil {
 		return fmt.Errorf("update labels on issue %s: %w", id, err)
 	}
-	return s.addComment(id, comment)
+	return s.addComment(repo, number, comment)
 }
 
-func (s *GitHubSource) addComment(id, comment string) error {
-	_, err := exec.Command("gh", "issue", "comment", id, "--body", comment).Output()
+// Requeue removes the failed and in-progress labels so the issue is ready
+// again.
+func (s *GitHubSource) Requeue(id string) error {
+	repo, number, err := s.issue(id)
 	if err != nil {
-		return fmt.Errorf("add comment to issue %s: %w", id, err)
+		return err
+	}
+	for _, label := range []string{ghLabelFailed, ghLabelInProgress} {
+		if err := s.client.RemoveLabel(repo, number, label); err != nil {
+			return fmt.Errorf("update labels on issue %s: %w", id, err)
+		}
 	}
 	return nil
 }
 
-func ghHasLabel(issue ghIssue, name string) bool {
+// CreateTask opens an issue in the first configured repository, labelled
+// "slack" rather than "devpilot" so it is not queued until someone adds the
+// devpilot label.
+func (s *GitHubSource) CreateTask(title, plan string) (Task, error) {
+	if len(s.repos) == 0 {
+		return Task{}, fmt.Errorf("no GitHub repository configured")
+	}
+	repo := s.repos[0]
+	issue, err := s.client.CreateIssue(repo, github.NewIssue{Title: title, Body: plan, Labels: []string{ghLabelSlack}})
+	if err != nil {
+		return Task{}, fmt.Errorf("create issue in %s: %w", repo, err)
+	}
+	id := strconv.Itoa(issue.Number)
+	if len(s.repos) > 1 {
+		id = repo + "#" + id
+	}
+	return Task{ID: id, Name: issue.Title, Description: issue.Body, URL: issue.HTMLURL, Repo: repo}, nil
+}
+
+func (s *GitHubSource) addComment(repo string, number int, comment string) error {
+	if _, err := s.client.CreateComment(repo, number, comment); err != nil {
+		return fmt.Errorf("add comment to issue %s#%d: %w", repo, number, err)
+	}
+	return nil
+}
+
+func (s *GitHubSource) UpdatePlan(id, plan string) error {
+	repo, number, err := s.issue(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.client.EditIssue(repo, number, github.IssueEdit{Body: &plan}); err != nil {
+		return fmt.Errorf("update issue %s: %w", id, err)
+	}
+	return nil
+}
+
+func (s *GitHubSource) GatherContext(task Task, want project.ContextConfig) (*TaskContext, error) {
+	repo, number, err := s.issue(task.ID)
+	if err != nil {
+		return nil, err
+	}
+	return gatherIssueContext(s.client, repo, number, task.Description, want)
+}
+
+// maxLinkedIssues caps how many referenced issues are fetched for context.
+const maxLinkedIssues = 5
+
+var (
+	issueRefRe = regexp.MustCompile(`(?:^|[\s(])([\w.-]+/[\w.-]+)?#(\d+)\b`)
+	issueURLRe = regexp.MustCompile(`https?://[^\s)>\]]+/(?:issues|pull)/\d+`)
+	mdLinkRe   = regexp.MustCompile(`!?\[([^\]]*)\]\((https?://[^)\s]+)\)`)
+	imgTagRe   = regexp.MustCompile(`<img\s[^>]*>`)
+	imgAltRe   = regexp.MustCompile(`\balt="([^"]*)"`)
+	imgSrcRe   = regexp.MustCompile(`\bsrc="(https?://[^"]+)"`)
+)
+
+// gatherIssueContext collects comments, referenced issues and uploaded
+// attachments of an issue. GitHub task lists live in the issue body, which is
+// already the plan, so there are no separate checklists.
+func gatherIssueContext(client *github.Client, repo string, number int, body string, want project.ContextConfig) (*TaskContext, error) {
+	c := &TaskContext{}
+	texts := []string{body}
+	if want.Comments || want.Linked || want.Attachments {
+		comments, err := client.ListComments(repo, number)
+		if err != nil {
+			return nil, fmt.Errorf("list comments on %s#%d: %w", repo, number, err)
+		}
+		for _, cm := range comments {
+			if _, isLease := parseLease(cm.Body); isLease {
+				continue
+			}
+			texts = append(texts, cm.Body)
+			if want.Comments {
+				c.Comments = append(c.Comments, TaskComment{Author: cm.User.Login, Body: cm.Body, CreatedAt: cm.CreatedAt})
+			}
+		}
+	}
+	if want.Linked {
+		for _, ref := range issueRefs(repo, number, texts) {
+			issue, err := client.GetIssue(ref.repo, ref.number)
+			if err != nil {
+				continue // deleted, private or not an issue; skip it
+			}
+			c.Linked = append(c.Linked, LinkedIssue{
+				Ref:   fmt.Sprintf("%s#%d", ref.repo, ref.number),
+				Title: issue.Title,
+				State: issue.State,
+				Body:  issue.Body,
+			})
+		}
+	}
+	if want.Attachments {
+		for _, a := range issueAttachments(texts, client.IsAttachmentURL) {
+			url := a.URL
+			a.open = func() (io.ReadCloser, error) { return client.Download(url) }
+			c.Attachments = append(c.Attachments, a)
+		}
+	}
+	return c, nil
+}
+
+type issueRef struct {
+	repo   string
+	number int
+}
+
+// issueRefs finds "#12", "owner/repo#12" and issue URLs in texts, skipping
+// the issue itself and duplicates.
+func issueRefs(repo string, number int, texts []string) []issueRef {
+	seen := map[issueRef]bool{{repo, number}: true}
+	var refs []issueRef
+	add := func(r issueRef) {
+		if !seen[r] && len(refs) < maxLinkedIssues {
+			seen[r] = true
+			refs = append(refs, r)
+		}
+	}
+	for _, text := range texts {
+		for _, u := range issueURLRe.FindAllString(text, -1) {
+			if r, n, err := github.ParseIssueURL(u); err == nil {
+				add(issueRef{r, n})
+			}
+		}
+		for _, m := range issueRefRe.FindAllStringSubmatch(text, -1) {
+			n, _ := strconv.Atoi(m[2])
+			r := m[1]
+			if r == "" {
+				r = repo
+			}
+			add(issueRef{r, n})
+		}
+	}
+	return refs
+}
+
+// issueAttachments finds files uploaded to GitHub in markdown links and
+// image tags. isAttachment tells uploads from other links, which are
+// skipped.
+func issueAttachments(texts []string, isAttachment func(string) bool) []Attachment {
+	seen := map[string]bool{}
+	var out []Attachment
+	for _, text := range texts {
+		matches := mdLinkRe.FindAllStringSubmatch(text, -1)
+		for _, tag := range imgTagRe.FindAllString(text, -1) {
+			src := imgSrcRe.FindStringSubmatch(tag)
+			if src == nil {
+				continue
+			}
+			alt := imgAltRe.FindStringSubmatch(tag)
+			if alt == nil {
+				alt = []string{"", ""}
+			}
+			matches = append(matches, []string{tag, alt[1], src[1]})
+		}
+		for _, m := range matches {
+			name, u := m[1], m[2]
+			if !isAttachment(u) || seen[u] {
+				continue
+			}
+			seen[u] = true
+			if name == "" {
+				name = path.Base(u)
+			}
+			out = append(out, Attachment{Name: name, URL: u})
+		}
+	}
+	return out
+}
+
+func (s *GitHubSource) Claim(id string, lease Lease) (bool, error) {
+	t, err := s.thread(id)
+	if err != nil {
+		return false, err
+	}
+	return claimByComment(t, lease, time.Now())
+}
+
+func (s *GitHubSource) Renew(id string, lease Lease) error {
+	t, err := s.thread(id)
+	if err != nil {
+		return err
+	}
+	return renewByComment(t, lease, time.Now())
+}
+
+func (s *GitHubSource) Release(id, runnerID string) error {
+	t, err := s.thread(id)
+	if err != nil {
+		return err
+	}
+	return releaseByComment(t, runnerID)
+}
+
+func (s *GitHubSource) thread(id string) (commentThread, error) {
+	repo, number, err := s.issue(id)
+	if err != nil {
+		return nil, err
+	}
+	return ghCommentThread{client: s.client, repo: repo, number: number}, nil
+}
+
+// ghCommentThread is the comment thread of an issue, used for leases.
+type ghCommentThread struct {
+	client *github.Client
+	repo   string
+	number int
+}
+
+func (t ghCommentThread) List() ([]leaseComment, error) {
+	comments, err := t.client.ListComments(t.repo, t.number)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]leaseComment, len(comments))
+	for i, c := range comments {
+		out[i] = leaseComment{ID: strconv.FormatInt(c.ID, 10), Body: c.Body}
+	}
+	return out, nil
+}
+
+func (t ghCommentThread) Add(body string) (string, error) {
+	c, err := t.client.CreateComment(t.repo, t.number, body)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(c.ID, 10), nil
+}
+
+func (t ghCommentThread) Edit(commentID, body string) error {
+	id, err := strconv.ParseInt(commentID, 10, 64)
+	if err != nil {
+		return err
+	}
+	return t.client.UpdateComment(t.repo, id, body)
+}
+
+func (t ghCommentThread) Delete(commentID string) error {
+	id, err := strconv.ParseInt(commentID, 10, 64)
+	if err != nil {
+		return err
+	}
+	return t.client.DeleteComment(t.repo, id)
+}
+
+// issue resolves a task ID, either "42" or "owner/repo#42", to its
+// repository and issue number.
+func (s *GitHubSource) issue(id string) (string, int, error) {
+	repo, num, qualified := strings.Cut(id, "#")
+	if !qualified {
+		if len(s.repos) == 0 {
+			return "", 0, fmt.Errorf("no GitHub repository configured")
+		}
+		repo, num = s.repos[0], id
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid issue ID %q", id)
+	}
+	return repo, n, nil
+}
+
+func ghHasLabel(issue github.Issue, name string) bool {
 	for _, l := range issue.Labels {
 		if l.Name == name {
 			return true
@@ -129,10 +432,14 @@ func ghHasLabel(issue ghIssue, name string) bool {
 	return false
 }
 
-func ghPriority(issue ghIssue) int {
+func ghPriority(issue github.Issue) int {
+	return priorityFromLabelNames(ghLabelNames(issue))
+}
+
+func ghLabelNames(issue github.Issue) []string {
 	names := make([]string, len(issue.Labels))
 	for i, l := range issue.Labels {
 		names[i] = l.Name
 	}
-	return priorityFromLabelNames(names)
+	return names
 }
@@ -1,18 +1,27 @@
 package taskrunner
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/siyuqian/devpilot/internal/github"
+	"github.com/siyuqian/devpilot/internal/project"
 )
 
 func TestGitHubSource_FilterReady(t *testing.T) {
-	issues := []ghIssue{
-		{Number: 1, Title: "Ready task", Body: "Do this", URL: "https://github.com/o/r/issues/1",
-			Labels: []ghLabel{{Name: "devpilot"}}},
-		{Number: 2, Title: "In progress", URL: "https://github.com/o/r/issues/2",
-			Labels: []ghLabel{{Name: "devpilot"}, {Name: "in-progress"}}},
-		{Number: 3, Title: "Failed task", URL: "https://github.com/o/r/issues/3",
-			Labels: []ghLabel{{Name: "devpilot"}, {Name: "failed"}}},
+	issues := []github.Issue{
+		{Number: 1, Title: "Ready task", Body: "Do this", HTMLURL: "https://github.com/o/r/issues/1",
+			Labels: []github.Label{{Name: "devpilot"}}},
+		{Number: 2, Title: "In progress", HTMLURL: "https://github.com/o/r/issues/2",
+			Labels: []github.Label{{Name: "devpilot"}, {Name: "in-progress"}}},
+		{Number: 3, Title: "Failed task", HTMLURL: "https://github.com/o/r/issues/3",
+			Labels: []github.Label{{Name: "devpilot"}, {Name: "failed"}}},
 	}
 
 	tasks := issuesToReadyTasks(issues)
@@ -27,9 +36,9 @@ func TestGitHubSource_FilterReady(t *testing.T) {
 
 func TestGitHubSource_CreatedAtPropagated(t *testing.T) {
 	ts := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
-	issues := []ghIssue{
-		{Number: 42, Title: "My task", Body: "do it", URL: "https://github.com/o/r/issues/42",
-			Labels:    []ghLabel{{Name: "devpilot"}},
+	issues := []github.Issue{
+		{Number: 42, Title: "My task", Body: "do it", HTMLURL: "https://github.com/o/r/issues/42",
+			Labels:    []github.Label{{Name: "devpilot"}},
 			CreatedAt: ts,
 		},
 	}
@@ -44,18 +53,248 @@ func TestGitHubSource_CreatedAtPropagated(t *testing.T) {
 
 func TestGitHubPriority(t *testing.T) {
 	cases := []struct {
-		labels   []ghLabel
+		labels   []github.Label
 		expected int
 	}{
-		{[]ghLabel{{Name: "P0-critical"}, {Name: "devpilot"}}, 0},
-		{[]ghLabel{{Name: "p1-high"}, {Name: "devpilot"}}, 1},
-		{[]ghLabel{{Name: "devpilot"}}, 2},
+		{[]github.Label{{Name: "P0-critical"}, {Name: "devpilot"}}, 0},
+		{[]github.Label{{Name: "p1-high"}, {Name: "devpilot"}}, 1},
+		{[]github.Label{{Name: "devpilot"}}, 2},
 	}
 	for _, c := range cases {
-		issue := ghIssue{Labels: c.labels}
+		issue := github.Issue{Labels: c.labels}
 		got := ghPriority(issue)
 		if got != c.expected {
 			t.Errorf("labels %v: expected %d, got %d", c.labels, c.expected, got)
 		}
 	}
 }
+
+func TestGitHubSource_BaseBranchFromLabel(t *testing.T) {
+	issues := []github.Issue{
+		{Number: 7, Title: "Backport fix", HTMLURL: "https://github.com/o/r/issues/7",
+			Labels: []github.Label{{Name: "devpilot"}, {Name: "base:release-1.2"}}},
+	}
+	tasks := issuesToReadyTasks(issues)
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(tasks))
+	}
+	if tasks[0].BaseBranch != "release-1.2" {
+		t.Errorf("BaseBranch: got %q, want release-1.2", tasks[0].BaseBranch)
+	}
+	if len(tasks[0].Labels) != 2 {
+		t.Errorf("Labels: got %v, want 2 labels", tasks[0].Labels)
+	}
+}
+
+func TestGitHubSource_IssueMetadata(t *testing.T) {
+	issues := []github.Issue{
+		{Number: 42, Title: "Fix it", HTMLURL: "https://github.com/o/r/issues/42",
+			Labels:    []github.Label{{Name: "devpilot"}},
+			Assignees: []github.User{{Login: "alice"}, {Login: "bob"}},
+			Milestone: &github.Milestone{Title: "v1.0"}},
+	}
+	tasks := issuesToReadyTasks(issues)
+	if len(tasks) != 1 {
+		t.Fatalf("expected 1 task, got %d", len(tasks))
+	}
+	task := tasks[0]
+	if len(task.Assignees) != 2 || task.Assignees[0] != "alice" {
+		t.Errorf("Assignees: got %v", task.Assignees)
+	}
+	if task.Milestone != "v1.0" {
+		t.Errorf("Milestone: got %q", task.Milestone)
+	}
+	if task.Closes != "o/r#42" {
+		t.Errorf("Closes: got %q, want o/r#42", task.Closes)
+	}
+}
+
+func TestGitHubSource_FetchAndMark(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodGet {
+			q := r.URL.Query()
+			if q.Get("labels") != "devpilot" || q.Get("sort") != "created" || q.Get("direction") != "asc" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `[{"number":9,"title":"Task","body":"plan","html_url":"https://github.com/o/r/issues/9","labels":[{"name":"devpilot"}]}]`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer srv.Close()
+
+	s := NewGitHubSource(github.NewClient("t", github.WithBaseURL(srv.URL)), "o/r")
+	tasks, err := s.FetchReady()
+	if err != nil {
+		t.Fatalf("FetchReady: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "9" || tasks[0].Closes != "o/r#9" {
+		t.Fatalf("unexpected tasks: %+v", tasks)
+	}
+
+	requests = nil
+	if err := s.MarkFailed("9", "boom"); err != nil {
+		t.Fatalf("MarkFailed: %v", err)
+	}
+	want := []string{
+		"DELETE /repos/o/r/issues/9/labels/in-progress",
+		"POST /repos/o/r/issues/9/labels",
+		"POST /repos/o/r/issues/9/comments",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
+
+func TestGitHubSource_MultiRepo(t *testing.T) {
+	var marked []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/api/issues":
+			fmt.Fprint(w, `[{"number":1,"title":"API task","body":"plan","html_url":"https://github.com/acme/api/issues/1","labels":[{"name":"devpilot"}]}]`)
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/web/issues":
+			fmt.Fprint(w, `[{"number":1,"title":"Web task","body":"plan","html_url":"https://github.com/acme/web/issues/1","labels":[{"name":"devpilot"}]}]`)
+		default:
+			marked = append(marked, r.Method+" "+r.URL.Path)
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewGitHubSource(github.NewClient("t", github.WithBaseURL(srv.URL)), "acme/api", "acme/web")
+	info, _ := s.Init()
+	if info.DisplayName != "acme/api, acme/web" {
+		t.Errorf("DisplayName = %q", info.DisplayName)
+	}
+	tasks, err := s.FetchReady()
+	if err != nil {
+		t.Fatalf("FetchReady: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != "acme/api#1" || tasks[1].ID != "acme/web#1" {
+		t.Fatalf("expected repo-qualified IDs, got %+v", tasks)
+	}
+	if tasks[1].Repo != "acme/web" {
+		t.Errorf("Repo = %q, want acme/web", tasks[1].Repo)
+	}
+
+	if err := s.MarkInProgress("acme/web#1"); err != nil {
+		t.Fatalf("MarkInProgress: %v", err)
+	}
+	if len(marked) != 1 || marked[0] != "POST /repos/acme/web/issues/1/labels" {
+		t.Errorf("expected label on acme/web#1, got %v", marked)
+	}
+}
+
+func TestGitHubSource_Lease(t *testing.T) {
+	var comments []github.Comment
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/issues/9/comments":
+			json.NewEncoder(w).Encode(comments)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/issues/9/comments":
+			var in github.Comment
+			json.NewDecoder(r.Body).Decode(&in)
+			in.ID = int64(100 + len(comments))
+			comments = append(comments, in)
+			json.NewEncoder(w).Encode(in)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/repos/o/r/issues/comments/"):
+			comments = nil
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewGitHubSource(github.NewClient("t", github.WithBaseURL(srv.URL)), "o/r")
+	won, err := s.Claim("9", Lease{RunnerID: "gpu-1", Expires: time.Now().Add(time.Minute)})
+	if err != nil || !won {
+		t.Fatalf("Claim: won=%v err=%v", won, err)
+	}
+	if len(comments) != 1 || !strings.Contains(comments[0].Body, "runner=gpu-1") {
+		t.Fatalf("unexpected comments: %+v", comments)
+	}
+	if won, _ := s.Claim("9", Lease{RunnerID: "other", Expires: time.Now().Add(time.Minute)}); won {
+		t.Error("second runner claimed a leased issue")
+	}
+	if err := s.Release("9", "gpu-1"); err != nil || len(comments) != 0 {
+		t.Errorf("Release: err=%v comments=%v", err, comments)
+	}
+}
+
+func TestGitHubSource_GatherContext(t *testing.T) {
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/issues/9/comments":
+			fmt.Fprintf(w, `[
+			  {"id":1,"body":"See #4 and other/lib#2, screenshot: ![shot](%[1]s/user-attachments/assets/abc), log: [log](https://attacker.example/files/1/x)","user":{"login":"alice"}},
+			  {"id":2,"body":"🔒 devpilot lease: runner=x expires=2999-01-01T00:00:00Z","user":{"login":"bot"}},
+			  {"id":3,"body":"<img width=\"300\" alt=\"mock\" src=\"%[1]s/user-attachments/assets/def\">","user":{"login":"bob"}}
+			]`, srvURL)
+		case "/repos/o/r/issues/4":
+			fmt.Fprint(w, `{"number":4,"title":"Old bug","state":"closed","body":"repro"}`)
+		case "/repos/other/lib/issues/2":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		case "/user-attachments/assets/abc":
+			fmt.Fprint(w, "PNG")
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	s := NewGitHubSource(github.NewClient("t", github.WithBaseURL(srv.URL)), "o/r")
+	c, err := s.GatherContext(Task{ID: "9", Description: "Fixes #9"}, project.ContextConfig{Comments: true, Linked: true, Attachments: true})
+	if err != nil {
+		t.Fatalf("GatherContext: %v", err)
+	}
+	if len(c.Comments) != 2 || c.Comments[0].Author != "alice" {
+		t.Errorf("comments = %+v", c.Comments)
+	}
+	if len(c.Linked) != 1 || c.Linked[0].Ref != "o/r#4" || c.Linked[0].State != "closed" {
+		t.Errorf("linked = %+v", c.Linked)
+	}
+	// The off-host link is not fetched with the token: it is no attachment.
+	if len(c.Attachments) != 2 || c.Attachments[0].Name != "shot" || c.Attachments[1].Name != "mock" {
+		t.Fatalf("attachments = %+v", c.Attachments)
+	}
+	body, err := c.Attachments[0].open()
+	if err != nil {
+		t.Fatalf("open attachment: %v", err)
+	}
+	defer body.Close()
+	if data, _ := io.ReadAll(body); string(data) != "PNG" {
+		t.Errorf("attachment content = %q", data)
+	}
+}
+
+func TestGitHubSource_CreateTaskIsNotQueued(t *testing.T) {
+	var created struct {
+		Labels []string `json:"labels"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/acme/api/issues" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&created)
+		fmt.Fprint(w, `{"number":7,"title":"Speed up deploys","body":"plan","html_url":"https://github.com/acme/api/issues/7"}`)
+	}))
+	defer srv.Close()
+
+	s := NewGitHubSource(github.NewClient("t", github.WithBaseURL(srv.URL)), "acme/api")
+	task, err := s.CreateTask("Speed up deploys", "plan")
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.ID != "7" || task.URL != "https://github.com/acme/api/issues/7" {
+		t.Errorf("task = %+v", task)
+	}
+	if len(created.Labels) != 1 || created.Labels[0] != ghLabelSlack {
+		t.Errorf("labels = %v, want only %q so the runner leaves it alone", created.Labels, ghLabelSlack)
+	}
+}
@@ -0,0 +1,280 @@
+package taskrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultLeaseTTL is how long a claim on a task lasts without a heartbeat.
+const DefaultLeaseTTL = 10 * time.Minute
+
+// runnerLabelPrefix marks a task label that routes the task to runners
+// selecting it, e.g. "runner:gpu-free".
+const runnerLabelPrefix = "runner:"
+
+// errLeaseLost is returned when renewing a lease another runner now holds.
+var errLeaseLost = errors.New("lease held by another runner")
+
+// Lease is a runner's claim on a task.
+type Lease struct {
+	RunnerID string
+	Expires  time.Time
+}
+
+// Leaser is implemented by task sources that can claim tasks atomically, so
+// that several runners can share one board without picking up the same task.
+type Leaser interface {
+	// Claim tries to take the task for lease.RunnerID, reporting whether
+	// it won. Losing to another runner is not an error.
+	Claim(id string, lease Lease) (bool, error)
+	// Renew extends a lease held by lease.RunnerID.
+	Renew(id string, lease Lease) error
+	// Release drops every lease runnerID holds on the task.
+	Release(id, runnerID string) error
+}
+
+// DefaultRunnerID identifies this runner when none is configured.
+func DefaultRunnerID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "devpilot"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// matchesSelector reports whether a runner with selector may take task. The
+// task must carry every selector label, and a task routed with "runner:"
+// labels is only taken by runners that select one of them.
+func matchesSelector(task Task, selector []string) bool {
+	for _, want := range selector {
+		if !hasLabel(task.Labels, want) {
+			return false
+		}
+	}
+	routed := false
+	for _, l := range task.Labels {
+		if !strings.HasPrefix(strings.ToLower(l), runnerLabelPrefix) {
+			continue
+		}
+		if hasLabel(selector, l) {
+			return true
+		}
+		routed = true
+	}
+	return !routed
+}
+
+func hasLabel(labels []string, name string) bool {
+	for _, l := range labels {
+		if strings.EqualFold(l, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Leases are recorded as task comments so other runners (and people) can
+// see who holds a task. The oldest unexpired lease comment wins.
+
+var leasePattern = regexp.MustCompile(`devpilot lease: runner=(\S+) expires=(\S+)`)
+
+func (l Lease) comment() string {
+	return fmt.Sprintf("🔒 devpilot lease: runner=%s expires=%s", l.RunnerID, l.Expires.UTC().Format(time.RFC3339))
+}
+
+func parseLease(body string) (Lease, bool) {
+	m := leasePattern.FindStringSubmatch(body)
+	if m == nil {
+		return Lease{}, false
+	}
+	expires, err := time.Parse(time.RFC3339, m[2])
+	if err != nil {
+		return Lease{}, false
+	}
+	return Lease{RunnerID: m[1], Expires: expires}, true
+}
+
+type leaseComment struct {
+	ID   string
+	Body string
+}
+
+// commentThread is the comment thread of one task.
+type commentThread interface {
+	List() ([]leaseComment, error) // oldest first
+	Add(body string) (string, error)
+	Edit(commentID, body string) error
+	Delete(commentID string) error
+}
+
+// activeLease returns the oldest lease comment that has not expired.
+func activeLease(comments []leaseComment, now time.Time) (leaseComment, Lease, bool) {
+	for _, c := range comments {
+		if l, ok := parseLease(c.Body); ok && l.Expires.After(now) {
+			return c, l, true
+		}
+	}
+	return leaseComment{}, Lease{}, false
+}
+
+// claimByComment posts a lease comment and then reads the thread back: the
+// claim holds only if that comment is the oldest active lease, which makes a
+// race between two runners resolve the same way for both.
+func claimByComment(t commentThread, lease Lease, now time.Time) (bool, error) {
+	comments, err := t.List()
+	if err != nil {
+		return false, err
+	}
+	if c, held, ok := activeLease(comments, now); ok {
+		if held.RunnerID != lease.RunnerID {
+			return false, nil
+		}
+		return true, t.Edit(c.ID, lease.comment())
+	}
+	id, err := t.Add(lease.comment())
+	if err != nil {
+		return false, err
+	}
+	if comments, err = t.List(); err != nil {
+		return false, err
+	}
+	if c, _, ok := activeLease(comments, now); ok && c.ID == id {
+		return true, nil
+	}
+	// Another runner's lease landed first.
+	t.Delete(id)
+	return false, nil
+}
+
+func renewByComment(t commentThread, lease Lease, now time.Time) error {
+	comments, err := t.List()
+	if err != nil {
+		return err
+	}
+	if _, held, ok := activeLease(comments, now); ok && held.RunnerID != lease.RunnerID {
+		return fmt.Errorf("%w (%s)", errLeaseLost, held.RunnerID)
+	}
+	for i := len(comments) - 1; i >= 0; i-- {
+		if l, ok := parseLease(comments[i].Body); ok && l.RunnerID == lease.RunnerID {
+			return t.Edit(comments[i].ID, lease.comment())
+		}
+	}
+	_, err = t.Add(lease.comment())
+	return err
+}
+
+func releaseByComment(t commentThread, runnerID string) error {
+	comments, err := t.List()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, c := range comments {
+		if l, ok := parseLease(c.Body); ok && l.RunnerID == runnerID {
+			errs = append(errs, t.Delete(c.ID))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// claimNext returns the first task this runner is allowed to take and, when
+// the source supports leases, manages to claim.
+func (r *Runner) claimNext(tasks []Task) (Task, bool) {
+	leaser, ok := r.source.(Leaser)
+	for _, task := range tasks {
+		if !matchesSelector(task, r.config.Selector) || !r.approvedToStart(task) {
+			continue
+		}
+		if !ok || r.config.RunnerID == "" || r.config.DryRun {
+			return task, true
+		}
+		won, err := leaser.Claim(task.ID, r.lease())
+		if err != nil {
+			r.logger.Printf("Failed to claim %q: %v", task.Name, err)
+			continue
+		}
+		if !won {
+			r.logger.Printf("Task %q is claimed by another runner, skipping", task.Name)
+			continue
+		}
+		return task, true
+	}
+	return Task{}, false
+}
+
+func (r *Runner) leaseTTL() time.Duration {
+	if r.config.LeaseTTL > 0 {
+		return r.config.LeaseTTL
+	}
+	return DefaultLeaseTTL
+}
+
+func (r *Runner) lease() Lease {
+	return Lease{RunnerID: r.config.RunnerID, Expires: time.Now().Add(r.leaseTTL())}
+}
+
+// maxRenewFailures is how many renewals in a row may fail before the lease
+// is given up for lost: by then its TTL has run out and another runner may
+// have claimed the task.
+const maxRenewFailures = 3
+
+// holdLease renews the lease on a claimed task every third of its TTL until
+// the returned function is called, which stops the heartbeat and releases
+// the lease. The returned context is cancelled with errLeaseLost as its
+// cause when another runner takes the task over or renewal keeps failing,
+// so the task stops instead of being worked on twice.
+func (r *Runner) holdLease(ctx context.Context, id string) (context.Context, func()) {
+	leaser, ok := r.source.(Leaser)
+	if !ok || r.config.RunnerID == "" || r.config.DryRun {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(r.leaseTTL() / 3)
+		defer ticker.Stop()
+		failures := 0
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				err := leaser.Renew(id, r.lease())
+				if err == nil {
+					failures = 0
+					continue
+				}
+				r.logger.Printf("Failed to renew lease on %s: %v", id, err)
+				if failures++; errors.Is(err, errLeaseLost) || failures >= maxRenewFailures {
+					cancel(fmt.Errorf("%w: %v", errLeaseLost, err))
+					return
+				}
+			}
+		}
+	}()
+	return ctx, func() {
+		close(stop)
+		<-done
+		lost := leaseLost(ctx)
+		cancel(nil)
+		if lost {
+			return // the lease is no longer ours to release
+		}
+		if err := leaser.Release(id, r.config.RunnerID); err != nil {
+			r.logger.Printf("Failed to release lease on %s: %v", id, err)
+		}
+	}
+}
+
+// leaseLost reports whether ctx was cancelled because the task's lease was
+// lost. The task must then be left alone: another runner may be on it.
+func leaseLost(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errLeaseLost)
+}
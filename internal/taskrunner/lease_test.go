@@ -0,0 +1,247 @@
+package taskrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// memThread is an in-memory commentThread.
+type memThread struct {
+	comments []leaseComment
+	next     int
+}
+
+func (t *memThread) List() ([]leaseComment, error) {
+	return append([]leaseComment(nil), t.comments...), nil
+}
+
+func (t *memThread) Add(body string) (string, error) {
+	t.next++
+	id := fmt.Sprint(t.next)
+	t.comments = append(t.comments, leaseComment{ID: id, Body: body})
+	return id, nil
+}
+
+func (t *memThread) Edit(id, body string) error {
+	for i := range t.comments {
+		if t.comments[i].ID == id {
+			t.comments[i].Body = body
+			return nil
+		}
+	}
+	return errors.New("no such comment")
+}
+
+func (t *memThread) Delete(id string) error {
+	for i := range t.comments {
+		if t.comments[i].ID == id {
+			t.comments = append(t.comments[:i], t.comments[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("no such comment")
+}
+
+func TestLeaseComment_RoundTrip(t *testing.T) {
+	l := Lease{RunnerID: "gpu-1", Expires: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	got, ok := parseLease("some text\n" + l.comment())
+	if !ok || got.RunnerID != "gpu-1" || !got.Expires.Equal(l.Expires) {
+		t.Errorf("parseLease = %+v, %v", got, ok)
+	}
+	if _, ok := parseLease("✅ Task completed"); ok {
+		t.Error("parsed a lease from an unrelated comment")
+	}
+}
+
+func TestClaimByComment(t *testing.T) {
+	now := time.Now()
+	a := Lease{RunnerID: "a", Expires: now.Add(time.Minute)}
+	b := Lease{RunnerID: "b", Expires: now.Add(time.Minute)}
+
+	thread := &memThread{}
+	if won, err := claimByComment(thread, a, now); err != nil || !won {
+		t.Fatalf("first claim: won=%v err=%v", won, err)
+	}
+	if won, _ := claimByComment(thread, b, now); won {
+		t.Error("b claimed a task a holds")
+	}
+	if won, _ := claimByComment(thread, a, now); !won || len(thread.comments) != 1 {
+		t.Errorf("re-claim by holder: won=%v comments=%v", won, thread.comments)
+	}
+
+	// An expired lease does not block a new claim.
+	stale := &memThread{}
+	stale.Add(Lease{RunnerID: "a", Expires: now.Add(-time.Minute)}.comment())
+	if won, _ := claimByComment(stale, b, now); !won {
+		t.Error("expired lease blocked claim")
+	}
+}
+
+func TestClaimByComment_Race(t *testing.T) {
+	now := time.Now()
+	thread := &memThread{}
+	thread.Add(Lease{RunnerID: "a", Expires: now.Add(time.Minute)}.comment())
+
+	won, err := claimByComment(&racingThread{memThread: thread}, Lease{RunnerID: "b", Expires: now.Add(time.Minute)}, now)
+	if err != nil || won {
+		t.Fatalf("b should lose the race: won=%v err=%v", won, err)
+	}
+	if len(thread.comments) != 1 || !strings.Contains(thread.comments[0].Body, "runner=a") {
+		t.Errorf("loser's lease should be removed, got %v", thread.comments)
+	}
+}
+
+// racingThread hides every comment from the first List, as if another
+// runner's lease were posted right after it.
+type racingThread struct {
+	*memThread
+	listed bool
+}
+
+func (t *racingThread) List() ([]leaseComment, error) {
+	comments, err := t.memThread.List()
+	if !t.listed {
+		t.listed = true
+		return nil, err
+	}
+	return comments, err
+}
+
+func TestRenewAndRelease(t *testing.T) {
+	now := time.Now()
+	thread := &memThread{}
+	claimByComment(thread, Lease{RunnerID: "a", Expires: now.Add(time.Minute)}, now)
+
+	later := Lease{RunnerID: "a", Expires: now.Add(time.Hour)}
+	if err := renewByComment(thread, later, now); err != nil {
+		t.Fatalf("renew: %v", err)
+	}
+	if l, _ := parseLease(thread.comments[0].Body); !l.Expires.Equal(later.Expires.Truncate(time.Second)) {
+		t.Errorf("lease not extended: %v", thread.comments[0].Body)
+	}
+	if err := renewByComment(thread, Lease{RunnerID: "b", Expires: now.Add(time.Hour)}, now); !errors.Is(err, errLeaseLost) {
+		t.Errorf("renew by non-holder: %v", err)
+	}
+
+	thread.Add("unrelated comment")
+	if err := releaseByComment(thread, "a"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if len(thread.comments) != 1 || thread.comments[0].Body != "unrelated comment" {
+		t.Errorf("release left %v", thread.comments)
+	}
+}
+
+func TestMatchesSelector(t *testing.T) {
+	cases := []struct {
+		labels   []string
+		selector []string
+		want     bool
+	}{
+		{nil, nil, true},
+		{[]string{"area:frontend"}, nil, true},
+		{[]string{"runner:gpu-free"}, nil, false},
+		{[]string{"runner:gpu-free"}, []string{"runner:gpu-free"}, true},
+		{[]string{"Runner:GPU-free", "P1"}, []string{"runner:gpu-free"}, true},
+		{[]string{"area:frontend"}, []string{"area:frontend", "runner:gpu-free"}, false},
+		{[]string{"area:frontend"}, []string{"area:frontend"}, true},
+		{[]string{"runner:gpu-free", "runner:big"}, []string{"runner:big"}, true},
+	}
+	for _, c := range cases {
+		if got := matchesSelector(Task{Labels: c.labels}, c.selector); got != c.want {
+			t.Errorf("labels %v selector %v: got %v, want %v", c.labels, c.selector, got, c.want)
+		}
+	}
+}
+
+// leasingSource is a TaskSource whose tasks share one in-memory thread per ID.
+type leasingSource struct {
+	threads map[string]*memThread
+}
+
+func (s *leasingSource) Init() (SourceInfo, error)     { return SourceInfo{}, nil }
+func (s *leasingSource) FetchReady() ([]Task, error)   { return nil, nil }
+func (s *leasingSource) MarkInProgress(string) error   { return nil }
+func (s *leasingSource) MarkDone(string, string) error { return nil }
+func (s *leasingSource) MarkFailed(string, string) error {
+	return nil
+}
+
+func (s *leasingSource) thread(id string) *memThread {
+	if s.threads[id] == nil {
+		s.threads[id] = &memThread{}
+	}
+	return s.threads[id]
+}
+
+func (s *leasingSource) Claim(id string, l Lease) (bool, error) {
+	return claimByComment(s.thread(id), l, time.Now())
+}
+
+func (s *leasingSource) Renew(id string, l Lease) error {
+	return renewByComment(s.thread(id), l, time.Now())
+}
+
+func (s *leasingSource) Release(id, runnerID string) error {
+	return releaseByComment(s.thread(id), runnerID)
+}
+
+func TestClaimNext(t *testing.T) {
+	src := &leasingSource{threads: map[string]*memThread{}}
+	src.thread("1").Add(Lease{RunnerID: "other", Expires: time.Now().Add(time.Hour)}.comment())
+	r := &Runner{
+		config: Config{RunnerID: "me", Selector: []string{"area:api"}},
+		source: src,
+		logger: log.New(io.Discard, "", 0),
+	}
+	tasks := []Task{
+		{ID: "1", Name: "claimed elsewhere", Labels: []string{"area:api"}},
+		{ID: "2", Name: "not selected", Labels: []string{"area:web"}},
+		{ID: "3", Name: "mine", Labels: []string{"area:api"}},
+	}
+	task, ok := r.claimNext(tasks)
+	if !ok || task.ID != "3" {
+		t.Fatalf("claimNext = %+v, %v", task, ok)
+	}
+
+	_, release := r.holdLease(context.Background(), task.ID)
+	if len(src.thread("3").comments) != 1 {
+		t.Fatalf("expected a lease comment, got %v", src.thread("3").comments)
+	}
+	release()
+	if len(src.thread("3").comments) != 0 {
+		t.Errorf("lease not released: %v", src.thread("3").comments)
+	}
+}
+
+func TestHoldLease_LostLeaseStopsTask(t *testing.T) {
+	src := &leasingSource{threads: map[string]*memThread{}}
+	r := &Runner{
+		config: Config{RunnerID: "me", LeaseTTL: 30 * time.Millisecond},
+		source: src,
+		logger: log.New(io.Discard, "", 0),
+	}
+	// Another runner has taken the task over, e.g. after this one's lease
+	// lapsed during a network partition.
+	src.thread("1").Add(Lease{RunnerID: "other", Expires: time.Now().Add(time.Hour)}.comment())
+	ctx, release := r.holdLease(context.Background(), "1")
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("task context not cancelled after losing the lease")
+	}
+	if !leaseLost(ctx) {
+		t.Errorf("cause = %v, want errLeaseLost", context.Cause(ctx))
+	}
+	release()
+	if c := src.thread("1").comments; len(c) != 1 {
+		t.Errorf("the other runner's lease was released: %v", c)
+	}
+}
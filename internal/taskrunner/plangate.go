@@ -0,0 +1,77 @@
+package taskrunner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/plan"
+)
+
+// Plan gates checked before a task is executed.
+const (
+	PlanGateLint   = "lint"   // fail tasks whose plan has lint errors
+	PlanGateRefine = "refine" // rewrite such plans with Claude, failing only if that does not help
+)
+
+// WithPlanRefiner sets the function used to rewrite plans under
+// PlanGateRefine. Without one, the refine gate behaves like the lint gate.
+func WithPlanRefiner(fn plan.Generator) RunnerOption {
+	return func(r *Runner) {
+		r.refine = fn
+	}
+}
+
+// planGate lints the task's plan and, under PlanGateRefine, refines a
+// failing one and writes it back to the source. It reports whether the task
+// may run; otherwise it has already marked the task failed or flagged it for
+// clarification. task.Description is updated to the refined plan. Paths the
+// plan names are checked against git's checkout.
+func (r *Runner) planGate(ctx context.Context, task *Task, git *GitOps, start time.Time) bool {
+	if r.config.PlanGate == "" {
+		return true
+	}
+	opts := plan.Options{Title: task.Name, Dir: git.dir}
+	result := plan.Lint(task.Description, opts)
+	if !result.HasErrors() {
+		return true
+	}
+	if r.config.PlanGate != PlanGateRefine || r.refine == nil {
+		r.failCard(ctx, *task, start, "plan failed lint:\n"+result.String())
+		return false
+	}
+
+	r.logger.Printf("Plan for %q failed lint, refining...", task.Name)
+	ref, err := plan.Refine(ctx, r.refine, task.Description, result, opts)
+	if err != nil {
+		r.failCard(ctx, *task, start, fmt.Sprintf("refine plan: %v", err))
+		return false
+	}
+	if ref.NeedsClarification() {
+		r.flagClarification(*task, start, ref.Questions)
+		return false
+	}
+	if again := plan.Lint(ref.Plan, opts); again.HasErrors() {
+		r.failCard(ctx, *task, start, "refined plan still fails lint:\n"+again.String())
+		return false
+	}
+	if w, ok := r.source.(PlanWriter); ok {
+		if err := w.UpdatePlan(task.ID, ref.Plan); err != nil {
+			r.logger.Printf("Failed to write refined plan back: %v", err)
+		}
+	}
+	task.Description = ref.Plan
+	return true
+}
+
+// flagClarification fails the task with the refiner's questions, so it
+// leaves the queue until someone answers them and moves it back to Ready.
+func (r *Runner) flagClarification(task Task, start time.Time, questions []string) {
+	duration := time.Since(start).Round(time.Second)
+	errMsg := "plan needs clarification"
+	r.emit(CardFailedEvent{CardID: task.ID, CardName: task.Name, ErrMsg: errMsg, Duration: duration})
+	comment := "❓ Needs clarification before devpilot can run this task:\n- " + strings.Join(questions, "\n- ")
+	r.source.MarkFailed(task.ID, comment)
+	r.logger.Printf("Card %q needs clarification", task.Name)
+}
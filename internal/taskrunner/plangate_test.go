@@ -0,0 +1,91 @@
+package taskrunner
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// planSource records what the plan gate does to a task.
+type planSource struct {
+	failed  string
+	updated string
+}
+
+func (s *planSource) Init() (SourceInfo, error)     { return SourceInfo{}, nil }
+func (s *planSource) FetchReady() ([]Task, error)   { return nil, nil }
+func (s *planSource) MarkInProgress(string) error   { return nil }
+func (s *planSource) MarkDone(string, string) error { return nil }
+func (s *planSource) MarkFailed(_, comment string) error {
+	s.failed = comment
+	return nil
+}
+func (s *planSource) UpdatePlan(_, plan string) error {
+	s.updated = plan
+	return nil
+}
+
+const refinedPlan = "# Speed up login\n\n" +
+	"Login takes three seconds because every request reloads the session from the database.\n\n" +
+	"## Steps\n\n1. Cache session lookups in memory for one minute.\n2. Invalidate the cache on logout.\n\n" +
+	"## Acceptance Criteria\n\n- Login completes in under 500ms locally.\n"
+
+func newPlanRunner(gate string, src TaskSource, refine func(context.Context, string) (string, error)) *Runner {
+	return &Runner{
+		config: Config{PlanGate: gate, WorkDir: "."},
+		source: src,
+		logger: log.New(io.Discard, "", 0),
+		refine: refine,
+	}
+}
+
+func TestPlanGate_Lint(t *testing.T) {
+	src := &planSource{}
+	r := newPlanRunner(PlanGateLint, src, nil)
+	task := Task{ID: "1", Name: "Speed up login", Description: "make login faster"}
+	if r.planGate(context.Background(), &task, NewGitOps("."), time.Now()) {
+		t.Fatal("vague plan passed the lint gate")
+	}
+	if !strings.Contains(src.failed, "plan failed lint") || !strings.Contains(src.failed, "[steps]") {
+		t.Errorf("failure comment = %q", src.failed)
+	}
+
+	task.Description = refinedPlan
+	if !r.planGate(context.Background(), &task, NewGitOps("."), time.Now()) {
+		t.Error("good plan rejected")
+	}
+}
+
+func TestPlanGate_Refine(t *testing.T) {
+	src := &planSource{}
+	r := newPlanRunner(PlanGateRefine, src, func(context.Context, string) (string, error) {
+		return refinedPlan, nil
+	})
+	task := Task{ID: "1", Name: "Speed up login", Description: "make login faster"}
+	if !r.planGate(context.Background(), &task, NewGitOps("."), time.Now()) {
+		t.Fatalf("refined plan rejected: %q", src.failed)
+	}
+	if task.Description != strings.TrimSpace(refinedPlan) || src.updated != task.Description {
+		t.Errorf("refined plan not applied: description=%q updated=%q", task.Description, src.updated)
+	}
+}
+
+func TestPlanGate_NeedsClarification(t *testing.T) {
+	src := &planSource{}
+	r := newPlanRunner(PlanGateRefine, src, func(context.Context, string) (string, error) {
+		return "NEEDS CLARIFICATION\n- Web or CLI login?", nil
+	})
+	task := Task{ID: "1", Name: "Speed up login", Description: "make login faster"}
+	if r.planGate(context.Background(), &task, NewGitOps("."), time.Now()) {
+		t.Fatal("task needing clarification passed the gate")
+	}
+	if !strings.HasPrefix(src.failed, "❓ Needs clarification") || !strings.Contains(src.failed, "Web or CLI login?") {
+		t.Errorf("clarification comment = %q", src.failed)
+	}
+	if src.updated != "" {
+		t.Error("plan written back despite needing clarification")
+	}
+}
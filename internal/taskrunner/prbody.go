@@ -0,0 +1,236 @@
+package taskrunner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxPlanSummaryChars = 600
+	maxSummaryDiffChars = 15000
+	maxTestOutputChars  = 1500
+	summaryTimeout      = 2 * time.Minute
+)
+
+var prTemplateFuncs = template.FuncMap{"tokens": formatTokens}
+
+var changeSummaryTmpl = template.Must(template.ParseFS(templatesFS, "templates/change_summary.tmpl"))
+
+// testCommandRe matches shell commands that run a test suite.
+var testCommandRe = regexp.MustCompile(`\b(go test|npm (run )?test|yarn test|pnpm test|pytest|cargo test|make test|mvn test|gradle test)\b`)
+
+// Summarizer turns a prompt into text, e.g. by calling claude.
+type Summarizer func(ctx context.Context, prompt string) (string, error)
+
+// PRBodyData is the data available to PR body templates.
+type PRBodyData struct {
+	Task          Task
+	Branch        string
+	Base          string
+	Plan          string // first paragraph of the task plan
+	ChangeSummary string // AI-written summary of the diff; empty if unavailable
+	FilesRead     []string
+	FilesEdited   []string
+	TestCommand   string // last test command claude ran; empty if none
+	TestOutput    string // tail of that command's output
+	InputTokens   int
+	OutputTokens  int
+	Turns         int
+	Duration      time.Duration
+}
+
+// taskStats accumulates what happened during a task's execution from the
+// runner's own event stream.
+type taskStats struct {
+	filesRead    []string
+	filesEdited  []string
+	inputTokens  int
+	outputTokens int
+	turns        int
+	lastBash     string
+	testCommand  string
+	testOutput   string
+}
+
+func (s *taskStats) observe(e Event) {
+	switch ev := e.(type) {
+	case CardStartedEvent:
+		*s = taskStats{}
+	case ToolStartEvent:
+		if fp := extractFilePath(ev.Input); fp != "" {
+			switch ev.ToolName {
+			case "Read", "Grep", "Glob":
+				s.filesRead = addUnique(s.filesRead, fp)
+			case "Edit", "Write":
+				s.filesEdited = addUnique(s.filesEdited, fp)
+			}
+		}
+		if ev.ToolName == "Bash" {
+			s.lastBash, _ = ev.Input["command"].(string)
+		}
+	case ToolResultEvent:
+		if ev.ToolName == "Bash" && testCommandRe.MatchString(s.lastBash) {
+			s.testCommand = s.lastBash
+			s.testOutput = tail(ev.Output, maxTestOutputChars)
+		}
+	case StatsUpdateEvent:
+		s.inputTokens += ev.InputTokens
+		s.outputTokens += ev.OutputTokens
+		if ev.Turns > 0 {
+			s.turns = ev.Turns
+		}
+	}
+}
+
+// prBody renders the PR description for task on branch. It falls back to a
+// minimal body if the template cannot be loaded or rendered.
+func (r *Runner) prBody(ctx context.Context, task Task, git *GitOps, branch string, start time.Time) string {
+	data := PRBodyData{
+		Task:         task,
+		Branch:       branch,
+		Base:         git.Base(),
+		Plan:         planSummary(task.Description),
+		FilesRead:    relPaths(git.dir, r.stats.filesRead),
+		FilesEdited:  relPaths(git.dir, r.stats.filesEdited),
+		TestCommand:  r.stats.testCommand,
+		TestOutput:   r.stats.testOutput,
+		InputTokens:  r.stats.inputTokens,
+		OutputTokens: r.stats.outputTokens,
+		Turns:        r.stats.turns,
+		Duration:     time.Since(start).Round(time.Second),
+	}
+	data.ChangeSummary = r.changeSummary(ctx, task, git, branch)
+
+	tmpl, err := loadPRTemplate(r.projectDir, r.config.PRTemplate)
+	if err != nil {
+		r.logger.Printf("PR template: %v; using default body", err)
+		return defaultPRBody(task)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		r.logger.Printf("Render PR template: %v; using default body", err)
+		return defaultPRBody(task)
+	}
+	return withCloses(buf.String(), task)
+}
+
+// withCloses appends a "Closes" line for the task's issue when the rendered
+// body doesn't already reference it, so custom templates can't silently
+// drop issue auto-closing.
+func withCloses(body string, task Task) string {
+	if task.Closes == "" || strings.Contains(body, "Closes "+task.Closes) {
+		return body
+	}
+	return strings.TrimRight(body, "\n") + "\n\nCloses " + task.Closes + "\n"
+}
+
+// changeSummary asks the summarizer to describe the diff between the base
+// branch and branch. Failures are logged and yield an empty summary.
+func (r *Runner) changeSummary(ctx context.Context, task Task, git *GitOps, branch string) string {
+	if r.summarize == nil {
+		return ""
+	}
+	diffStat, err := git.DiffStat(branch)
+	if err != nil {
+		r.logger.Printf("Diff stat for PR summary: %v", err)
+		return ""
+	}
+	diff, err := git.Diff(branch)
+	if err != nil {
+		r.logger.Printf("Diff for PR summary: %v", err)
+		return ""
+	}
+	var buf bytes.Buffer
+	err = changeSummaryTmpl.Execute(&buf, map[string]string{
+		"TaskName": task.Name,
+		"DiffStat": diffStat,
+		"Diff":     truncate(diff, maxSummaryDiffChars),
+	})
+	if err != nil {
+		return ""
+	}
+
+	summaryCtx, cancel := context.WithTimeout(ctx, summaryTimeout)
+	defer cancel()
+	summary, err := r.summarize(summaryCtx, buf.String())
+	if err != nil {
+		r.logger.Printf("Generate PR change summary: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(summary)
+}
+
+// loadPRTemplate parses the project's PR body template at path (relative to
+// workDir), or the embedded default when path is empty.
+func loadPRTemplate(workDir, path string) (*template.Template, error) {
+	if path == "" {
+		return template.New("pr_body.tmpl").Funcs(prTemplateFuncs).ParseFS(templatesFS, "templates/pr_body.tmpl")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return template.New(filepath.Base(path)).Funcs(prTemplateFuncs).Parse(string(data))
+}
+
+func defaultPRBody(task Task) string {
+	return withCloses(fmt.Sprintf("## Task\n%s\n\n🤖 Executed by devpilot runner", task.URL), task)
+}
+
+// planSummary returns the first prose paragraph of a plan, skipping headings,
+// capped at maxPlanSummaryChars.
+func planSummary(plan string) string {
+	var para []string
+	for _, line := range strings.Split(plan, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") || line == "---" {
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		if line == "" {
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		para = append(para, line)
+	}
+	return truncate(strings.Join(para, "\n"), maxPlanSummaryChars)
+}
+
+// relPaths makes absolute paths relative to the checkout dir for display.
+func relPaths(dir string, paths []string) []string {
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if rel, err := filepath.Rel(dir, p); err == nil && !strings.HasPrefix(rel, "..") {
+			p = rel
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+func tail(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= max {
+		return s
+	}
+	cut := len(s) - max
+	for cut < len(s) && !utf8.RuneStart(s[cut]) {
+		cut++
+	}
+	return "..." + s[cut:]
+}
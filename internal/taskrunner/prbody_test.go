@@ -0,0 +1,186 @@
+package taskrunner
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestPlanSummary(t *testing.T) {
+	plan := "# Add auth\n\n## Goal\nAdd login\nwith sessions.\n\n## Steps\n1. Do it"
+	if got := planSummary(plan); got != "Add login\nwith sessions." {
+		t.Errorf("planSummary = %q", got)
+	}
+	if got := planSummary(""); got != "" {
+		t.Errorf("planSummary(\"\") = %q, want empty", got)
+	}
+}
+
+func TestTail_KeepsRunesWhole(t *testing.T) {
+	if got := tail("✓ ok\n✗ fail", 8); got != "...✗ fail" {
+		t.Errorf("tail(8) = %q, want %q", got, "...✗ fail")
+	}
+	if got := tail("✓ ok\n✗ fail", 7); got != "... fail" {
+		t.Errorf("tail(7) = %q, want %q", got, "... fail")
+	}
+	if !utf8.ValidString(tail(strings.Repeat("é", 10), 5)) {
+		t.Error("tail() split a rune")
+	}
+}
+
+func TestTaskStats_Observe(t *testing.T) {
+	var s taskStats
+	s.observe(ToolStartEvent{ToolName: "Read", Input: map[string]any{"file_path": "/repo/a.go"}})
+	s.observe(ToolStartEvent{ToolName: "Edit", Input: map[string]any{"file_path": "/repo/b.go"}})
+	s.observe(ToolStartEvent{ToolName: "Edit", Input: map[string]any{"file_path": "/repo/b.go"}})
+	s.observe(ToolStartEvent{ToolName: "Bash", Input: map[string]any{"command": "go test ./..."}})
+	s.observe(ToolResultEvent{ToolName: "Bash", Output: "ok  \tpkg\t0.1s"})
+	s.observe(ToolStartEvent{ToolName: "Bash", Input: map[string]any{"command": "git status"}})
+	s.observe(ToolResultEvent{ToolName: "Bash", Output: "clean"})
+	s.observe(StatsUpdateEvent{InputTokens: 100, OutputTokens: 50})
+	s.observe(StatsUpdateEvent{InputTokens: 10, OutputTokens: 5, Turns: 3})
+
+	if len(s.filesRead) != 1 || len(s.filesEdited) != 1 {
+		t.Errorf("files: read=%v edited=%v", s.filesRead, s.filesEdited)
+	}
+	if s.testCommand != "go test ./..." || !strings.Contains(s.testOutput, "ok") {
+		t.Errorf("test run not captured: %q / %q", s.testCommand, s.testOutput)
+	}
+	if s.inputTokens != 110 || s.outputTokens != 55 || s.turns != 3 {
+		t.Errorf("tokens: %+v", s)
+	}
+
+	s.observe(CardStartedEvent{CardID: "c2"})
+	if len(s.filesEdited) != 0 || s.inputTokens != 0 {
+		t.Errorf("CardStartedEvent should reset stats, got %+v", s)
+	}
+}
+
+func TestLoadPRTemplate_Default(t *testing.T) {
+	tmpl, err := loadPRTemplate(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var sb strings.Builder
+	err = tmpl.Execute(&sb, PRBodyData{
+		Task:          Task{Name: "Add auth", URL: "https://trello.com/c/abc"},
+		Plan:          "Add login.",
+		ChangeSummary: "- Added login handler",
+		FilesEdited:   []string{"auth.go"},
+		TestCommand:   "go test ./...",
+		TestOutput:    "ok",
+		InputTokens:   12300,
+		OutputTokens:  450,
+		Turns:         7,
+		Duration:      3 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	body := sb.String()
+	for _, want := range []string{
+		"[Add auth](https://trello.com/c/abc)",
+		"## Plan\nAdd login.",
+		"## Changes\n- Added login handler",
+		"- `auth.go`",
+		"`go test ./...`",
+		"↑12k ↓450 tokens · 7 turns",
+		"Executed by devpilot runner",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "Files read") {
+		t.Errorf("body should omit empty files-read section:\n%s", body)
+	}
+}
+
+func TestLoadPRTemplate_Override(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".devpilot"), 0755)
+	os.WriteFile(filepath.Join(dir, ".devpilot", "pr.tmpl"), []byte("Custom: {{.Task.Name}} ({{tokens .InputTokens}})"), 0644)
+
+	tmpl, err := loadPRTemplate(dir, ".devpilot/pr.tmpl")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var sb strings.Builder
+	tmpl.Execute(&sb, PRBodyData{Task: Task{Name: "X"}, InputTokens: 2000})
+	if sb.String() != "Custom: X (2.0k)" {
+		t.Errorf("got %q", sb.String())
+	}
+
+	if _, err := loadPRTemplate(dir, "missing.tmpl"); err == nil {
+		t.Error("expected error for missing template")
+	}
+}
+
+func TestPRBody_WithChangeSummary(t *testing.T) {
+	dir := setupGitRepo(t)
+	git := NewGitOps(dir)
+	git.CreateBranch("task/1-feature")
+	os.WriteFile(filepath.Join(dir, "feature.go"), []byte("package main\n"), 0644)
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", "add feature"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s %v", args, out, err)
+		}
+	}
+
+	var gotPrompt string
+	r := &Runner{
+		config: Config{WorkDir: dir},
+		logger: log.New(io.Discard, "", 0),
+		summarize: func(ctx context.Context, prompt string) (string, error) {
+			gotPrompt = prompt
+			return "- Added feature.go", nil
+		},
+	}
+	r.stats.observe(ToolStartEvent{ToolName: "Write", Input: map[string]any{"file_path": filepath.Join(dir, "feature.go")}})
+
+	body := r.prBody(context.Background(), Task{Name: "Feature", Description: "Build it."}, git, "task/1-feature", time.Now())
+
+	if !strings.Contains(gotPrompt, "feature.go") {
+		t.Errorf("summary prompt should include the diff, got:\n%s", gotPrompt)
+	}
+	if !strings.Contains(body, "- Added feature.go") {
+		t.Errorf("body missing change summary:\n%s", body)
+	}
+	if !strings.Contains(body, "- `feature.go`") {
+		t.Errorf("body should list edited files relative to the work dir:\n%s", body)
+	}
+}
+
+func TestPRBody_FallsBackOnBadTemplate(t *testing.T) {
+	r := &Runner{
+		config: Config{WorkDir: t.TempDir(), PRTemplate: "missing.tmpl"},
+		logger: log.New(io.Discard, "", 0),
+	}
+	task := Task{Name: "X", URL: "https://example.com/1"}
+	body := r.prBody(context.Background(), task, NewGitOps(r.config.WorkDir), "task/x", time.Now())
+	if body != defaultPRBody(task) {
+		t.Errorf("expected default body, got %q", body)
+	}
+}
+
+func TestWithCloses(t *testing.T) {
+	task := Task{Closes: "o/r#42"}
+	if got := withCloses("body\n", task); got != "body\n\nCloses o/r#42\n" {
+		t.Errorf("withCloses = %q", got)
+	}
+	if got := withCloses("Closes o/r#42\n", task); got != "Closes o/r#42\n" {
+		t.Errorf("should not duplicate an existing Closes line, got %q", got)
+	}
+	if got := withCloses("body", Task{}); got != "body" {
+		t.Errorf("should leave body untouched without Closes, got %q", got)
+	}
+}
@@ -0,0 +1,39 @@
+package taskrunner
+
+// prMetadata maps task metadata onto PR labels, reviewers, assignees and
+// milestone according to the configured PR rules.
+func (r *Runner) prMetadata(task Task, git *GitOps, branch string) PRMetadata {
+	rules := r.config.PRRules
+	m := PRMetadata{
+		Assignees: task.Assignees,
+		Milestone: task.Milestone,
+	}
+	for _, l := range task.Labels {
+		if mapped := rules.PRLabelFor(l); mapped != "" {
+			m.Labels = addUnique(m.Labels, mapped)
+		}
+	}
+	for _, rv := range rules.Reviewers {
+		m.Reviewers = addUnique(m.Reviewers, rv)
+	}
+	if rules.CodeOwners {
+		if owners := loadCodeOwners(git.dir); owners != nil {
+			files, err := git.ChangedFiles(branch)
+			if err != nil {
+				r.logger.Printf("List changed files for CODEOWNERS: %v", err)
+			}
+			for _, o := range codeOwnersFor(owners, files) {
+				m.Reviewers = addUnique(m.Reviewers, o)
+			}
+		}
+	}
+	return m
+}
+
+// applyPRMetadata carries task metadata over to the PR. Failures (e.g. a
+// label missing from the repository) are logged but never fail the task.
+func (r *Runner) applyPRMetadata(task Task, git *GitOps, branch, prURL string) {
+	if err := git.ApplyPRMetadata(prURL, r.prMetadata(task, git, branch)); err != nil {
+		r.logger.Printf("Failed to apply labels/reviewers to PR %s: %v", prURL, err)
+	}
+}
@@ -0,0 +1,92 @@
+package taskrunner
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/siyuqian/devpilot/internal/github"
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+func TestApplyPRMetadata(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/repos/o/r/milestones":
+			fmt.Fprint(w, `[{"number":3,"title":"v1"}]`)
+		case "/repos/o/r/issues/1/labels":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message":"Validation Failed"}`)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer srv.Close()
+
+	git := NewGitOps("/tmp", WithGitHub(github.NewClient("t", github.WithBaseURL(srv.URL))))
+	m := PRMetadata{Labels: []string{"bug"}, Reviewers: []string{"alice"}, Assignees: []string{"bob"}, Milestone: "v1"}
+	err := git.ApplyPRMetadata("https://github.com/o/r/pull/1", m)
+	if err == nil || !strings.Contains(err.Error(), "add labels") {
+		t.Errorf("expected the label failure to be reported, got %v", err)
+	}
+	want := []string{
+		"POST /repos/o/r/issues/1/labels",
+		"POST /repos/o/r/pulls/1/requested_reviewers",
+		"POST /repos/o/r/issues/1/assignees",
+		"GET /repos/o/r/milestones",
+		"PATCH /repos/o/r/issues/1",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("requests = %v\nwant %v (a failed step must not stop the rest)", paths, want)
+	}
+
+	if err := NewGitOps("/tmp").ApplyPRMetadata("https://github.com/o/r/pull/1", PRMetadata{}); err != nil {
+		t.Errorf("empty metadata should be a no-op, got %v", err)
+	}
+}
+
+func TestRunner_PRMetadata(t *testing.T) {
+	dir := setupGitRepo(t)
+	git := NewGitOps(dir)
+	os.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte("*.go @gopher\n/docs/ @writer\n"), 0o644)
+	git.CreateBranch("task/1-x")
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644)
+	for _, args := range [][]string{{"add", "main.go"}, {"commit", "-m", "add main"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %s %v", args, out, err)
+		}
+	}
+
+	r := &Runner{
+		config: Config{WorkDir: dir, PRRules: project.PRRules{
+			Labels:     map[string]string{"P1-high": "priority:high", "bug": "bug"},
+			Reviewers:  []string{"lead"},
+			CodeOwners: true,
+		}},
+		logger: log.New(io.Discard, "", 0),
+	}
+	task := Task{Labels: []string{"p1-HIGH", "devpilot"}, Assignees: []string{"bob"}, Milestone: "v2"}
+	m := r.prMetadata(task, git, "task/1-x")
+
+	if !reflect.DeepEqual(m.Labels, []string{"priority:high"}) {
+		t.Errorf("Labels = %v", m.Labels)
+	}
+	if !reflect.DeepEqual(m.Reviewers, []string{"lead", "gopher"}) {
+		t.Errorf("Reviewers = %v, want configured reviewer plus CODEOWNERS", m.Reviewers)
+	}
+	if !reflect.DeepEqual(m.Assignees, []string{"bob"}) || m.Milestone != "v2" {
+		t.Errorf("Assignees/Milestone = %v/%q", m.Assignees, m.Milestone)
+	}
+}
@@ -0,0 +1,147 @@
+package taskrunner
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+// Prompt templates. Each is embedded under templates/prompts/ and can be
+// overridden by a file of the same name in .devpilot/prompts/.
+const (
+	PromptExecute   = "execute.tmpl"    // runs a task plan
+	PromptOpenSpec  = "openspec.tmpl"   // runs an OpenSpec change
+	PromptStackStep = "stack_step.tmpl" // runs one task group of a stacked OpenSpec change
+	PromptReview    = "review.tmpl"     // reviews a PR
+	PromptFix       = "fix.tmpl"        // addresses review comments on a PR
+
+	PromptPanelReview = "panel_review.tmpl" // one reviewer of a review panel
+)
+
+// promptOverrideDir holds project prompt overrides, relative to the project
+// directory.
+const promptOverrideDir = ".devpilot/prompts"
+
+// PromptData is the data available to prompt templates.
+type PromptData struct {
+	Task    Task
+	Context string // comments, attachments and linked issues, already size-limited
+	Resumed bool   // the branch holds commits from an earlier attempt
+	Repo    RepoInfo
+	Config  Config          // runner configuration
+	Project *project.Config // .devpilot.yaml; nil when not loaded
+	PRURL   string          // set for review and fix prompts
+	Step    *StackStep      // set for stack step prompts
+
+	Reviewer *project.PanelReviewer // set for panel review prompts
+	Review   string                 // findings of the panel reviewers that rejected the PR, for the fix prompt
+}
+
+// RepoInfo describes the checkout a prompt runs in.
+type RepoInfo struct {
+	Name   string // owner/repo; empty when it cannot be determined
+	Dir    string
+	Base   string
+	Branch string
+}
+
+// StackStep identifies one step of a stacked change.
+type StackStep struct {
+	Number int // 1-based
+	Total  int
+	Group  string
+}
+
+// loadPromptTemplate parses the override for name in projectDir, or the
+// embedded default when there is none.
+func loadPromptTemplate(projectDir, name string) (*template.Template, error) {
+	if projectDir != "" {
+		path := filepath.Join(projectDir, promptOverrideDir, name)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return template.New(name).Parse(string(data))
+		}
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+	}
+	return template.ParseFS(templatesFS, "templates/prompts/"+name)
+}
+
+func renderPrompt(projectDir, name string, data PromptData) (string, error) {
+	tmpl, err := loadPromptTemplate(projectDir, name)
+	if err != nil {
+		return "", err
+	}
+	return execPrompt(tmpl, name, data)
+}
+
+// renderPromptFile renders the template at path.
+func renderPromptFile(path string, data PromptData) (string, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", path, err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Parse(string(text))
+	if err != nil {
+		return "", err
+	}
+	return execPrompt(tmpl, path, data)
+}
+
+func execPrompt(tmpl *template.Template, name string, data PromptData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render %s: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// defaultPrompt renders an embedded prompt template, which cannot fail for
+// well-formed data.
+func defaultPrompt(name string, data PromptData) string {
+	prompt, err := renderPrompt("", name, data)
+	if err != nil {
+		panic(err)
+	}
+	return prompt
+}
+
+// prompt renders name with the project's override, falling back to the
+// embedded template if the override is broken.
+func (r *Runner) prompt(name string, data PromptData) string {
+	prompt, err := renderPrompt(r.projectDir, name, data)
+	if err != nil {
+		r.logger.Printf("Prompt template %s: %v; using default", name, err)
+		return defaultPrompt(name, data)
+	}
+	return prompt
+}
+
+// promptData returns the template data for task running on branch in git's
+// checkout.
+func (r *Runner) promptData(task Task, git *GitOps, branch string) PromptData {
+	dir := r.config.WorkDir
+	if git != nil {
+		dir = git.dir
+	}
+	data := PromptData{
+		Task:    task,
+		Context: formatContext(task.Context, dir, r.config.Context.MaxPromptBytes),
+		Config:  r.config,
+		Project: r.config.Project,
+		Repo:    RepoInfo{Name: task.Repo, Dir: dir, Branch: branch},
+	}
+	if git != nil {
+		data.Repo.Base = git.Base()
+		if data.Repo.Name == "" {
+			data.Repo.Name, _ = git.prRepo()
+		}
+	}
+	return data
+}
@@ -0,0 +1,63 @@
+package taskrunner
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+func TestDefaultExecutePrompt(t *testing.T) {
+	r := &Runner{}
+	prompt := r.buildPrompt(Task{Name: "add-auth", Description: "the plan"}, nil, "", true)
+	if strings.Contains(prompt, "/superpowers:") {
+		t.Errorf("default prompt should not require optional skills:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "Task: add-auth") || !strings.Contains(prompt, "earlier attempt") {
+		t.Errorf("prompt missing task or resume note:\n%s", prompt)
+	}
+}
+
+func TestPromptOverride(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".devpilot", "prompts"), 0755)
+	override := "Use /superpowers:tdd. {{.Task.Name}} in {{.Repo.Name}} on {{.Repo.Branch}} ({{.Project.BaseBranch}})"
+	os.WriteFile(filepath.Join(dir, ".devpilot", "prompts", PromptExecute), []byte(override), 0644)
+	os.WriteFile(filepath.Join(dir, ".devpilot", "prompts", PromptReview), []byte("Review {{.PRURL}} for {{.Task.Name"), 0644)
+
+	r := &Runner{
+		projectDir: dir,
+		config:     Config{Project: &project.Config{BaseBranch: "develop"}},
+		logger:     log.New(io.Discard, "", 0),
+	}
+	task := Task{Name: "add-auth", Repo: "acme/api"}
+	got := r.buildPrompt(task, nil, "task/1-add-auth", false)
+	if want := "Use /superpowers:tdd. add-auth in acme/api on task/1-add-auth (develop)"; got != want {
+		t.Errorf("prompt = %q, want %q", got, want)
+	}
+
+	// A broken override falls back to the embedded template.
+	data := r.promptData(task, nil, "")
+	data.PRURL = "https://github.com/o/r/pull/1"
+	if got := r.prompt(PromptReview, data); got != ReviewPrompt(data.PRURL) {
+		t.Errorf("broken override should fall back, got %q", got)
+	}
+}
+
+func TestReviewer_WithPrompts(t *testing.T) {
+	var rendered string
+	rv := NewReviewer(WithCommand("echo")).withPrompts(func(name, prURL string) string {
+		rendered = name + " " + prURL
+		return rendered
+	})
+	if _, err := rv.InDir(t.TempDir()).Fix(t.Context(), "https://github.com/o/r/pull/1"); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+	if want := PromptFix + " https://github.com/o/r/pull/1"; rendered != want {
+		t.Errorf("rendered = %q, want %q", rendered, want)
+	}
+}
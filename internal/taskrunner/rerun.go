@@ -0,0 +1,49 @@
+package taskrunner
+
+import "fmt"
+
+// prepareBranch checks out the task branch. Branch names are deterministic,
+// so a re-run may find the branch left by an earlier attempt. With
+// BranchPolicyRebase that branch is kept and rebased onto the base branch;
+// otherwise, or when the rebase conflicts, it is reset to the base branch.
+// It reports whether earlier work was kept.
+func (r *Runner) prepareBranch(git *GitOps, branch string) (bool, error) {
+	if r.config.BranchPolicy == BranchPolicyRebase && (git.BranchExists(branch) || git.FetchBranch(branch)) {
+		err := git.RebaseBranch(branch)
+		if err == nil {
+			r.logger.Printf("Continuing existing branch %s, rebased onto %s", branch, git.Base())
+			return true, nil
+		}
+		r.logger.Printf("Rebase of existing branch %s failed, resetting it: %v", branch, err)
+		if err := git.CheckoutBase(); err != nil {
+			return false, fmt.Errorf("checkout %s: %w", git.Base(), err)
+		}
+	}
+	return false, git.CreateBranch(branch)
+}
+
+// pushBranch pushes the task branch, force-pushing when an earlier run left
+// a copy on the remote that the reset or rebased branch no longer matches.
+func (r *Runner) pushBranch(git *GitOps, branch string) error {
+	if git.FetchBranch(branch) {
+		return git.ForcePush(branch)
+	}
+	return git.Push(branch)
+}
+
+// openPR creates a PR for branch, or updates the open PR an earlier run
+// created for it instead of failing because one already exists.
+func (r *Runner) openPR(git *GitOps, branch, title, body string) (string, error) {
+	existing, err := git.FindPR(branch)
+	if err != nil {
+		r.logger.Printf("Look up existing PR for %s: %v", branch, err)
+	}
+	if existing == "" {
+		return git.CreatePR(branch, title, body)
+	}
+	if err := git.UpdatePR(existing, title, body); err != nil {
+		return "", fmt.Errorf("update existing PR %s: %w", existing, err)
+	}
+	r.logger.Printf("Reusing existing PR: %s", existing)
+	return existing, nil
+}
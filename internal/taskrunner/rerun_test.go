@@ -0,0 +1,149 @@
+package taskrunner
+
+import (
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupGitRepoWithRemote returns a repo whose origin is a local bare repo
+// that already has the base branch pushed.
+func setupGitRepoWithRemote(t *testing.T) string {
+	t.Helper()
+	dir := setupGitRepo(t)
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	gitRun(t, "", "init", "--bare", remote)
+	gitRun(t, dir, "remote", "add", "origin", remote)
+	gitRun(t, dir, "push", "origin", "HEAD")
+	return dir
+}
+
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %s %v", args, out, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func commitFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+	gitRun(t, dir, "add", name)
+	gitRun(t, dir, "commit", "-m", "add "+name)
+}
+
+func TestPrepareBranch_Reset(t *testing.T) {
+	dir := setupGitRepoWithRemote(t)
+	git := NewGitOps(dir)
+	git.CreateBranch("task/1-x")
+	commitFile(t, dir, "old.txt", "earlier attempt")
+	git.CheckoutBase()
+
+	r := &Runner{config: Config{WorkDir: dir}, logger: log.New(io.Discard, "", 0)}
+	resumed, err := r.prepareBranch(git, "task/1-x")
+	if err != nil {
+		t.Fatalf("prepareBranch: %v", err)
+	}
+	if resumed {
+		t.Error("reset policy should not resume the earlier branch")
+	}
+	if has, _ := git.HasNewCommits("task/1-x"); has {
+		t.Error("reset branch should have no commits beyond the base")
+	}
+}
+
+func TestPrepareBranch_Rebase(t *testing.T) {
+	dir := setupGitRepoWithRemote(t)
+	git := NewGitOps(dir)
+	base := git.Base()
+	git.CreateBranch("task/1-x")
+	commitFile(t, dir, "old.txt", "earlier attempt")
+	git.CheckoutBase()
+	commitFile(t, dir, "upstream.txt", "new on base")
+
+	r := &Runner{config: Config{WorkDir: dir, BranchPolicy: BranchPolicyRebase}, logger: log.New(io.Discard, "", 0)}
+	resumed, err := r.prepareBranch(git, "task/1-x")
+	if err != nil {
+		t.Fatalf("prepareBranch: %v", err)
+	}
+	if !resumed {
+		t.Fatal("rebase policy should resume the earlier branch")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.txt")); err != nil {
+		t.Error("earlier commit should be kept")
+	}
+	// The branch must now contain the latest base commit.
+	gitRun(t, dir, "merge-base", "--is-ancestor", base, "task/1-x")
+}
+
+func TestPrepareBranch_RebaseConflictFallsBackToReset(t *testing.T) {
+	dir := setupGitRepoWithRemote(t)
+	git := NewGitOps(dir)
+	git.CreateBranch("task/1-x")
+	commitFile(t, dir, "f.txt", "branch")
+	git.CheckoutBase()
+	commitFile(t, dir, "f.txt", "base")
+
+	r := &Runner{config: Config{WorkDir: dir, BranchPolicy: BranchPolicyRebase}, logger: log.New(io.Discard, "", 0)}
+	resumed, err := r.prepareBranch(git, "task/1-x")
+	if err != nil {
+		t.Fatalf("prepareBranch: %v", err)
+	}
+	if resumed {
+		t.Error("conflicting rebase should fall back to a reset branch")
+	}
+	if has, _ := git.HasNewCommits("task/1-x"); has {
+		t.Error("reset branch should have no commits beyond the base")
+	}
+}
+
+func TestPrepareBranch_RebaseFromRemoteOnly(t *testing.T) {
+	dir := setupGitRepoWithRemote(t)
+	git := NewGitOps(dir)
+	git.CreateBranch("task/1-x")
+	commitFile(t, dir, "old.txt", "pushed attempt")
+	git.Push("task/1-x")
+	git.CheckoutBase()
+	gitRun(t, dir, "branch", "-D", "task/1-x")
+
+	r := &Runner{config: Config{WorkDir: dir, BranchPolicy: BranchPolicyRebase}, logger: log.New(io.Discard, "", 0)}
+	resumed, err := r.prepareBranch(git, "task/1-x")
+	if err != nil || !resumed {
+		t.Fatalf("expected to resume from the remote branch, got resumed=%v err=%v", resumed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.txt")); err != nil {
+		t.Error("commit from the remote branch should be checked out")
+	}
+}
+
+func TestPushBranch_OverwritesEarlierRun(t *testing.T) {
+	dir := setupGitRepoWithRemote(t)
+	git := NewGitOps(dir)
+	r := &Runner{config: Config{WorkDir: dir}, logger: log.New(io.Discard, "", 0)}
+
+	git.CreateBranch("task/1-x")
+	commitFile(t, dir, "a.txt", "first run")
+	if err := r.pushBranch(git, "task/1-x"); err != nil {
+		t.Fatalf("first push: %v", err)
+	}
+
+	git.CheckoutBase()
+	git.CreateBranch("task/1-x") // reset, diverging from the remote copy
+	commitFile(t, dir, "b.txt", "second run")
+	if err := r.pushBranch(git, "task/1-x"); err != nil {
+		t.Fatalf("second push should force over the earlier run: %v", err)
+	}
+	local := gitRun(t, dir, "rev-parse", "task/1-x")
+	remote := gitRun(t, dir, "ls-remote", "origin", "refs/heads/task/1-x")
+	if !strings.HasPrefix(remote, local) {
+		t.Errorf("remote branch = %q, want %s", remote, local)
+	}
+}
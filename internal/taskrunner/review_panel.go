@@ -0,0 +1,199 @@
+package taskrunner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+// Review panel policies: how many reviewers must approve a PR. A rejection
+// by a reviewer with veto fails the review under either policy.
+const (
+	PanelPolicyAll      = "all"
+	PanelPolicyMajority = "majority"
+)
+
+// maxFindingBytes caps each reviewer's findings in the posted review.
+const maxFindingBytes = 8000
+
+// PanelVerdict is one panel reviewer's verdict on a PR.
+type PanelVerdict struct {
+	Reviewer string
+	Veto     bool
+	Approved bool
+	Findings string // the reviewer's report without the verdict line
+	ExitCode int
+	Err      error // the reviewer did not finish; counts as not approving
+}
+
+var verdictRe = regexp.MustCompile(`(?m)^[\s*_#>]*VERDICT:\s*\**\s*(APPROVE|REQUEST[_ ]CHANGES)\b.*$`)
+
+// parseVerdict reads a panel reviewer's report. The last VERDICT line
+// decides; a report without one approves only if it says "No issues found",
+// as the single code review does.
+func parseVerdict(report string) (approved bool, findings string) {
+	matches := verdictRe.FindAllStringSubmatchIndex(report, -1)
+	if len(matches) == 0 {
+		return IsApproved(report), strings.TrimSpace(report)
+	}
+	last := matches[len(matches)-1]
+	approved = report[last[2]:last[3]] == "APPROVE"
+	findings = strings.TrimSpace(report[:last[0]] + report[last[1]:])
+	return approved, findings
+}
+
+// panelApproves combines verdicts under policy.
+func panelApproves(policy string, verdicts []PanelVerdict) bool {
+	approvals := 0
+	for _, v := range verdicts {
+		if v.Approved {
+			approvals++
+		} else if v.Veto {
+			return false
+		}
+	}
+	if policy == PanelPolicyMajority {
+		return approvals*2 > len(verdicts)
+	}
+	return approvals == len(verdicts)
+}
+
+// runPanel runs every panel reviewer on prURL concurrently and posts their
+// verdicts as one PR review. It returns whether the panel approves and the
+// findings of the reviewers that did not, for the fix prompt.
+func (r *Runner) runPanel(ctx context.Context, task Task, git *GitOps, branch, prURL string) (bool, string) {
+	panel := r.config.ReviewPanel
+	data := r.promptData(task, git, branch)
+	data.PRURL = prURL
+
+	verdicts := make([]PanelVerdict, len(panel.Reviewers))
+	var wg sync.WaitGroup
+	for i, member := range panel.Reviewers {
+		r.emit(ReviewStartedEvent{PRURL: prURL, Reviewer: member.Name})
+		prompt := r.panelPrompt(member, data)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			verdicts[i] = r.panelReview(ctx, git.dir, member, prompt)
+		}()
+	}
+	wg.Wait()
+
+	for _, v := range verdicts {
+		exitCode := v.ExitCode
+		if v.Err != nil {
+			r.logger.Printf("%s review error: %v", v.Reviewer, v.Err)
+			exitCode = -1
+		}
+		r.emit(ReviewDoneEvent{PRURL: prURL, Reviewer: v.Reviewer, ExitCode: exitCode, Approved: v.Approved})
+	}
+
+	approved := panelApproves(panel.Policy, verdicts)
+	if err := git.PostReview(prURL, formatPanelReview(panel.Policy, approved, verdicts)); err != nil {
+		r.logger.Printf("Failed to post panel review on %s: %v", prURL, err)
+	}
+	return approved, panelFindings(verdicts)
+}
+
+// panelReview runs one panel reviewer in dir and reads its verdict.
+func (r *Runner) panelReview(ctx context.Context, dir string, member project.PanelReviewer, prompt string) PanelVerdict {
+	v := PanelVerdict{Reviewer: member.Name, Veto: member.Veto}
+	model := member.Model
+	if model == "" && r.config.Project != nil {
+		model = r.config.Project.ModelFor("review")
+	}
+	reviewCtx, cancel := context.WithTimeout(ctx, r.config.ReviewTimeout)
+	defer cancel()
+	result, err := r.reviewer.InDir(dir).withModel(model).run(reviewCtx, prompt)
+	if err != nil {
+		v.Err = err
+		return v
+	}
+	v.ExitCode = result.ExitCode
+	if result.ExitCode != 0 {
+		v.Err = fmt.Errorf("exit code %d", result.ExitCode)
+		return v
+	}
+	v.Approved, v.Findings = parseVerdict(AssistantText(result.Stdout))
+	return v
+}
+
+// panelPrompt renders the prompt for a panel reviewer: its own template when
+// it names one, otherwise the panel review prompt.
+func (r *Runner) panelPrompt(member project.PanelReviewer, data PromptData) string {
+	data.Reviewer = &member
+	if member.Prompt != "" {
+		path := member.Prompt
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.projectDir, path)
+		}
+		prompt, err := renderPromptFile(path, data)
+		if err == nil {
+			return prompt
+		}
+		r.logger.Printf("Prompt template for %s reviewer: %v; using default", member.Name, err)
+	}
+	return r.prompt(PromptPanelReview, data)
+}
+
+// formatPanelReview renders the panel's verdicts as a PR review body.
+func formatPanelReview(policy string, approved bool, verdicts []PanelVerdict) string {
+	if policy == "" {
+		policy = PanelPolicyAll
+	}
+	var b strings.Builder
+	outcome := "✅ approved"
+	if !approved {
+		outcome = "❌ changes requested"
+	}
+	fmt.Fprintf(&b, "## devpilot review panel: %s\n\nPolicy: %s must approve; a rejection by a veto reviewer always blocks.\n\n", outcome, policy)
+	b.WriteString("| Reviewer | Verdict |\n|---|---|\n")
+	for _, v := range verdicts {
+		name := v.Reviewer
+		if v.Veto {
+			name += " (veto)"
+		}
+		fmt.Fprintf(&b, "| %s | %s |\n", name, verdictLabel(v))
+	}
+	for _, v := range verdicts {
+		if v.Findings == "" && v.Err == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", v.Reviewer)
+		if v.Err != nil {
+			fmt.Fprintf(&b, "Review did not finish: %v\n", v.Err)
+			continue
+		}
+		b.WriteString(clip(v.Findings, maxFindingBytes))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func verdictLabel(v PanelVerdict) string {
+	switch {
+	case v.Err != nil:
+		return "⚠️ error"
+	case v.Approved:
+		return "✅ approve"
+	default:
+		return "❌ request changes"
+	}
+}
+
+// panelFindings joins the findings of the reviewers that did not approve.
+func panelFindings(verdicts []PanelVerdict) string {
+	var parts []string
+	for _, v := range verdicts {
+		if v.Approved || v.Findings == "" {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s reviewer:\n%s", v.Reviewer, clip(v.Findings, maxFindingBytes)))
+	}
+	return strings.Join(parts, "\n\n")
+}
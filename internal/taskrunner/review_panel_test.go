@@ -0,0 +1,154 @@
+package taskrunner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/github"
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+func TestParseVerdict(t *testing.T) {
+	tests := []struct {
+		name     string
+		report   string
+		approved bool
+		findings string
+	}{
+		{"approve", "Looks fine.\nVERDICT: APPROVE", true, "Looks fine."},
+		{"request changes", "1. SQL injection in db.go:12\n\nVERDICT: REQUEST_CHANGES", false, "1. SQL injection in db.go:12"},
+		{"markdown", "Nit only.\n**VERDICT: APPROVE**", true, "Nit only."},
+		{"last wins", "VERDICT: APPROVE\nOn second look, a bug.\nVERDICT: REQUEST CHANGES", false, "VERDICT: APPROVE\nOn second look, a bug."},
+		{"legacy approval", "No issues found.", true, "No issues found."},
+		{"no verdict", "Something is off.", false, "Something is off."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approved, findings := parseVerdict(tt.report)
+			if approved != tt.approved || findings != tt.findings {
+				t.Errorf("parseVerdict = (%v, %q), want (%v, %q)", approved, findings, tt.approved, tt.findings)
+			}
+		})
+	}
+}
+
+func TestPanelApproves(t *testing.T) {
+	yes := PanelVerdict{Approved: true}
+	no := PanelVerdict{}
+	veto := PanelVerdict{Veto: true}
+	tests := []struct {
+		name     string
+		policy   string
+		verdicts []PanelVerdict
+		want     bool
+	}{
+		{"all approve", PanelPolicyAll, []PanelVerdict{yes, yes}, true},
+		{"all with one rejection", PanelPolicyAll, []PanelVerdict{yes, no}, false},
+		{"default policy is all", "", []PanelVerdict{yes, no}, false},
+		{"majority", PanelPolicyMajority, []PanelVerdict{yes, yes, no}, true},
+		{"majority tie", PanelPolicyMajority, []PanelVerdict{yes, no}, false},
+		{"veto overrides majority", PanelPolicyMajority, []PanelVerdict{yes, yes, veto}, false},
+		{"errored reviewer does not approve", PanelPolicyAll, []PanelVerdict{yes, {Err: context.DeadlineExceeded}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := panelApproves(tt.policy, tt.verdicts); got != tt.want {
+				t.Errorf("panelApproves = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPanel_PostsReview(t *testing.T) {
+	var posted github.NewReview
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/o/r/pulls/3/reviews" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&posted)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer srv.Close()
+
+	var events []Event
+	r := &Runner{
+		config: Config{
+			ReviewTimeout: time.Minute,
+			ReviewPanel: project.ReviewPanel{
+				Policy: PanelPolicyMajority,
+				Reviewers: []project.PanelReviewer{
+					{Name: "security", Veto: true},
+					{Name: "style"},
+					{Name: "plan"},
+				},
+			},
+		},
+		reviewer:     NewReviewer(WithCommand("printf", "Unescaped query in db.go:12\nVERDICT: REQUEST_CHANGES\n")),
+		logger:       log.New(io.Discard, "", 0),
+		eventHandler: func(e Event) { events = append(events, e) },
+	}
+	git := NewGitOps(t.TempDir(), WithGitHub(github.NewClient("t", github.WithBaseURL(srv.URL))))
+
+	approved, findings := r.runPanel(context.Background(), Task{Name: "add-auth"}, git, "task/1-add-auth", "https://github.com/o/r/pull/3")
+	if approved {
+		t.Error("panel should reject")
+	}
+	if !strings.Contains(findings, "security reviewer:\nUnescaped query in db.go:12") || strings.Contains(findings, "VERDICT") {
+		t.Errorf("unexpected findings:\n%s", findings)
+	}
+	if posted.Event != github.ReviewComment {
+		t.Errorf("review event = %q, want COMMENT", posted.Event)
+	}
+	for _, want := range []string{"changes requested", "majority", "| security (veto) | ❌ request changes |", "### style"} {
+		if !strings.Contains(posted.Body, want) {
+			t.Errorf("review body missing %q:\n%s", want, posted.Body)
+		}
+	}
+	if len(events) != 6 {
+		t.Fatalf("got %d events, want a start and done per reviewer", len(events))
+	}
+	if done, ok := events[5].(ReviewDoneEvent); !ok || done.Reviewer != "plan" || done.Approved {
+		t.Errorf("unexpected last event: %#v", events[5])
+	}
+}
+
+func TestPanelPrompt(t *testing.T) {
+	dir := t.TempDir()
+	r := &Runner{projectDir: dir, logger: log.New(io.Discard, "", 0)}
+	data := PromptData{Task: Task{Name: "add-auth", Description: "Add login"}, PRURL: "https://github.com/o/r/pull/3"}
+
+	prompt := r.panelPrompt(project.PanelReviewer{Name: "security", Focus: "secrets in logs"}, data)
+	for _, want := range []string{"security reviewer", "secrets in logs", "Add login", "VERDICT: APPROVE"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("panel prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, "style.tmpl"), []byte("Style-check {{.PRURL}} as {{.Reviewer.Name}}"), 0644)
+	prompt = r.panelPrompt(project.PanelReviewer{Name: "style", Prompt: "style.tmpl"}, data)
+	if prompt != "Style-check https://github.com/o/r/pull/3 as style" {
+		t.Errorf("custom prompt = %q", prompt)
+	}
+
+	// A missing template falls back to the panel prompt.
+	prompt = r.panelPrompt(project.PanelReviewer{Name: "perf", Prompt: "missing.tmpl"}, data)
+	if !strings.Contains(prompt, "perf reviewer") {
+		t.Errorf("fallback prompt = %q", prompt)
+	}
+}
+
+func TestFixPrompt_IncludesPanelFindings(t *testing.T) {
+	prompt := defaultPrompt(PromptFix, PromptData{PRURL: "https://github.com/o/r/pull/3", Review: "security reviewer:\nUnescaped query"})
+	if !strings.Contains(prompt, "The review panel reported:\n\nsecurity reviewer:\nUnescaped query") {
+		t.Errorf("fix prompt missing findings:\n%s", prompt)
+	}
+}
@@ -2,12 +2,12 @@ package taskrunner
 
 import (
 	"context"
-	"fmt"
 	"strings"
 )
 
 type Reviewer struct {
 	executor *Executor
+	prompt   func(name, prURL string) string // renders review and fix prompts; nil uses the defaults
 }
 
 func NewReviewer(opts ...ExecutorOption) *Reviewer {
@@ -16,22 +16,51 @@ func NewReviewer(opts ...ExecutorOption) *Reviewer {
 	}
 }
 
+// InDir returns a copy of rv that reviews and fixes from the checkout in dir.
+func (rv *Reviewer) InDir(dir string) *Reviewer {
+	return &Reviewer{executor: rv.executor.InDir(dir), prompt: rv.prompt}
+}
+
+// withPrompts returns a copy of rv that renders its prompts with render,
+// so project overrides and task fields reach the review and fix prompts.
+func (rv *Reviewer) withPrompts(render func(name, prURL string) string) *Reviewer {
+	return &Reviewer{executor: rv.executor, prompt: render}
+}
+
+// withModel returns a copy of rv whose reviews run on model.
+func (rv *Reviewer) withModel(model string) *Reviewer {
+	return &Reviewer{executor: rv.executor.withModel(model), prompt: rv.prompt}
+}
+
 func (rv *Reviewer) Review(ctx context.Context, prURL string) (*ExecuteResult, error) {
 	prompt := ReviewPrompt(prURL)
+	if rv.prompt != nil {
+		prompt = rv.prompt(PromptReview, prURL)
+	}
 	return rv.executor.Run(ctx, prompt)
 }
 
 func (rv *Reviewer) Fix(ctx context.Context, prURL string) (*ExecuteResult, error) {
 	prompt := FixPrompt(prURL)
+	if rv.prompt != nil {
+		prompt = rv.prompt(PromptFix, prURL)
+	}
+	return rv.executor.Run(ctx, prompt)
+}
+
+// run reviews with a prompt rendered by the caller, for panel reviewers.
+func (rv *Reviewer) run(ctx context.Context, prompt string) (*ExecuteResult, error) {
 	return rv.executor.Run(ctx, prompt)
 }
 
+// ReviewPrompt returns the default review prompt for prURL.
 func ReviewPrompt(prURL string) string {
-	return fmt.Sprintf("Code review: %s", prURL)
+	return defaultPrompt(PromptReview, PromptData{PRURL: prURL})
 }
 
+// FixPrompt returns the default prompt for addressing review comments on prURL.
 func FixPrompt(prURL string) string {
-	return fmt.Sprintf(`Fix the code review comments on %s. Read the review with gh pr view and address all requested changes. Commit and push your fixes.`, prURL)
+	return defaultPrompt(PromptFix, PromptData{PRURL: prURL})
 }
 
 func IsApproved(stdout string) bool {
@@ -8,32 +8,86 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/siyuqian/devpilot/internal/github"
+	"github.com/siyuqian/devpilot/internal/plan"
+	"github.com/siyuqian/devpilot/internal/project"
 )
 
 type Config struct {
-	BoardName     string
-	Interval      time.Duration
-	Timeout       time.Duration
-	ReviewTimeout time.Duration // 0 disables code review
-	Once          bool
-	DryRun        bool
-	WorkDir       string
-	UseOpenSpec   bool
+	BoardName       string
+	Interval        time.Duration
+	Timeout         time.Duration
+	ReviewTimeout   time.Duration // 0 disables code review
+	Once            bool
+	DryRun          bool
+	WorkDir         string
+	UseOpenSpec     bool
+	BaseBranch      string // empty means auto-detect main/master
+	Remote          string // empty means origin
+	PRRepo          string // owner/repo PRs target; empty lets gh infer it
+	StackedPRs      bool   // split OpenSpec changes into one stacked PR per task group
+	PRTemplate      string // path to a PR body template; empty uses the embedded default
+	PRRules         project.PRRules
+	BranchPolicy    string        // what to do with a task branch left by an earlier run; empty means BranchPolicyReset
+	Workspace       string        // root for per-repo checkouts of tasks that name a repo; empty runs every task in WorkDir
+	GitHubHost      string        // GitHub host workspace checkouts are cloned from; empty means github.com
+	RunnerID        string        // identifies this runner in task leases; empty disables leasing
+	Selector        []string      // labels a task must carry for this runner to take it
+	LeaseTTL        time.Duration // how long a task claim lasts without a heartbeat; 0 means DefaultLeaseTTL
+	PlanGate        string        // check plans before executing them: PlanGateLint, PlanGateRefine, or empty to skip
+	Context         project.ContextConfig
+	ReviewPanel     project.ReviewPanel // several reviewers instead of the single code review
+	Escalation      project.EscalationConfig
+	Schedule        *Schedule       // when tasks may start; nil means any time
+	Drain           bool            // exit once no task is ready instead of polling
+	RequireApproval bool            // tasks wait for an operator's approval, e.g. /devpilot approve
+	Project         *project.Config // the loaded .devpilot.yaml, exposed to prompt templates
 }
 
+// Branch policies for re-running a task whose branch already exists.
+const (
+	BranchPolicyReset  = "reset"  // start over from the base branch
+	BranchPolicyRebase = "rebase" // keep the earlier commits, rebased onto the base branch
+)
+
 type Runner struct {
 	config       Config
+	projectDir   string // the directory the runner was started in; holds logs and the PR template
+	workspace    *Workspace
 	source       TaskSource
 	executor     *Executor
 	reviewer     *Reviewer
 	git          *GitOps
 	logger       *log.Logger
 	eventHandler EventHandler
+	notify       EventHandler // publishes events outside the process, e.g. to Slack
+	summarize    Summarizer
+	refine       plan.Generator
+	github       *github.Client
+	stats        taskStats
+	control      control
+	slackBot     *slackBotConfig
 }
 
 // RunnerOption configures a Runner.
 type RunnerOption func(*Runner)
 
+// WithSummarizer sets the function used to write the AI change summary in PR
+// descriptions. Without one, PR bodies omit the change summary.
+func WithSummarizer(fn Summarizer) RunnerOption {
+	return func(r *Runner) {
+		r.summarize = fn
+	}
+}
+
+// WithGitHubClient sets the GitHub client used to open, update and merge PRs.
+func WithGitHubClient(c *github.Client) RunnerOption {
+	return func(r *Runner) {
+		r.github = c
+	}
+}
+
 // WithEventHandler sets an event handler that receives runner lifecycle events.
 func WithEventHandler(handler EventHandler) RunnerOption {
 	return func(r *Runner) {
@@ -41,16 +95,34 @@ func WithEventHandler(handler EventHandler) RunnerOption {
 	}
 }
 
+// WithNotifier sets a handler that publishes runner events outside the
+// process, such as to Slack. Unlike an event handler it does not replace the
+// runner's log output.
+func WithNotifier(notify EventHandler) RunnerOption {
+	return func(r *Runner) {
+		r.notify = notify
+	}
+}
+
 func New(cfg Config, source TaskSource, opts ...RunnerOption) *Runner {
 	r := &Runner{
-		config: cfg,
-		source: source,
-		git:    NewGitOps(cfg.WorkDir),
-		logger: log.New(os.Stdout, "", log.LstdFlags),
+		config:     cfg,
+		projectDir: cfg.WorkDir,
+		source:     source,
+		logger:     log.New(os.Stdout, "", log.LstdFlags),
+	}
+	if cfg.Workspace != "" {
+		r.workspace = NewWorkspace(cfg.Workspace, cfg.GitHubHost)
 	}
 	for _, opt := range opts {
 		opt(r)
 	}
+	r.git = NewGitOps(cfg.WorkDir,
+		WithBaseBranch(cfg.BaseBranch),
+		WithRemote(cfg.Remote),
+		WithPRRepo(cfg.PRRepo),
+		WithGitHub(r.github),
+	)
 
 	// When event handler is set, silence the logger to avoid duplicate output.
 	// All information is conveyed through events instead.
@@ -58,13 +130,10 @@ func New(cfg Config, source TaskSource, opts ...RunnerOption) *Runner {
 		r.logger = log.New(io.Discard, "", 0)
 	}
 
-	// When event handler is set, enable streaming output on executor
-	var execOpts []ExecutorOption
-	if r.eventHandler != nil {
-		bridge := newEventBridge(r.eventHandler)
-		execOpts = append(execOpts, WithClaudeEventHandler(bridge.Handle))
-	}
-	r.executor = NewExecutor(execOpts...)
+	// Always stream so the runner can collect per-task stats for the PR body;
+	// events are forwarded to the event handler when one is set.
+	bridge := newEventBridge(r.emit)
+	r.executor = NewExecutor(WithClaudeEventHandler(bridge.Handle))
 
 	if cfg.ReviewTimeout > 0 {
 		r.reviewer = NewReviewer()
@@ -73,9 +142,14 @@ func New(cfg Config, source TaskSource, opts ...RunnerOption) *Runner {
 }
 
 func (r *Runner) emit(e Event) {
+	r.stats.observe(e)
+	r.control.observe(e)
 	if r.eventHandler != nil {
 		r.eventHandler(e)
 	}
+	if r.notify != nil {
+		r.notify(e)
+	}
 }
 
 func (r *Runner) init() error {
@@ -94,7 +168,9 @@ func (r *Runner) Run(ctx context.Context) error {
 		return err
 	}
 
-	// Pre-flight: ensure working directory is clean
+	// Pre-flight: ensure working directory is clean. Tasks without a repo
+	// run here even when a workspace is configured; workspace checkouts are
+	// owned by devpilot and reset before each task instead.
 	clean, err := r.git.IsClean()
 	if err != nil {
 		return fmt.Errorf("check working directory: %w", err)
@@ -103,8 +179,17 @@ func (r *Runner) Run(ctx context.Context) error {
 		return fmt.Errorf("working directory has uncommitted changes; commit or stash them before running")
 	}
 
+	if r.slackBot != nil {
+		stop, err := r.serveSlackBot()
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
 	r.logger.Println("Runner started. Polling for tasks...")
 
+	triggered := false // a cron trigger fired and its queue is not drained yet
 	for {
 		select {
 		case <-ctx.Done():
@@ -114,6 +199,12 @@ func (r *Runner) Run(ctx context.Context) error {
 		default:
 		}
 
+		if !r.awaitResume(ctx) || !r.awaitSchedule(ctx, &triggered) {
+			r.logger.Println("Shutting down.")
+			r.emit(RunnerStoppedEvent{})
+			return nil
+		}
+
 		r.emit(PollingEvent{})
 		tasks, err := r.source.FetchReady()
 		if err != nil {
@@ -128,9 +219,19 @@ func (r *Runner) Run(ctx context.Context) error {
 		}
 
 		if len(tasks) == 0 {
+			if r.config.Drain {
+				r.logger.Println("Queue drained. Exiting.")
+				r.emit(RunnerStoppedEvent{})
+				return nil
+			}
+			if triggered {
+				r.logger.Println("Queue drained. Waiting for the next trigger.")
+				triggered = false
+				continue
+			}
 			r.logger.Printf("No tasks. Sleeping %s...", r.config.Interval)
 			r.emit(NoTasksEvent{NextPoll: r.config.Interval})
-			if !r.sleep(ctx, r.config.Interval) {
+			if !r.idle(ctx, r.config.Interval) {
 				r.logger.Println("Shutting down.")
 				r.emit(RunnerStoppedEvent{})
 				return nil
@@ -139,8 +240,30 @@ func (r *Runner) Run(ctx context.Context) error {
 		}
 
 		SortByPriority(tasks)
-		task := tasks[0]
-		r.processCard(ctx, task)
+		task, ok := r.claimNext(tasks)
+		if !ok {
+			if r.config.Drain {
+				r.logger.Println("No claimable tasks left. Exiting.")
+				r.emit(RunnerStoppedEvent{})
+				return nil
+			}
+			if triggered {
+				r.logger.Println("No claimable tasks left. Waiting for the next trigger.")
+				triggered = false
+				continue
+			}
+			r.logger.Printf("No claimable tasks. Sleeping %s...", r.config.Interval)
+			r.emit(NoTasksEvent{NextPoll: r.config.Interval})
+			if !r.idle(ctx, r.config.Interval) {
+				r.logger.Println("Shutting down.")
+				r.emit(RunnerStoppedEvent{})
+				return nil
+			}
+			continue
+		}
+		taskCtx, release := r.holdLease(ctx, task.ID)
+		r.processCard(taskCtx, task)
+		release()
 
 		if r.config.Once {
 			r.logger.Println("--once flag set. Exiting.")
@@ -170,195 +293,290 @@ func (r *Runner) processCard(ctx context.Context, task Task) {
 		r.logger.Printf("Failed to move card to In Progress: %v", err)
 	}
 
-	// Git: checkout main, pull, create branch
-	branch := r.git.BranchName(task.ID, task.Name)
-	if err := r.git.CheckoutMain(); err != nil {
-		r.failCard(task, start, fmt.Sprintf("git checkout main: %v", err))
+	// Tasks for another repository run in that repository's workspace
+	// checkout rather than the runner's own.
+	checkout, err := r.checkout(task)
+	if err != nil {
+		r.failCard(ctx, task, start, fmt.Sprintf("prepare checkout of %s: %v", task.Repo, err))
 		return
 	}
-	r.git.Pull() // best-effort
-	if err := r.git.CreateBranch(branch); err != nil {
-		r.failCard(task, start, fmt.Sprintf("git create branch: %v", err))
+
+	// Vague plans usually fail after a long run; check them up front.
+	if !r.planGate(ctx, &task, checkout, start) {
 		return
 	}
-	r.emit(CardStartedEvent{CardID: task.ID, CardName: task.Name, Branch: branch})
+	r.gatherContext(&task, checkout)
 
-	// Build prompt
-	prompt := r.buildPrompt(task)
+	// Git: checkout base, pull, create branch. A task-level base branch
+	// (from a "base:<branch>" label) overrides the configured one.
+	git := checkout.ForBase(task.BaseBranch)
+	if groups := r.stackGroups(task, git); len(groups) > 1 {
+		r.processStack(ctx, task, start, git, groups)
+		return
+	}
+	branch := git.BranchName(task.ID, task.Name)
+	if err := git.CheckoutBase(); err != nil {
+		r.failCard(ctx, task, start, fmt.Sprintf("git checkout %s: %v", git.Base(), err))
+		return
+	}
+	git.Pull() // best-effort
+	resumed, err := r.prepareBranch(git, branch)
+	if err != nil {
+		r.failCard(ctx, task, start, fmt.Sprintf("git create branch: %v", err))
+		return
+	}
+	r.emit(CardStartedEvent{CardID: task.ID, CardName: task.Name, URL: task.URL, Branch: branch})
+
+	// Run the task, moving up the model ladder when an attempt fails in a
+	// way a stronger model may fix.
+	res, models := r.climbLadder(task, func(tier int, model string) attemptResult {
+		if tier > 0 {
+			if err := git.CheckoutBase(); err != nil {
+				return attemptResult{Reason: outcomeError, Err: fmt.Sprintf("git checkout %s: %v", git.Base(), err)}
+			}
+			if resumed, err = r.prepareBranch(git, branch); err != nil {
+				return attemptResult{Reason: outcomeError, Err: fmt.Sprintf("git create branch: %v", err)}
+			}
+		}
+		return r.runAttempt(ctx, task, git, branch, model, resumed, start)
+	})
+	if res.Reason != "" {
+		if len(models) > 1 {
+			res.Err += fmt.Sprintf(" (models tried: %s)", modelTrail(models))
+		}
+		r.failCard(ctx, task, start, res.Err)
+		git.CheckoutBase()
+		return
+	}
+	prURL := res.PRURL
+	if r.abandoned(ctx, task) {
+		git.CheckoutBase()
+		return
+	}
+
+	if err := git.MergePR(prURL); err != nil {
+		r.logger.Printf("Auto-merge failed (may need approval): %v", err)
+	}
+
+	// Move to Done
+	duration := time.Since(start).Round(time.Second)
+	r.emit(CardDoneEvent{CardID: task.ID, CardName: task.Name, PRURL: prURL, Duration: duration})
+	comment := fmt.Sprintf("✅ Task completed by devpilot runner\nDuration: %s\nPR: %s", duration, prURL)
+	if len(models) > 1 {
+		comment += "\nModels: " + modelTrail(models)
+	}
+	r.source.MarkDone(task.ID, comment)
+	r.logger.Printf("Card %q completed in %s. PR: %s", task.Name, duration, prURL)
+
+	git.CheckoutBase()
+	git.Pull()
+}
+
+// runAttempt executes task on model and carries it through push, PR and
+// review. It does not mark the task; the caller decides whether to escalate.
+func (r *Runner) runAttempt(ctx context.Context, task Task, git *GitOps, branch, model string, resumed bool, start time.Time) attemptResult {
+	prompt := r.buildPrompt(task, git, branch, resumed)
 
-	// Execute
 	taskCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
 	defer cancel()
 
-	result, err := r.executor.Run(taskCtx, prompt)
+	result, err := r.executor.InDir(git.dir).withModel(model).Run(taskCtx, prompt)
 
 	// Save log
 	r.saveLog(task.ID, result)
 
+	// A task that was stopped (shutdown or a lost lease) must neither move
+	// up the model ladder nor publish what it has.
+	if ctx.Err() != nil {
+		return attemptResult{Reason: outcomeError, Err: fmt.Sprintf("stopped: %v", context.Cause(ctx))}
+	}
+
 	if err != nil || result.ExitCode != 0 {
-		errMsg := "non-zero exit code"
-		if result.TimedOut {
-			errMsg = "execution timed out"
-		} else if result.Stderr != "" {
-			errMsg = truncate(result.Stderr, 500)
-		}
-		r.failCard(task, start, errMsg)
-		r.git.CheckoutMain()
-		return
+		return attemptResult{Reason: EscalateExecution, Err: executionError(result)}
 	}
 
 	// Verify claude produced commits before pushing
-	hasCommits, err := r.git.HasNewCommits(branch)
+	hasCommits, err := git.HasNewCommits(branch)
 	if err != nil {
-		r.failCard(task, start, fmt.Sprintf("check commits: %v", err))
-		r.git.CheckoutMain()
-		return
+		return attemptResult{Reason: outcomeError, Err: fmt.Sprintf("check commits: %v", err)}
 	}
 	if !hasCommits {
-		r.failCard(task, start, "claude produced no commits on task branch")
-		r.git.CheckoutMain()
-		return
+		return attemptResult{Reason: EscalateNoCommits, Err: "claude produced no commits on task branch"}
 	}
 
-	// Push and create PR
-	if err := r.git.Push(branch); err != nil {
-		r.failCard(task, start, fmt.Sprintf("git push: %v", err))
-		r.git.CheckoutMain()
-		return
+	// Push and create (or update) the PR
+	if err := r.pushBranch(git, branch); err != nil {
+		return attemptResult{Reason: outcomeError, Err: fmt.Sprintf("git push: %v", err)}
 	}
 
-	prBody := fmt.Sprintf("## Task\n%s\n\n🤖 Executed by devpilot runner", task.URL)
-	prURL, err := r.git.CreatePR(task.Name, prBody)
+	prBody := r.prBody(ctx, task, git, branch, start)
+	prURL, err := r.openPR(git, branch, task.Name, prBody)
 	if err != nil {
-		r.failCard(task, start, fmt.Sprintf("create PR: %v", err))
-		r.git.CheckoutMain()
-		return
+		return attemptResult{Reason: outcomeError, Err: fmt.Sprintf("create PR: %v", err)}
 	}
+	r.applyPRMetadata(task, git, branch, prURL)
 
 	// Code review gate (blocking with self-heal loop)
-	if r.reviewer != nil {
-		approved := false
-		for attempt := 0; attempt <= MaxReviewRetries; attempt++ {
-			r.logger.Printf("Running code review for PR: %s (attempt %d)", prURL, attempt+1)
-			r.emit(ReviewStartedEvent{PRURL: prURL})
-			reviewCtx, reviewCancel := context.WithTimeout(ctx, r.config.ReviewTimeout)
-			reviewResult, reviewErr := r.reviewer.Review(reviewCtx, prURL)
-			reviewCancel()
-
-			if reviewErr != nil {
-				r.logger.Printf("Code review error: %v", reviewErr)
-				r.emit(ReviewDoneEvent{PRURL: prURL, ExitCode: -1})
-				break
+	return reviewOutcome(prURL, r.reviewGate(ctx, task, git, branch, prURL))
+}
+
+// reviewOutcome turns the review gate's verdict on prURL into the attempt's
+// result. Only a review that ran and never approved escalates; a review that
+// could not run says nothing about the model's work.
+func reviewOutcome(prURL string, err error) attemptResult {
+	if err == nil {
+		return attemptResult{PRURL: prURL}
+	}
+	reason := outcomeError
+	if _, ok := err.(reviewRejectedError); ok {
+		reason = EscalateReview
+	}
+	return attemptResult{PRURL: prURL, Reason: reason, Err: err.Error()}
+}
+
+// reviewGate runs the blocking code review on prURL, letting the reviewer fix
+// and push up to MaxReviewRetries times. It returns nil when the review
+// approves (or review is disabled), a reviewRejectedError when it kept
+// rejecting the PR, and another error when a review could not run. With a review panel configured, the panel's combined verdict
+// decides and the findings of rejecting reviewers are passed to the fix.
+func (r *Runner) reviewGate(ctx context.Context, task Task, git *GitOps, branch, prURL string) error {
+	if r.reviewer == nil {
+		return nil
+	}
+	var findings string
+	reviewer := r.reviewer.InDir(git.dir).withPrompts(func(name, url string) string {
+		data := r.promptData(task, git, branch)
+		data.PRURL = url
+		data.Review = findings
+		return r.prompt(name, data)
+	})
+	for attempt := 0; attempt <= MaxReviewRetries; attempt++ {
+		r.logger.Printf("Running code review for PR: %s (attempt %d)", prURL, attempt+1)
+		var approved bool
+		if len(r.config.ReviewPanel.Reviewers) > 0 {
+			approved, findings = r.runPanel(ctx, task, git, branch, prURL)
+		} else {
+			var err error
+			if approved, err = r.review(ctx, reviewer, prURL); err != nil {
+				return fmt.Errorf("code review: %w", err)
 			}
+		}
 
-			r.emit(ReviewDoneEvent{PRURL: prURL, ExitCode: reviewResult.ExitCode})
+		if approved {
+			r.logger.Printf("Code review approved for PR: %s", prURL)
+			return nil
+		}
 
-			if IsApproved(reviewResult.Stdout) {
-				r.logger.Printf("Code review approved for PR: %s", prURL)
-				approved = true
-				break
+		// Review found issues — attempt fix if retries remain
+		if attempt < MaxReviewRetries {
+			r.logger.Printf("Review found issues, attempting fix (attempt %d/%d)", attempt+1, MaxReviewRetries)
+			r.emit(FixStartedEvent{PRURL: prURL, Attempt: attempt + 1})
+			fixCtx, fixCancel := context.WithTimeout(ctx, r.config.ReviewTimeout)
+			fixResult, fixErr := reviewer.Fix(fixCtx, prURL)
+			fixCancel()
+
+			fixExitCode := -1
+			if fixErr == nil {
+				fixExitCode = fixResult.ExitCode
 			}
+			r.emit(FixDoneEvent{PRURL: prURL, Attempt: attempt + 1, ExitCode: fixExitCode})
 
-			// Review found issues — attempt fix if retries remain
-			if attempt < MaxReviewRetries {
-				r.logger.Printf("Review found issues, attempting fix (attempt %d/%d)", attempt+1, MaxReviewRetries)
-				r.emit(FixStartedEvent{PRURL: prURL, Attempt: attempt + 1})
-				fixCtx, fixCancel := context.WithTimeout(ctx, r.config.ReviewTimeout)
-				fixResult, fixErr := r.reviewer.Fix(fixCtx, prURL)
-				fixCancel()
-
-				fixExitCode := -1
-				if fixErr == nil {
-					fixExitCode = fixResult.ExitCode
-				}
-				r.emit(FixDoneEvent{PRURL: prURL, Attempt: attempt + 1, ExitCode: fixExitCode})
-
-				if fixErr != nil {
-					r.logger.Printf("Fix attempt failed: %v", fixErr)
-					continue
-				}
-
-				// Push the fix
-				if err := r.git.Push(branch); err != nil {
-					r.logger.Printf("Failed to push fix: %v", err)
-					r.failCard(task, start, fmt.Sprintf("push fix: %v", err))
-					r.git.CheckoutMain()
-					return
-				}
+			if fixErr != nil {
+				r.logger.Printf("Fix attempt failed: %v", fixErr)
+				continue
 			}
-		}
 
-		if !approved {
-			r.failCard(task, start, fmt.Sprintf("code review failed after %d attempts", MaxReviewRetries+1))
-			r.git.CheckoutMain()
-			return
+			// Push the fix
+			if err := git.Push(branch); err != nil {
+				r.logger.Printf("Failed to push fix: %v", err)
+				return fmt.Errorf("push fix: %v", err)
+			}
 		}
 	}
+	return reviewRejectedError{attempts: MaxReviewRetries + 1}
+}
 
-	if err := r.git.MergePR(); err != nil {
-		r.logger.Printf("Auto-merge failed (may need approval): %v", err)
+// review runs the single code review on prURL and reports whether it
+// approved. A review that times out, cannot start or exits non-zero is an
+// error rather than a rejection.
+func (r *Runner) review(ctx context.Context, reviewer *Reviewer, prURL string) (bool, error) {
+	r.emit(ReviewStartedEvent{PRURL: prURL})
+	reviewCtx, reviewCancel := context.WithTimeout(ctx, r.config.ReviewTimeout)
+	reviewResult, reviewErr := reviewer.Review(reviewCtx, prURL)
+	reviewCancel()
+
+	if reviewErr != nil {
+		r.logger.Printf("Code review error: %v", reviewErr)
+		r.emit(ReviewDoneEvent{PRURL: prURL, ExitCode: -1})
+		return false, reviewErr
 	}
 
-	// Move to Done
-	duration := time.Since(start).Round(time.Second)
-	r.emit(CardDoneEvent{CardID: task.ID, CardName: task.Name, PRURL: prURL, Duration: duration})
-	comment := fmt.Sprintf("✅ Task completed by devpilot runner\nDuration: %s\nPR: %s", duration, prURL)
-	r.source.MarkDone(task.ID, comment)
-	r.logger.Printf("Card %q completed in %s. PR: %s", task.Name, duration, prURL)
+	if reviewResult.ExitCode != 0 {
+		r.logger.Printf("Code review exited with code %d", reviewResult.ExitCode)
+		r.emit(ReviewDoneEvent{PRURL: prURL, ExitCode: reviewResult.ExitCode})
+		return false, fmt.Errorf("exit code %d", reviewResult.ExitCode)
+	}
+	approved := IsApproved(reviewResult.Stdout)
+	r.emit(ReviewDoneEvent{PRURL: prURL, ExitCode: reviewResult.ExitCode, Approved: approved})
+	return approved, nil
+}
 
-	r.git.CheckoutMain()
-	r.git.Pull()
+// executionError summarizes why an executor run failed.
+func executionError(result *ExecuteResult) string {
+	if result == nil {
+		return "execution failed"
+	}
+	if result.TimedOut {
+		return "execution timed out"
+	}
+	if result.Stderr != "" {
+		return truncate(result.Stderr, 500)
+	}
+	return "non-zero exit code"
 }
 
-func (r *Runner) buildPrompt(task Task) string {
+// buildPrompt renders the execution prompt for task on branch.
+func (r *Runner) buildPrompt(task Task, git *GitOps, branch string, resumed bool) string {
+	data := r.promptData(task, git, branch)
+	data.Resumed = resumed
 	if r.config.UseOpenSpec {
-		return fmt.Sprintf(`Execute the following OpenSpec change autonomously from start to finish. This runs unattended — never stop to ask for feedback, confirmation, or approval.
-
-Run: /opsx:apply %s
-
-Rules:
-- Execute ALL tasks without stopping
-- Commit after each logical unit of work
-- Never ask for user input or feedback
-- If a task is blocked, skip it and continue with the next task
-- When ALL tasks are complete, push to the current branch`, task.Name)
+		return r.prompt(PromptOpenSpec, data)
 	}
-	return fmt.Sprintf(`Execute the following task plan autonomously from start to finish. This runs unattended — never stop to ask for feedback, confirmation, or approval. Execute ALL steps/batches continuously without pausing.
-
-Use /superpowers:test-driven-development and /superpowers:verification-before-completion skills during execution.
-
-Task: %s
-
-Plan:
-%s
-
-Rules:
-- Execute ALL steps in the plan without stopping. Do NOT pause between batches or steps for review.
-- Commit after each logical unit of work
-- Never ask for user input or feedback
-- If a step is blocked, skip it and continue with the next step
-- When ALL steps are complete, push to the current branch`, task.Name, task.Description)
+	return r.prompt(PromptExecute, data)
 }
 
-func (r *Runner) failCard(task Task, start time.Time, errMsg string) {
+func (r *Runner) failCard(ctx context.Context, task Task, start time.Time, errMsg string) {
+	if r.abandoned(ctx, task) {
+		return
+	}
 	duration := time.Since(start).Round(time.Second)
-	r.emit(CardFailedEvent{CardID: task.ID, CardName: task.Name, ErrMsg: errMsg, Duration: duration})
-	logPath := filepath.Join(r.config.WorkDir, ".devpilot", "logs", task.ID+".log")
+	logPath := filepath.Join(r.projectDir, ".devpilot", "logs", safeID(task.ID)+".log")
+	r.emit(CardFailedEvent{CardID: task.ID, CardName: task.Name, ErrMsg: errMsg, Duration: duration, LogPath: logPath})
 	comment := fmt.Sprintf("❌ Task failed\nDuration: %s\nError: %s\nSee full log: %s", duration, errMsg, logPath)
 	r.source.MarkFailed(task.ID, comment)
 	r.logger.Printf("Card %q failed: %s", task.Name, errMsg)
 }
 
+// abandoned reports whether the task's lease was lost, in which case it is
+// left for the runner now holding it rather than marked done or failed.
+func (r *Runner) abandoned(ctx context.Context, task Task) bool {
+	if !leaseLost(ctx) {
+		return false
+	}
+	r.logger.Printf("Lost the lease on %q to another runner; abandoning it: %v", task.Name, context.Cause(ctx))
+	return true
+}
+
 func (r *Runner) saveLog(cardID string, result *ExecuteResult) {
 	if result == nil {
 		return
 	}
-	logDir := filepath.Join(r.config.WorkDir, ".devpilot", "logs")
+	logDir := filepath.Join(r.projectDir, ".devpilot", "logs")
 	if err := os.MkdirAll(logDir, 0755); err != nil {
 		r.logger.Printf("Failed to create log directory: %v", err)
 		return
 	}
-	logPath := filepath.Join(logDir, cardID+".log")
+	logPath := filepath.Join(logDir, safeID(cardID)+".log")
 	content := fmt.Sprintf("=== STDOUT ===\n%s\n\n=== STDERR ===\n%s\n", result.Stdout, result.Stderr)
 	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
 		r.logger.Printf("Failed to write log file %s: %v", logPath, err)
@@ -0,0 +1,339 @@
+package taskrunner
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+// Reasons for a ScheduleWaitEvent.
+const (
+	ScheduleWaitTrigger = "the next trigger"
+	ScheduleWaitWindow  = "the schedule to allow new tasks"
+)
+
+// Schedule decides when the runner may start tasks: inside its active hours,
+// outside its quiet periods and, with cron triggers, only after a trigger
+// has fired. A task that is already running always finishes.
+type Schedule struct {
+	loc      *time.Location
+	active   []window
+	quiet    []window
+	triggers []cronSpec
+}
+
+// NewSchedule parses cfg. A zero config gives a schedule that always allows
+// starting tasks.
+func NewSchedule(cfg project.ScheduleConfig) (*Schedule, error) {
+	s := &Schedule{loc: time.Local}
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("schedule timezone: %w", err)
+		}
+		s.loc = loc
+	}
+	for _, spec := range cfg.ActiveHours {
+		w, err := parseWindow(spec)
+		if err != nil {
+			return nil, fmt.Errorf("active hours %q: %w", spec, err)
+		}
+		s.active = append(s.active, w)
+	}
+	for _, spec := range cfg.Quiet {
+		w, err := parseWindow(spec)
+		if err != nil {
+			return nil, fmt.Errorf("quiet period %q: %w", spec, err)
+		}
+		s.quiet = append(s.quiet, w)
+	}
+	for _, spec := range cfg.Cron {
+		c, err := parseCron(spec)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: %w", spec, err)
+		}
+		s.triggers = append(s.triggers, c)
+	}
+	return s, nil
+}
+
+// Triggered reports whether the schedule runs on cron triggers.
+func (s *Schedule) Triggered() bool {
+	return len(s.triggers) > 0
+}
+
+// CanStart reports whether a task may start at t.
+func (s *Schedule) CanStart(t time.Time) bool {
+	t = t.In(s.loc)
+	for _, w := range s.quiet {
+		if w.contains(t) {
+			return false
+		}
+	}
+	if len(s.active) == 0 {
+		return true
+	}
+	for _, w := range s.active {
+		if w.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxScheduleScan bounds the minute-by-minute search for the next start or
+// trigger time.
+const maxScheduleScan = 366 * 24 * time.Hour
+
+// NextStart returns the first time at or after t when a task may start, or
+// the zero time if there is none within a year.
+func (s *Schedule) NextStart(t time.Time) time.Time {
+	if s.CanStart(t) {
+		return t
+	}
+	for m := t.Truncate(time.Minute).Add(time.Minute); m.Sub(t) < maxScheduleScan; m = m.Add(time.Minute) {
+		if s.CanStart(m) {
+			return m
+		}
+	}
+	return time.Time{}
+}
+
+// NextTrigger returns the first cron trigger after t, or the zero time if
+// there is none within a year.
+func (s *Schedule) NextTrigger(t time.Time) time.Time {
+	for m := t.Truncate(time.Minute).Add(time.Minute); m.Sub(t) < maxScheduleScan; m = m.Add(time.Minute) {
+		local := m.In(s.loc)
+		for _, c := range s.triggers {
+			if c.matches(local) {
+				return m
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// window is a daily time range such as "22:00-06:00", optionally limited to
+// some weekdays ("Mon-Fri 09:00-17:00"). A range that wraps past midnight
+// belongs to the day it starts on.
+type window struct {
+	days       [7]bool // indexed by time.Weekday
+	start, end int     // minutes since midnight; end may be 24*60
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func parseWindow(spec string) (window, error) {
+	var w window
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 1:
+		for i := range w.days {
+			w.days[i] = true
+		}
+	case 2:
+		if err := parseDays(fields[0], &w.days); err != nil {
+			return w, err
+		}
+		fields = fields[1:]
+	default:
+		return w, fmt.Errorf("want [days] HH:MM-HH:MM")
+	}
+	from, to, ok := strings.Cut(fields[0], "-")
+	if !ok {
+		return w, fmt.Errorf("want HH:MM-HH:MM, got %q", fields[0])
+	}
+	var err error
+	if w.start, err = parseClock(from); err != nil {
+		return w, err
+	}
+	if w.end, err = parseClock(to); err != nil {
+		return w, err
+	}
+	if w.start == w.end {
+		return w, fmt.Errorf("empty range %q", fields[0])
+	}
+	return w, nil
+}
+
+// parseDays parses "Mon-Fri", "Sat,Sun" or combinations like "Mon,Wed-Fri".
+func parseDays(spec string, days *[7]bool) error {
+	for _, part := range strings.Split(strings.ToLower(spec), ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return fmt.Errorf("unknown day %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return fmt.Errorf("unknown day %q", to)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(s, ":")
+	h, herr := strconv.Atoi(hh)
+	m, merr := strconv.Atoi(mm)
+	if !ok || herr != nil || merr != nil || h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return h*60 + m, nil
+}
+
+func (w window) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if w.start < w.end {
+		return w.days[t.Weekday()] && minute >= w.start && minute < w.end
+	}
+	// Wraps past midnight: the evening part belongs to today, the morning
+	// part to yesterday.
+	yesterday := (t.Weekday() + 6) % 7
+	return (w.days[t.Weekday()] && minute >= w.start) || (w.days[yesterday] && minute < w.end)
+}
+
+// cronSpec is a five-field cron expression: minute, hour, day of month,
+// month and day of week, each "*", a number, a range, a list or a step
+// ("*/15", "1-5", "0,30").
+type cronSpec struct {
+	minute, hour, dom, month, dow []bool
+	domAny, dowAny                bool
+}
+
+func parseCron(spec string) (cronSpec, error) {
+	var c cronSpec
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return c, fmt.Errorf("want 5 fields, got %d", len(fields))
+	}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return c, fmt.Errorf("minute: %w", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return c, fmt.Errorf("hour: %w", err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return c, fmt.Errorf("day of month: %w", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return c, fmt.Errorf("month: %w", err)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return c, fmt.Errorf("day of week: %w", err)
+	}
+	c.dow[0] = c.dow[0] || c.dow[7] // 7 is Sunday too
+	c.domAny, c.dowAny = fields[2] == "*", fields[4] == "*"
+	return c, nil
+}
+
+func parseCronField(field string, lo, hi int) ([]bool, error) {
+	set := make([]bool, hi+1)
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+		first, last := lo, hi
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if first, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("invalid value %q", from)
+			}
+			last = first
+			if isRange {
+				if last, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("invalid value %q", to)
+				}
+			} else if hasStep {
+				last = hi
+			}
+		}
+		if first < lo || last > hi || first > last {
+			return nil, fmt.Errorf("%q out of range %d-%d", part, lo, hi)
+		}
+		for v := first; v <= last; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// matches follows cron's rule that when both day fields are restricted, a
+// time matching either one matches.
+func (c cronSpec) matches(t time.Time) bool {
+	if !c.minute[t.Minute()] || !c.hour[t.Hour()] || !c.month[int(t.Month())] {
+		return false
+	}
+	dom, dow := c.dom[t.Day()], c.dow[int(t.Weekday())]
+	switch {
+	case c.domAny && c.dowAny:
+		return true
+	case c.domAny:
+		return dow
+	case c.dowAny:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// awaitSchedule blocks until the schedule lets the runner start a task:
+// first until a cron trigger fires (unless *triggered says one already
+// has), then until it is inside active hours and outside quiet periods. It
+// returns false when ctx is done or the schedule never allows a start.
+func (r *Runner) awaitSchedule(ctx context.Context, triggered *bool) bool {
+	s := r.config.Schedule
+	if s == nil {
+		return true
+	}
+	if s.Triggered() && !*triggered {
+		next := s.NextTrigger(time.Now())
+		if next.IsZero() {
+			r.logger.Println("No cron trigger within a year.")
+			return false
+		}
+		if !r.waitUntil(ctx, next, ScheduleWaitTrigger) {
+			return false
+		}
+		*triggered = true
+	}
+	now := time.Now()
+	next := s.NextStart(now)
+	if next.IsZero() {
+		r.logger.Println("The schedule allows no task to start within a year.")
+		return false
+	}
+	if next.After(now) {
+		return r.waitUntil(ctx, next, ScheduleWaitWindow)
+	}
+	return true
+}
+
+// waitUntil sleeps until t, announcing why.
+func (r *Runner) waitUntil(ctx context.Context, t time.Time, reason string) bool {
+	r.logger.Printf("Waiting for %s at %s...", reason, t.Format("Mon 15:04"))
+	r.emit(ScheduleWaitEvent{Until: t, Reason: reason})
+	return r.sleep(ctx, time.Until(t))
+}
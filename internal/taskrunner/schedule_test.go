@@ -0,0 +1,164 @@
+package taskrunner
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+// at returns a UTC time in the week starting Sunday 2026-03-01.
+func at(day time.Weekday, hour, minute int) time.Time {
+	return time.Date(2026, 3, 1+int(day), hour, minute, 0, 0, time.UTC)
+}
+
+func mustSchedule(t *testing.T, cfg project.ScheduleConfig) *Schedule {
+	t.Helper()
+	cfg.Timezone = "UTC"
+	s, err := NewSchedule(cfg)
+	if err != nil {
+		t.Fatalf("NewSchedule: %v", err)
+	}
+	return s
+}
+
+func TestSchedule_CanStart(t *testing.T) {
+	s := mustSchedule(t, project.ScheduleConfig{
+		ActiveHours: []string{"Mon-Fri 22:00-06:00", "Sat,Sun 00:00-24:00"},
+		Quiet:       []string{"Tue 02:00-03:00"},
+	})
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"weekday evening", at(time.Monday, 23, 0), true},
+		{"overnight into Tuesday", at(time.Tuesday, 5, 59), true},
+		{"weekday daytime", at(time.Tuesday, 12, 0), false},
+		{"end is exclusive", at(time.Tuesday, 6, 0), false},
+		{"quiet period", at(time.Tuesday, 2, 30), false},
+		{"Saturday noon", at(time.Saturday, 12, 0), true},
+		{"Monday morning after Sunday", at(time.Monday, 1, 0), false},
+		{"Saturday morning after Friday night", at(time.Saturday, 1, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.CanStart(tt.t); got != tt.want {
+				t.Errorf("CanStart(%s) = %v, want %v", tt.t.Format("Mon 15:04"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_NextStart(t *testing.T) {
+	s := mustSchedule(t, project.ScheduleConfig{ActiveHours: []string{"22:00-06:00"}, Quiet: []string{"23:00-23:30"}})
+	if got, want := s.NextStart(at(time.Monday, 12, 15)), at(time.Monday, 22, 0); !got.Equal(want) {
+		t.Errorf("NextStart = %s, want %s", got, want)
+	}
+	if got, want := s.NextStart(at(time.Monday, 23, 10)), at(time.Monday, 23, 30); !got.Equal(want) {
+		t.Errorf("NextStart in quiet period = %s, want %s", got, want)
+	}
+	now := at(time.Monday, 22, 45).Add(17 * time.Second)
+	if got := s.NextStart(now); !got.Equal(now) {
+		t.Errorf("NextStart inside a window = %s, want now", got)
+	}
+	if !mustSchedule(t, project.ScheduleConfig{}).CanStart(at(time.Wednesday, 15, 0)) {
+		t.Error("empty schedule should always allow starting")
+	}
+}
+
+func TestSchedule_NextTrigger(t *testing.T) {
+	s := mustSchedule(t, project.ScheduleConfig{Cron: []string{"30 2 * * 1-5", "0 */6 * * 0"}})
+	tests := []struct {
+		from, want time.Time
+	}{
+		{at(time.Monday, 1, 0), at(time.Monday, 2, 30)},
+		{at(time.Monday, 2, 30), at(time.Tuesday, 2, 30)},
+		{at(time.Friday, 3, 0), at(time.Sunday+7, 0, 0)},
+		{at(time.Sunday, 6, 1), at(time.Sunday, 12, 0)},
+	}
+	for _, tt := range tests {
+		if got := s.NextTrigger(tt.from); !got.Equal(tt.want) {
+			t.Errorf("NextTrigger(%s) = %s, want %s", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestCronSpec_DayFields(t *testing.T) {
+	c, err := parseCron("0 9 1 * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Either day field matches when both are restricted.
+	first := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)   // a Wednesday
+	monday := time.Date(2026, 4, 6, 9, 0, 0, 0, time.UTC)  // a Monday
+	tuesday := time.Date(2026, 4, 7, 9, 0, 0, 0, time.UTC) // neither
+	if !c.matches(first) || !c.matches(monday) || c.matches(tuesday) {
+		t.Error("cron day-of-month/day-of-week union not honoured")
+	}
+}
+
+func TestNewSchedule_Invalid(t *testing.T) {
+	for _, cfg := range []project.ScheduleConfig{
+		{Timezone: "Mars/Olympus"},
+		{ActiveHours: []string{"22:00"}},
+		{ActiveHours: []string{"Funday 01:00-02:00"}},
+		{Quiet: []string{"25:00-26:00"}},
+		{ActiveHours: []string{"10:00-10:00"}},
+		{Cron: []string{"* * * *"}},
+		{Cron: []string{"60 * * * *"}},
+		{Cron: []string{"*/0 * * * *"}},
+	} {
+		if _, err := NewSchedule(cfg); err == nil {
+			t.Errorf("NewSchedule(%+v) should fail", cfg)
+		}
+	}
+}
+
+// queueSource hands out one batch of tasks per poll.
+type queueSource struct {
+	planSource
+	batches [][]Task
+	polls   int
+}
+
+func (s *queueSource) FetchReady() ([]Task, error) {
+	s.polls++
+	if len(s.batches) == 0 {
+		return nil, nil
+	}
+	batch := s.batches[0]
+	s.batches = s.batches[1:]
+	return batch, nil
+}
+
+func TestRun_DrainExitsWhenQueueEmpty(t *testing.T) {
+	dir := setupGitRepo(t)
+	src := &queueSource{batches: [][]Task{
+		{{ID: "1", Name: "one", Description: "plan"}},
+		{{ID: "2", Name: "two", Description: "plan"}},
+	}}
+	r := &Runner{
+		config: Config{DryRun: true, Drain: true, Interval: time.Hour, WorkDir: dir},
+		source: src,
+		git:    NewGitOps(dir),
+		logger: log.New(io.Discard, "", 0),
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- r.Run(context.Background()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("drain mode did not exit")
+	}
+	if src.polls != 3 {
+		t.Errorf("polled %d times, want 3 (two batches, then empty)", src.polls)
+	}
+}
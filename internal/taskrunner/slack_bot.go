@@ -0,0 +1,183 @@
+package taskrunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/plan"
+	"github.com/siyuqian/devpilot/internal/slack"
+)
+
+// maxQueueLines caps the tasks listed by /devpilot queue.
+const maxQueueLines = 15
+
+const slackBotUsage = "Usage: `/devpilot status`, `/devpilot queue`, `/devpilot approve <id>`, `/devpilot retry <id>`, `/devpilot pause`, `/devpilot resume`"
+
+// toTaskTimeout bounds reading a thread, drafting its plan and filing it.
+const toTaskTimeout = 5 * time.Minute
+
+type slackBotConfig struct {
+	addr          string // updated to the bound address once listening
+	signingSecret string
+	client        *slack.Client  // reads threads for the to-task shortcut; nil disables it
+	draft         plan.Generator // drafts plans from threads
+}
+
+// WithSlackBot serves the /devpilot slash command, message buttons and the
+// to-task message shortcut on addr while the runner runs, verifying
+// requests with the Slack app's signing secret. The shortcut reads threads
+// with client and drafts their plans with draft; a nil client disables it.
+func WithSlackBot(addr, signingSecret string, client *slack.Client, draft plan.Generator) RunnerOption {
+	return func(r *Runner) {
+		r.slackBot = &slackBotConfig{addr: addr, signingSecret: signingSecret, client: client, draft: draft}
+	}
+}
+
+// serveSlackBot starts the Slack bot endpoint. The returned function shuts
+// it down.
+func (r *Runner) serveSlackBot() (stop func(), err error) {
+	ln, err := net.Listen("tcp", r.slackBot.addr)
+	if err != nil {
+		return nil, fmt.Errorf("slack bot: %w", err)
+	}
+	srv := &http.Server{
+		Handler:           slack.NewBot(r.slackBot.signingSecret, r.SlackCommand, slack.WithBotLogger(r.logger)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	r.slackBot.addr = ln.Addr().String()
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			r.logger.Printf("Slack bot stopped: %v", err)
+		}
+	}()
+	r.logger.Printf("Slack bot listening on %s", r.slackBot.addr)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx) //nolint:errcheck
+	}, nil
+}
+
+// SlackCommand runs a /devpilot command or button press against the runner.
+func (r *Runner) SlackCommand(cmd slack.Command) slack.Reply {
+	arg := ""
+	if len(cmd.Args) > 0 {
+		arg = cmd.Args[0]
+	}
+	who := "<@" + cmd.UserID + ">"
+	switch cmd.Name {
+	case "status":
+		return slack.Reply{Text: r.statusText()}
+	case "queue":
+		return slack.Reply{Text: r.queueText()}
+	case "pause":
+		r.Pause()
+		r.logger.Printf("Paused by %s", cmd.UserName)
+		return slack.Reply{Text: "⏸ " + who + " paused the runner; the current task will finish.", InChannel: true}
+	case "resume":
+		r.Resume()
+		r.logger.Printf("Resumed by %s", cmd.UserName)
+		return slack.Reply{Text: "▶ " + who + " resumed the runner.", InChannel: true}
+	case "approve", "retry":
+		if arg == "" {
+			return slack.Reply{Text: "Usage: `/devpilot " + cmd.Name + " <task id>`"}
+		}
+		var err error
+		if cmd.Name == "approve" {
+			err = r.Approve(arg)
+		} else {
+			err = r.Retry(arg)
+		}
+		if err != nil {
+			return slack.Reply{Text: fmt.Sprintf("⚠️ Could not %s %s: %s", cmd.Name, slack.Escape(arg), slack.Escape(err.Error()))}
+		}
+		r.logger.Printf("Task %s: %s by %s", arg, cmd.Name, cmd.UserName)
+		verb := map[string]string{"approve": "approved", "retry": "queued a retry of"}[cmd.Name]
+		return slack.Reply{Text: fmt.Sprintf("✅ %s %s task %s.", who, verb, slack.Escape(arg)), InChannel: true}
+	case "to-task":
+		if arg == "" {
+			return slack.Reply{Text: "Use the to-task shortcut on a message in the thread to turn into a task."}
+		}
+		return r.threadToTask(cmd.ChannelID, arg, cmd.UserName)
+	default:
+		return slack.Reply{Text: slackBotUsage}
+	}
+}
+
+// threadToTask drafts a plan from a thread and files it with the runner's
+// task source, outside the ready queue: anyone who can use the shortcut
+// could otherwise have the runner execute whatever the thread asks for.
+func (r *Runner) threadToTask(channelID, threadTS, user string) slack.Reply {
+	if r.slackBot == nil || r.slackBot.client == nil || r.slackBot.draft == nil {
+		return slack.Reply{Text: "⚠️ Turning threads into tasks needs the Slack bot token. Run: devpilot login slack"}
+	}
+	creator, ok := r.source.(TaskCreator)
+	if !ok {
+		return slack.Reply{Text: "⚠️ This task source cannot create tasks."}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), toTaskTimeout)
+	defer cancel()
+	task, err := slack.ThreadToTask(ctx, r.slackBot.client, slack.ThreadRef{ChannelID: channelID, TS: threadTS}, r.slackBot.draft,
+		func(title, plan string) (string, error) {
+			t, err := creator.CreateTask(title, plan)
+			return t.URL, err
+		})
+	if err != nil {
+		return slack.Reply{Text: "⚠️ Could not turn the thread into a task: " + slack.Escape(err.Error())}
+	}
+	r.logger.Printf("Task %q created from Slack by %s", task.Title, user)
+	return slack.Reply{Text: "📋 Created " + slack.Link(task.URL, task.Title) + " from this thread. Review it and queue it for the runner when it's ready."}
+}
+
+func (r *Runner) statusText() string {
+	s := r.Status()
+	var b strings.Builder
+	switch {
+	case s.Current != nil:
+		fmt.Fprintf(&b, "▶ Working on %s (%s) for %s", slack.Link(s.Current.URL, s.Current.Name), slack.Escape(s.Current.ID), time.Since(s.Since).Round(time.Second))
+		if s.Paused {
+			b.WriteString("; paused after it")
+		}
+	case s.Paused:
+		b.WriteString("⏸ Paused")
+	default:
+		b.WriteString("Waiting for tasks")
+	}
+	fmt.Fprintf(&b, "\nDone: %d · Failed: %d", s.Done, s.Failed)
+	for _, t := range s.Awaiting {
+		fmt.Fprintf(&b, "\n⏳ %s (%s) waits for approval", slack.Link(t.URL, t.Name), slack.Escape(t.ID))
+	}
+	return b.String()
+}
+
+func (r *Runner) queueText() string {
+	tasks, err := r.Queue()
+	if err != nil {
+		return "⚠️ Could not read the queue: " + slack.Escape(err.Error())
+	}
+	if len(tasks) == 0 {
+		return "The queue is empty."
+	}
+	awaiting := map[string]bool{}
+	for _, t := range r.Status().Awaiting {
+		awaiting[t.ID] = true
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d ready:", len(tasks))
+	for i, t := range tasks {
+		if i == maxQueueLines {
+			fmt.Fprintf(&b, "\n…and %d more", len(tasks)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n%d. [P%d] %s (%s)", i+1, t.Priority, slack.Link(t.URL, t.Name), slack.Escape(t.ID))
+		if awaiting[t.ID] {
+			b.WriteString(" ⏳ awaiting approval")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package taskrunner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/slack"
+)
+
+// postSlashCommand sends text as a /devpilot command, signed with secret.
+func postSlashCommand(t *testing.T, addr, secret, text string) (int, string) {
+	t.Helper()
+	body := url.Values{"command": {"/devpilot"}, "text": {text}, "user_id": {"U001"}, "user_name": {"ada"}}.Encode()
+	req, _ := http.NewRequest(http.MethodPost, "http://"+addr+"/", strings.NewReader(body))
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", strconv.FormatInt(ts, 10))
+	req.Header.Set("X-Slack-Signature", slack.Sign(secret, ts, []byte(body)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("post %q: %v", text, err)
+	}
+	defer resp.Body.Close()
+	var reply struct {
+		Text string `json:"text"`
+	}
+	json.NewDecoder(resp.Body).Decode(&reply)
+	return resp.StatusCode, reply.Text
+}
+
+func TestSlackBot_ControlsRunner(t *testing.T) {
+	src := &requeueSource{}
+	r := &Runner{
+		config:   Config{RequireApproval: true},
+		source:   src,
+		logger:   log.New(io.Discard, "", 0),
+		slackBot: &slackBotConfig{addr: "127.0.0.1:0", signingSecret: "s3cret"},
+	}
+	stop, err := r.serveSlackBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	ln := r.slackBot.addr
+
+	if code, _ := postSlashCommand(t, ln, "wrong", "pause"); code != http.StatusUnauthorized {
+		t.Errorf("unsigned request: status %d, want 401", code)
+	}
+	if r.Status().Paused {
+		t.Fatal("unsigned request paused the runner")
+	}
+
+	if _, text := postSlashCommand(t, ln, "s3cret", "pause"); !strings.Contains(text, "paused") {
+		t.Errorf("pause reply = %q", text)
+	}
+	if !r.Status().Paused {
+		t.Error("runner not paused")
+	}
+	if _, text := postSlashCommand(t, ln, "s3cret", "status"); !strings.Contains(text, "Paused") {
+		t.Errorf("status reply = %q", text)
+	}
+	postSlashCommand(t, ln, "s3cret", "resume")
+	if r.Status().Paused {
+		t.Error("runner still paused")
+	}
+
+	postSlashCommand(t, ln, "s3cret", "retry 42")
+	if len(src.requeued) != 1 || src.requeued[0] != "42" {
+		t.Errorf("requeued %v", src.requeued)
+	}
+	if _, text := postSlashCommand(t, ln, "s3cret", "approve"); !strings.Contains(text, "Usage") {
+		t.Errorf("approve without an id: %q", text)
+	}
+	if _, text := postSlashCommand(t, ln, "s3cret", "dance"); !strings.Contains(text, "/devpilot status") {
+		t.Errorf("unknown command reply = %q", text)
+	}
+}
+
+func TestSlackCommand_Queue(t *testing.T) {
+	src := &queueSource{batches: [][]Task{{
+		{ID: "7", Name: "later", Priority: 2},
+		{ID: "3", Name: "urgent", Priority: 0, URL: "https://example.com/3"},
+	}}}
+	r := &Runner{source: src, logger: log.New(io.Discard, "", 0)}
+	reply := r.SlackCommand(slack.Command{Name: "queue"})
+	want := "2 ready:\n1. [P0] <https://example.com/3|urgent> (3)\n2. [P2] later (7)"
+	if reply.Text != want {
+		t.Errorf("queue reply = %q, want %q", reply.Text, want)
+	}
+	if reply := r.SlackCommand(slack.Command{Name: "queue"}); reply.Text != "The queue is empty." {
+		t.Errorf("empty queue reply = %q", reply.Text)
+	}
+}
+
+// creatorSource records tasks created through it.
+type creatorSource struct {
+	planSource
+	created []string
+}
+
+func (s *creatorSource) CreateTask(title, plan string) (Task, error) {
+	s.created = append(s.created, title)
+	return Task{ID: "9", Name: title, Description: plan, URL: "https://example.com/9"}, nil
+}
+
+func TestSlackCommand_ToTask(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conversations.replies":
+			if r.URL.Query().Get("ts") != "1700000000.000100" || r.URL.Query().Get("channel") != "C001" {
+				t.Errorf("unexpected thread: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"ok":true,"messages":[{"ts":"1700000000.000100","username":"ci","text":"deploys are slow"}]}`))
+		case "/chat.getPermalink":
+			w.Write([]byte(`{"ok":true,"permalink":"https://acme.slack.com/archives/C001/p1700000000000100"}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer api.Close()
+
+	src := &creatorSource{}
+	draft := func(context.Context, string) (string, error) { return "# Speed up deploys\n\n## Steps\n1. Cache", nil }
+	r := &Runner{
+		source:   src,
+		logger:   log.New(io.Discard, "", 0),
+		slackBot: &slackBotConfig{client: slack.NewClient("xoxb", slack.WithBaseURL(api.URL)), draft: draft},
+	}
+	reply := r.SlackCommand(slack.Command{Name: "to-task", Args: []string{"1700000000.000100"}, ChannelID: "C001"})
+	if reply.Text != "📋 Created <https://example.com/9|Speed up deploys> from this thread. Review it and queue it for the runner when it's ready." {
+		t.Errorf("reply = %q", reply.Text)
+	}
+	if len(src.created) != 1 || src.created[0] != "Speed up deploys" {
+		t.Errorf("created %v", src.created)
+	}
+
+	r.source = &requeueSource{}
+	if reply := r.SlackCommand(slack.Command{Name: "to-task", Args: []string{"1700000000.000100"}, ChannelID: "C001"}); !strings.Contains(reply.Text, "cannot create tasks") {
+		t.Errorf("reply without a TaskCreator = %q", reply.Text)
+	}
+}
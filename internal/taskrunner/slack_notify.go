@@ -0,0 +1,265 @@
+package taskrunner
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/siyuqian/devpilot/internal/slack"
+)
+
+// DefaultSlackEvents are the event types posted when the configuration
+// names none.
+var DefaultSlackEvents = []string{
+	"card_started", "review_done", "fix_started", "escalated", "card_done", "card_failed", "approval_needed",
+}
+
+// slackEvents lists the event types the Slack notifier can post.
+var slackEvents = []string{
+	"card_started", "review_started", "review_done", "fix_started", "fix_done",
+	"escalated", "card_done", "card_failed", "runner_error", "approval_needed",
+}
+
+// maxLogExcerpt caps the log tail attached to failure messages.
+const maxLogExcerpt = 2500
+
+// maxErrMsg caps the error in failure messages, so it fits with the task
+// name into one section, which Slack limits to 3000 characters.
+const maxErrMsg = 2000
+
+// slackPoster is the part of the Slack client the notifier uses.
+type slackPoster interface {
+	Send(msg slack.Message) (string, error)
+}
+
+// SlackNotifier posts runner events to a Slack channel. Each task gets a
+// thread: its start is the parent message, review and fix progress are
+// replies, and the outcome is a reply broadcast to the channel. Events are
+// posted from a background goroutine so Slack never slows the runner.
+type SlackNotifier struct {
+	client  slackPoster
+	channel string
+	events  map[string]bool
+	queue   chan Event
+	done    chan struct{}
+	logf    func(format string, args ...any)
+
+	// Only touched by the posting goroutine.
+	task   string // ID of the task whose thread is open
+	thread string // ts of that thread's parent message
+}
+
+// NewSlackNotifier returns a notifier posting events of the given types
+// (DefaultSlackEvents when empty) to channelID. Call Close to flush it.
+func NewSlackNotifier(client slackPoster, channelID string, events []string) (*SlackNotifier, error) {
+	if len(events) == 0 {
+		events = DefaultSlackEvents
+	}
+	n := &SlackNotifier{
+		client:  client,
+		channel: channelID,
+		events:  map[string]bool{},
+		queue:   make(chan Event, 100),
+		done:    make(chan struct{}),
+		logf:    func(string, ...any) {},
+	}
+	for _, e := range events {
+		if !slices.Contains(slackEvents, e) {
+			return nil, fmt.Errorf("unknown notification event %q (want one of %s)", e, strings.Join(slackEvents, ", "))
+		}
+		n.events[e] = true
+	}
+	go n.loop()
+	return n, nil
+}
+
+// Notify queues e for posting. It never blocks; events are dropped if Slack
+// falls far behind.
+func (n *SlackNotifier) Notify(e Event) {
+	if _, started := e.(CardStartedEvent); !started && !n.events[e.eventType()] {
+		return
+	}
+	select {
+	case n.queue <- e:
+	default:
+		n.logf("Slack notification queue full; dropping %s", e.eventType())
+	}
+}
+
+// Close posts the queued events and stops the notifier.
+func (n *SlackNotifier) Close() {
+	close(n.queue)
+	<-n.done
+}
+
+func (n *SlackNotifier) loop() {
+	defer close(n.done)
+	for e := range n.queue {
+		if err := n.post(e); err != nil {
+			n.logf("Slack notification (%s): %v", e.eventType(), err)
+		}
+	}
+}
+
+func (n *SlackNotifier) post(e Event) error {
+	// A task's start opens its thread even when starts are not announced,
+	// so that later replies still group together.
+	if ev, ok := e.(CardStartedEvent); ok {
+		n.task, n.thread = ev.CardID, ""
+		if !n.events["card_started"] {
+			return nil
+		}
+		ts, err := n.client.Send(slackStartMessage(n.channel, ev))
+		n.thread = ts
+		return err
+	}
+
+	msg, ok := slackMessage(e)
+	if !ok {
+		return nil
+	}
+	msg.Channel = n.channel
+	switch ev := e.(type) {
+	case CardDoneEvent, CardFailedEvent:
+		if id := cardID(ev); id == n.task && n.thread != "" {
+			msg.ThreadTS, msg.ReplyBroadcast = n.thread, true
+		}
+		n.task, n.thread = "", ""
+	case RunnerErrorEvent, ApprovalNeededEvent:
+		// Not about the task in progress.
+	default:
+		msg.ThreadTS = n.thread
+	}
+	_, err := n.client.Send(msg)
+	return err
+}
+
+func cardID(e Event) string {
+	switch ev := e.(type) {
+	case CardDoneEvent:
+		return ev.CardID
+	case CardFailedEvent:
+		return ev.CardID
+	}
+	return ""
+}
+
+func slackStartMessage(channel string, ev CardStartedEvent) slack.Message {
+	return slack.Message{
+		Channel: channel,
+		Text:    "Started: " + ev.CardName,
+		Blocks: []slack.Block{
+			slack.Section("🚀 *Started* " + slack.Link(ev.URL, ev.CardName)),
+			slack.Context("Branch `" + slack.Escape(ev.Branch) + "`"),
+		},
+	}
+}
+
+// slackMessage renders every event but a task start; ok is false for
+// events the notifier does not post.
+func slackMessage(e Event) (slack.Message, bool) {
+	line := func(text string) (slack.Message, bool) {
+		return slack.Message{Text: text, Blocks: []slack.Block{slack.Section(slack.Escape(text))}}, true
+	}
+	switch ev := e.(type) {
+	case ReviewStartedEvent:
+		if ev.Reviewer != "" {
+			return line("🔍 " + ev.Reviewer + " review started")
+		}
+		return line("🔍 Code review started")
+	case ReviewDoneEvent:
+		who := "Code review"
+		if ev.Reviewer != "" {
+			who = ev.Reviewer + " review"
+		}
+		switch {
+		case ev.ExitCode != 0:
+			return line(fmt.Sprintf("⚠️ %s failed (exit %d)", who, ev.ExitCode))
+		case ev.Approved:
+			return line("✅ " + who + " approved")
+		default:
+			return line("❌ " + who + " requested changes")
+		}
+	case FixStartedEvent:
+		return line(fmt.Sprintf("🔧 Fix attempt %d started", ev.Attempt))
+	case FixDoneEvent:
+		return line(fmt.Sprintf("🔧 Fix attempt %d finished (exit %d)", ev.Attempt, ev.ExitCode))
+	case EscalatedEvent:
+		return line(fmt.Sprintf("⤴ %s on %s; retrying on %s", ev.Reason, modelName(ev.From), ev.To))
+	case CardDoneEvent:
+		return slack.Message{
+			Text: "Done: " + ev.CardName,
+			Blocks: []slack.Block{
+				slack.Section("✅ *Done* " + slack.Escape(ev.CardName) + " · " + slack.Link(ev.PRURL, "pull request")),
+				slack.Context("Took " + ev.Duration.String()),
+			},
+		}, true
+	case CardFailedEvent:
+		blocks := []slack.Block{
+			slack.Section("❌ *Failed* " + slack.Escape(ev.CardName) + "\n" + slack.Escape(clipHead(ev.ErrMsg, maxErrMsg))),
+		}
+		if excerpt := logExcerpt(ev.LogPath); excerpt != "" {
+			blocks = append(blocks, slack.Section("```"+excerpt+"```"))
+		}
+		blocks = append(blocks,
+			slack.Context("Took "+ev.Duration.String()),
+			slack.Actions(slack.Button("Retry", "retry", ev.CardID)),
+		)
+		return slack.Message{Text: "Failed: " + ev.CardName, Blocks: blocks}, true
+	case ApprovalNeededEvent:
+		return slack.Message{
+			Text: "Waiting for approval: " + ev.CardName,
+			Blocks: []slack.Block{
+				slack.Section("⏳ *Waiting for approval* " + slack.Link(ev.URL, ev.CardName)),
+				slack.Actions(slack.Button("Approve", "approve", ev.CardID)),
+			},
+		}, true
+	case RunnerErrorEvent:
+		if ev.Err == nil {
+			return slack.Message{}, false
+		}
+		return line("⚠️ Runner error: " + ev.Err.Error())
+	}
+	return slack.Message{}, false
+}
+
+// clipHead shortens s to at most max bytes, cut at a rune boundary and
+// marked with an ellipsis. Errors lead with what went wrong, so the start is
+// kept.
+func clipHead(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
+
+// logExcerpt returns the end of a task log: its stderr when there is any,
+// otherwise what Claude last wrote.
+func logExcerpt(path string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	stdout, stderr, _ := strings.Cut(string(data), "=== STDERR ===")
+	text := strings.TrimSpace(stderr)
+	if text == "" {
+		text = AssistantText(strings.TrimPrefix(stdout, "=== STDOUT ==="))
+	}
+	if len(text) > maxLogExcerpt {
+		cut := len(text) - maxLogExcerpt
+		for cut < len(text) && !utf8.RuneStart(text[cut]) {
+			cut++
+		}
+		text = "…" + text[cut:]
+	}
+	return slack.Escape(strings.ReplaceAll(text, "```", "'''"))
+}
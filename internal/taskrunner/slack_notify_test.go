@@ -0,0 +1,154 @@
+package taskrunner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/siyuqian/devpilot/internal/slack"
+)
+
+// fakeSlack records sent messages and answers each with a new ts.
+type fakeSlack struct {
+	mu   sync.Mutex
+	sent []slack.Message
+}
+
+func (f *fakeSlack) Send(msg slack.Message) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, msg)
+	return fmt.Sprintf("100.%d", len(f.sent)), nil
+}
+
+func blocksText(msg slack.Message) string {
+	var b strings.Builder
+	for _, block := range msg.Blocks {
+		fmt.Fprint(&b, block)
+	}
+	return b.String()
+}
+
+func TestSlackNotifier_ThreadsTask(t *testing.T) {
+	fake := &fakeSlack{}
+	n, err := NewSlackNotifier(fake, "C123", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Notify(CardStartedEvent{CardID: "1", CardName: "Add login", Branch: "task/1-add-login", URL: "https://trello.com/c/1"})
+	n.Notify(ReviewStartedEvent{PRURL: "https://github.com/o/r/pull/7"}) // not in the defaults
+	n.Notify(ReviewDoneEvent{PRURL: "https://github.com/o/r/pull/7"})
+	n.Notify(FixStartedEvent{Attempt: 1})
+	n.Notify(CardDoneEvent{CardID: "1", CardName: "Add login", PRURL: "https://github.com/o/r/pull/7", Duration: time.Minute})
+	n.Notify(CardDoneEvent{CardID: "2", CardName: "Other"})
+	n.Close()
+
+	if len(fake.sent) != 5 {
+		t.Fatalf("sent %d messages, want 5", len(fake.sent))
+	}
+	start := fake.sent[0]
+	if start.Channel != "C123" || start.ThreadTS != "" {
+		t.Errorf("start message = %+v, want top level in C123", start)
+	}
+	if !strings.Contains(blocksText(start), "<https://trello.com/c/1|Add login>") {
+		t.Errorf("start message should link the card: %s", blocksText(start))
+	}
+	for _, msg := range fake.sent[1:3] {
+		if msg.ThreadTS != "100.1" || msg.ReplyBroadcast {
+			t.Errorf("progress message %q: thread %q broadcast %v, want plain reply in 100.1", msg.Text, msg.ThreadTS, msg.ReplyBroadcast)
+		}
+	}
+	if done := fake.sent[3]; done.ThreadTS != "100.1" || !done.ReplyBroadcast {
+		t.Errorf("done message: thread %q broadcast %v, want broadcast reply in 100.1", done.ThreadTS, done.ReplyBroadcast)
+	}
+	if !strings.Contains(blocksText(fake.sent[3]), "https://github.com/o/r/pull/7") {
+		t.Errorf("done message should link the PR: %s", blocksText(fake.sent[3]))
+	}
+	if other := fake.sent[4]; other.ThreadTS != "" {
+		t.Errorf("outcome of a task without a thread should be top level, got thread %q", other.ThreadTS)
+	}
+}
+
+func TestSlackNotifier_EventFilter(t *testing.T) {
+	fake := &fakeSlack{}
+	n, err := NewSlackNotifier(fake, "C123", []string{"card_failed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.Notify(CardStartedEvent{CardID: "1", CardName: "Add login"})
+	n.Notify(FixStartedEvent{Attempt: 1})
+	n.Notify(CardFailedEvent{CardID: "1", CardName: "Add login", ErrMsg: "boom"})
+	n.Close()
+
+	if len(fake.sent) != 1 {
+		t.Fatalf("sent %d messages, want only the failure", len(fake.sent))
+	}
+	if fake.sent[0].ThreadTS != "" {
+		t.Errorf("failure without an announced start should be top level, got thread %q", fake.sent[0].ThreadTS)
+	}
+
+	if _, err := NewSlackNotifier(fake, "C123", []string{"card_exploded"}); err == nil {
+		t.Error("expected error for unknown event name")
+	}
+}
+
+func TestSlackNotifier_FailureLogExcerpt(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "task.log")
+	log := "=== STDOUT ===\nworking\n=== STDERR ===\nfatal: tests <failed>\n"
+	if err := os.WriteFile(logPath, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+	msg, ok := slackMessage(CardFailedEvent{CardName: "Add login", ErrMsg: "exit 1", LogPath: logPath})
+	if !ok {
+		t.Fatal("failure should be posted")
+	}
+	text := blocksText(msg)
+	if !strings.Contains(text, "exit 1") || !strings.Contains(text, "fatal: tests &lt;failed&gt;") {
+		t.Errorf("failure message missing error or escaped stderr: %s", text)
+	}
+	if strings.Contains(text, "working") {
+		t.Errorf("stdout should not be quoted when stderr has output: %s", text)
+	}
+}
+
+func TestSlackNotifier_FailureErrorTruncated(t *testing.T) {
+	errMsg := "plan failed lint:\n" + strings.Repeat("é", maxErrMsg)
+	msg, _ := slackMessage(CardFailedEvent{CardName: "Add login", ErrMsg: errMsg})
+	text := msg.Blocks[0]["text"].(slack.Block)["text"].(string)
+	if !strings.Contains(text, "plan failed lint:") || !strings.HasSuffix(text, "…") {
+		t.Errorf("error should keep its start and be marked as cut: %q…", text[:40])
+	}
+	if n := utf8.RuneCountInString(text); n > 3000 || !utf8.ValidString(text) {
+		t.Errorf("failure section is %d characters (valid UTF-8: %v)", n, utf8.ValidString(text))
+	}
+}
+
+func TestLogExcerpt_Tail(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "task.log")
+	stderr := "é" + strings.Repeat("x", maxLogExcerpt)
+	if err := os.WriteFile(logPath, []byte("=== STDERR ===\n"+stderr), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := logExcerpt(logPath)
+	if !strings.HasPrefix(got, "…x") || len(got) != len("…")+maxLogExcerpt {
+		t.Errorf("logExcerpt kept %d bytes starting %q", len(got), got[:8])
+	}
+	if logExcerpt(filepath.Join(t.TempDir(), "missing.log")) != "" {
+		t.Error("missing log should give no excerpt")
+	}
+}
+
+func TestRunner_Notifier(t *testing.T) {
+	var got []string
+	r := New(Config{}, nil, WithNotifier(func(e Event) { got = append(got, e.eventType()) }))
+	r.emit(RunnerErrorEvent{Err: errors.New("poll failed")})
+	if len(got) != 1 || got[0] != "runner_error" {
+		t.Errorf("notifier got %v", got)
+	}
+}
@@ -1,5 +1,11 @@
 package taskrunner
 
+import "strings"
+
+// baseLabelPrefix marks a task label that overrides the base branch,
+// e.g. "base:release-1.2".
+const baseLabelPrefix = "base:"
+
 // Task is a provider-agnostic unit of work.
 type Task struct {
 	ID          string
@@ -8,6 +14,13 @@ type Task struct {
 	URL         string
 	Priority    int   // 0=P0, 1=P1, 2=P2 (default)
 	CreatedAt   int64 // Unix timestamp; used as tiebreaker within the same priority (FIFO)
+	Labels      []string
+	BaseBranch  string   // optional; overrides the configured base branch for this task
+	Assignees   []string // GitHub logins; empty for sources without GitHub users
+	Milestone   string
+	Closes      string       // issue the PR should close on merge, e.g. "owner/repo#42"
+	Repo        string       // owner/repo the task belongs to; empty runs it in the runner's own checkout
+	Context     *TaskContext // comments, checklists, attachments and linked issues, when gathered
 }
 
 // SourceInfo is returned by TaskSource.Init and used to populate RunnerStartedEvent.
@@ -25,3 +38,29 @@ type TaskSource interface {
 	MarkDone(id, comment string) error
 	MarkFailed(id, comment string) error
 }
+
+// PlanWriter is implemented by task sources that can replace a task's plan,
+// so a refined plan is written back to the card or issue it came from.
+type PlanWriter interface {
+	UpdatePlan(id, plan string) error
+}
+
+// TaskCreator is implemented by task sources that can file a new task, e.g.
+// one drafted from a Slack thread. The task is filed outside the ready queue
+// so a person reviews it before the runner takes it.
+type TaskCreator interface {
+	CreateTask(title, plan string) (Task, error)
+}
+
+// baseFromLabelNames returns the branch named by the first "base:<branch>"
+// label, or "" when no such label is present.
+func baseFromLabelNames(names []string) string {
+	for _, n := range names {
+		if branch, ok := strings.CutPrefix(n, baseLabelPrefix); ok {
+			if branch = strings.TrimSpace(branch); branch != "" {
+				return branch
+			}
+		}
+	}
+	return ""
+}
@@ -15,3 +15,21 @@ func TestSourceInfoZeroValue(t *testing.T) {
 		t.Error("SourceInfo zero value should have empty fields")
 	}
 }
+
+func TestBaseFromLabelNames(t *testing.T) {
+	cases := []struct {
+		labels   []string
+		expected string
+	}{
+		{[]string{"P1-high", "base:release-1.2"}, "release-1.2"},
+		{[]string{"base:develop", "base:main"}, "develop"}, // first wins
+		{[]string{"base:"}, ""},                            // empty branch ignored
+		{[]string{"database"}, ""},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got := baseFromLabelNames(c.labels); got != c.expected {
+			t.Errorf("labels %v: expected %q, got %q", c.labels, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package taskrunner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siyuqian/devpilot/internal/openspec"
+)
+
+// stackMergePollInterval is how often the merge cascade checks whether the
+// previous PR in a stack has merged.
+const stackMergePollInterval = 30 * time.Second
+
+// stackPR is one branch/PR pair in a stack, ordered bottom to top.
+type stackPR struct {
+	title  string
+	branch string
+	base   string
+	body   string
+	url    string
+}
+
+// StackBranchName returns the branch for step i (0-based) of a stack rooted
+// at branch, e.g. "task/abc-add-auth-2" for the second step.
+func StackBranchName(branch string, i int) string {
+	return fmt.Sprintf("%s-%d", branch, i+1)
+}
+
+// stackGroups returns the OpenSpec task groups to stack for task, read from
+// git's checkout, or nil when stacking is disabled or the change cannot be
+// found.
+func (r *Runner) stackGroups(task Task, git *GitOps) []openspec.TaskGroup {
+	if !r.config.StackedPRs || !r.config.UseOpenSpec {
+		return nil
+	}
+	change, err := openspec.FindChange(git.dir, task.Name)
+	if err != nil {
+		r.logger.Printf("Stacked PRs: %v; falling back to a single PR", err)
+		return nil
+	}
+	return change.Groups
+}
+
+// processStack executes an OpenSpec change as a stack of branches and PRs,
+// one per top-level task group, each based on the previous one. Once every
+// step is reviewed, the PR bodies are linked and merges cascade bottom-up.
+func (r *Runner) processStack(ctx context.Context, task Task, start time.Time, git *GitOps, groups []openspec.TaskGroup) {
+	root := git.BranchName(task.ID, task.Name)
+	base := git.Base()
+	if err := git.CheckoutBase(); err != nil {
+		r.failCard(ctx, task, start, fmt.Sprintf("git checkout %s: %v", base, err))
+		return
+	}
+	git.Pull() // best-effort
+	r.emit(CardStartedEvent{CardID: task.ID, CardName: task.Name, URL: task.URL, Branch: StackBranchName(root, 0)})
+
+	taskCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+
+	var stack []stackPR
+	parent := base
+	for i, group := range groups {
+		step := stackPR{
+			title:  fmt.Sprintf("%s (%d/%d): %s", task.Name, i+1, len(groups), group.Title),
+			branch: StackBranchName(root, i),
+			base:   parent,
+		}
+		stepGit := git.ForBase(parent)
+		fail := func(msg string) {
+			open := stack
+			if step.url != "" {
+				open = append(open[:len(open):len(open)], step)
+			}
+			r.failCard(ctx, task, start, fmt.Sprintf("stack step %d/%d: %s", i+1, len(groups), msg)+openPRsSection(open))
+			git.CheckoutBase()
+		}
+
+		// The step branch starts from the current HEAD, i.e. the previous step.
+		if err := stepGit.CreateBranch(step.branch); err != nil {
+			fail(fmt.Sprintf("git create branch: %v", err))
+			return
+		}
+
+		r.logger.Printf("Stack step %d/%d: %s", i+1, len(groups), group.Title)
+		r.stats = taskStats{}
+		result, err := r.executor.InDir(stepGit.dir).Run(taskCtx, r.buildStackStepPrompt(task, stepGit, step.branch, group, i, len(groups)))
+		r.saveLog(fmt.Sprintf("%s-%d", task.ID, i+1), result)
+		if ctx.Err() != nil {
+			fail(fmt.Sprintf("stopped: %v", context.Cause(ctx)))
+			return
+		}
+		if err != nil || result.ExitCode != 0 {
+			fail(executionError(result))
+			return
+		}
+
+		hasCommits, err := stepGit.HasNewCommits(step.branch)
+		if err != nil {
+			fail(fmt.Sprintf("check commits: %v", err))
+			return
+		}
+		if !hasCommits {
+			fail("claude produced no commits on step branch")
+			return
+		}
+
+		if err := r.pushBranch(stepGit, step.branch); err != nil {
+			fail(fmt.Sprintf("git push: %v", err))
+			return
+		}
+		step.body = r.prBody(ctx, task, stepGit, step.branch, start)
+		step.url, err = r.openPR(stepGit, step.branch, step.title, step.body)
+		if err != nil {
+			fail(fmt.Sprintf("create PR: %v", err))
+			return
+		}
+		r.applyPRMetadata(task, stepGit, step.branch, step.url)
+
+		if err := r.reviewGate(ctx, task, stepGit, step.branch, step.url); err != nil {
+			fail(err.Error())
+			return
+		}
+
+		stack = append(stack, step)
+		parent = step.branch
+	}
+
+	if r.abandoned(ctx, task) {
+		git.CheckoutBase()
+		return
+	}
+
+	// Link the stack in every PR body now that all URLs are known.
+	for i, pr := range stack {
+		body := pr.body + "\n\n" + stackSection(stack, i)
+		if err := git.EditPRBody(pr.url, body); err != nil {
+			r.logger.Printf("Failed to link stack in PR %s: %v", pr.url, err)
+		}
+	}
+
+	merged := r.cascadeMerge(ctx, git, base, stack)
+
+	duration := time.Since(start).Round(time.Second)
+	r.emit(CardDoneEvent{CardID: task.ID, CardName: task.Name, PRURL: stack[0].url, Duration: duration})
+	var urls strings.Builder
+	for _, pr := range stack {
+		fmt.Fprintf(&urls, "\n- %s", pr.url)
+	}
+	comment := fmt.Sprintf("✅ Task completed by devpilot runner\nDuration: %s\nStacked PRs (%d/%d merged):%s%s",
+		duration, merged, len(stack), urls.String(), openPRsSection(stack[merged:]))
+	r.source.MarkDone(task.ID, comment)
+	r.logger.Printf("Card %q completed in %s as a stack of %d PRs", task.Name, duration, len(stack))
+
+	git.CheckoutBase()
+	git.Pull()
+}
+
+// cascadeMerge merges the stack bottom-up: each PR is retargeted onto the
+// base branch only after the one below it has merged, so the stack lands in
+// order. It stops at the first PR that does not merge (e.g. it needs human
+// approval), leaving the rest stacked, and returns how many PRs merged. One
+// task timeout covers the whole cascade, so a stack holds up the runner no
+// longer than any other task.
+func (r *Runner) cascadeMerge(ctx context.Context, git *GitOps, base string, stack []stackPR) int {
+	waitCtx, cancel := context.WithTimeout(ctx, r.config.Timeout)
+	defer cancel()
+	for i, pr := range stack {
+		if i > 0 {
+			if err := git.RetargetPR(pr.url, base); err != nil {
+				r.logger.Printf("Failed to retarget %s onto %s: %v", pr.url, base, err)
+				return i
+			}
+		}
+		if err := git.MergeStackedPR(pr.url); err != nil {
+			r.logger.Printf("Auto-merge failed for %s (may need approval): %v", pr.url, err)
+			return i
+		}
+		if !r.waitForMerge(waitCtx, git, pr.url) {
+			r.logger.Printf("PR %s has not merged; leaving the rest of the stack for manual merge", pr.url)
+			return i
+		}
+	}
+	return len(stack)
+}
+
+// waitForMerge polls the PR state until it is merged, closed, or ctx is
+// done.
+func (r *Runner) waitForMerge(ctx context.Context, git *GitOps, prURL string) bool {
+	for {
+		state, err := git.PRState(prURL)
+		if err == nil {
+			switch state {
+			case "MERGED":
+				return true
+			case "CLOSED":
+				return false
+			}
+		}
+		if !r.sleep(ctx, stackMergePollInterval) {
+			return false
+		}
+	}
+}
+
+// stackSection renders the markdown list linking every PR in the stack,
+// highlighting the one at index current.
+func stackSection(stack []stackPR, current int) string {
+	var sb strings.Builder
+	sb.WriteString("## Stack\n")
+	for i, pr := range stack {
+		marker := "  "
+		if i == current {
+			marker = "👉"
+		}
+		fmt.Fprintf(&sb, "%s %d. %s — %s\n", marker, i+1, pr.title, pr.url)
+	}
+	sb.WriteString("\nMerges cascade bottom-up: each PR is retargeted onto the base branch once the one above it in this list has merged.\n")
+	return sb.String()
+}
+
+// openPRsSection lists the stack's PRs that were left open, for the task
+// comment, so nobody has to go looking for them.
+func openPRsSection(prs []stackPR) string {
+	if len(prs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n\n%d stacked PR(s) still open; merge them bottom-up or close them:", len(prs))
+	for _, pr := range prs {
+		fmt.Fprintf(&sb, "\n- %s", pr.url)
+	}
+	return sb.String()
+}
+
+func (r *Runner) buildStackStepPrompt(task Task, git *GitOps, branch string, group openspec.TaskGroup, i, n int) string {
+	data := r.promptData(task, git, branch)
+	data.Step = &StackStep{Number: i + 1, Total: n, Group: group.Title}
+	return r.prompt(PromptStackStep, data)
+}
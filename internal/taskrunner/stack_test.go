@@ -0,0 +1,94 @@
+package taskrunner
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/siyuqian/devpilot/internal/openspec"
+)
+
+func TestStackBranchName(t *testing.T) {
+	if got := StackBranchName("task/abc-add-auth", 0); got != "task/abc-add-auth-1" {
+		t.Errorf("got %q", got)
+	}
+	if got := StackBranchName("task/abc-add-auth", 2); got != "task/abc-add-auth-3" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestStackSection(t *testing.T) {
+	stack := []stackPR{
+		{title: "add-auth (1/2): 1. Backend", url: "https://github.com/o/r/pull/1"},
+		{title: "add-auth (2/2): 2. Frontend", url: "https://github.com/o/r/pull/2"},
+	}
+	got := stackSection(stack, 1)
+	if !strings.Contains(got, "## Stack") {
+		t.Errorf("missing heading:\n%s", got)
+	}
+	if !strings.Contains(got, "   1. add-auth (1/2): 1. Backend — https://github.com/o/r/pull/1") {
+		t.Errorf("first PR should be listed unmarked:\n%s", got)
+	}
+	if !strings.Contains(got, "👉 2. add-auth (2/2): 2. Frontend — https://github.com/o/r/pull/2") {
+		t.Errorf("current PR should be marked:\n%s", got)
+	}
+}
+
+func TestBuildStackStepPrompt(t *testing.T) {
+	r := &Runner{config: Config{UseOpenSpec: true}}
+	prompt := r.buildStackStepPrompt(Task{Name: "add-auth"}, nil, "", openspec.TaskGroup{Title: "2. Frontend"}, 1, 3)
+	if !strings.Contains(prompt, "step 2 of 3") {
+		t.Errorf("expected step position, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "/opsx:apply add-auth") {
+		t.Errorf("expected opsx:apply, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, `"## 2. Frontend"`) {
+		t.Errorf("expected group scope, got:\n%s", prompt)
+	}
+
+	task := Task{Name: "add-auth", Context: &TaskContext{Comments: []TaskComment{{Author: "alice", Body: "Keep the old login working."}}}}
+	prompt = r.buildStackStepPrompt(task, nil, "", openspec.TaskGroup{Title: "2. Frontend"}, 1, 3)
+	if !strings.Contains(prompt, "alice wrote:\nKeep the old login working.") {
+		t.Errorf("expected the task context, got:\n%s", prompt)
+	}
+}
+
+func TestStackGroups(t *testing.T) {
+	dir := t.TempDir()
+	changeDir := filepath.Join(dir, "openspec", "changes", "add-auth")
+	os.MkdirAll(changeDir, 0755)
+	os.WriteFile(filepath.Join(changeDir, "tasks.md"), []byte("## 1. Backend\n- [ ] 1.1 API\n\n## 2. Frontend\n- [ ] 2.1 UI\n"), 0644)
+
+	logger := log.New(io.Discard, "", 0)
+	task := Task{Name: "add-auth"}
+
+	r := &Runner{config: Config{WorkDir: dir, UseOpenSpec: true, StackedPRs: true}, logger: logger}
+	if groups := r.stackGroups(task, NewGitOps(dir)); len(groups) != 2 {
+		t.Errorf("expected 2 groups, got %d", len(groups))
+	}
+
+	r.config.StackedPRs = false
+	if groups := r.stackGroups(task, NewGitOps(dir)); groups != nil {
+		t.Errorf("expected nil groups when stacking is disabled, got %v", groups)
+	}
+
+	r.config.StackedPRs = true
+	if groups := r.stackGroups(Task{Name: "missing"}, NewGitOps(dir)); groups != nil {
+		t.Errorf("expected nil groups for unknown change, got %v", groups)
+	}
+}
+
+func TestOpenPRsSection(t *testing.T) {
+	if got := openPRsSection(nil); got != "" {
+		t.Errorf("openPRsSection(nil) = %q, want empty", got)
+	}
+	stack := []stackPR{{url: "https://github.com/o/r/pull/2"}, {url: "https://github.com/o/r/pull/3"}}
+	want := "\n\n2 stacked PR(s) still open; merge them bottom-up or close them:\n- https://github.com/o/r/pull/2\n- https://github.com/o/r/pull/3"
+	if got := openPRsSection(stack); got != want {
+		t.Errorf("openPRsSection() = %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package taskrunner
 
 import (
 	"encoding/json"
+	"strings"
 )
 
 // ClaudeEvent is the interface implemented by all parsed stream-json events.
@@ -253,3 +254,32 @@ func parseResultMsg(data []byte) (ClaudeEvent, error) {
 		OutputTokens: raw.Usage.OutputTokens,
 	}, nil
 }
+
+// AssistantText returns the text Claude wrote in stream-json output,
+// skipping tool calls and results. Lines that are not JSON are kept as they
+// are.
+func AssistantText(output string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		event, err := ParseLine([]byte(line))
+		if err != nil {
+			continue
+		}
+		switch ev := event.(type) {
+		case ClaudeAssistantMsg:
+			for _, block := range ev.Content {
+				if text, ok := block.(TextBlock); ok {
+					b.WriteString(text.Text)
+					b.WriteByte('\n')
+				}
+			}
+		case RawOutputMsg:
+			b.WriteString(ev.Text)
+			b.WriteByte('\n')
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
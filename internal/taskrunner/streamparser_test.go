@@ -330,3 +330,17 @@ func TestParseLineToolUseInputNestedObject(t *testing.T) {
 		t.Errorf("Input[path] = %v, want %q", tub.Input["path"], "/tmp/out.json")
 	}
 }
+
+func TestAssistantText(t *testing.T) {
+	output := `{"type":"system","session_id":"s1","model":"m","tools":[]}
+{"type":"assistant","message":{"content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"gh pr diff"}}]}}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Found a bug.\nVERDICT: REQUEST_CHANGES"}]}}
+{"type":"result","subtype":"success","num_turns":2}
+`
+	if got, want := AssistantText(output), "Found a bug.\nVERDICT: REQUEST_CHANGES"; got != want {
+		t.Errorf("AssistantText = %q, want %q", got, want)
+	}
+	if got := AssistantText("plain output\n"); got != "plain output" {
+		t.Errorf("AssistantText(raw) = %q", got)
+	}
+}
@@ -2,7 +2,10 @@ package taskrunner
 
 import (
 	"fmt"
+	"io"
+	"time"
 
+	"github.com/siyuqian/devpilot/internal/project"
 	"github.com/siyuqian/devpilot/internal/trello"
 )
 
@@ -10,6 +13,7 @@ import (
 type TrelloSource struct {
 	client       *trello.Client
 	boardName    string
+	boardID      string
 	readyListID  string
 	inProgListID string
 	doneListID   string
@@ -32,6 +36,7 @@ func (s *TrelloSource) Init() (SourceInfo, error) {
 		"Done":        &s.doneListID,
 		"Failed":      &s.failedListID,
 	}
+	s.boardID = board.ID
 	resolved := make(map[string]string, len(listNames))
 	for name, idPtr := range listNames {
 		list, err := s.client.FindListByName(board.ID, name)
@@ -55,12 +60,15 @@ func (s *TrelloSource) FetchReady() ([]Task, error) {
 	}
 	tasks := make([]Task, 0, len(cards))
 	for _, c := range cards {
+		labels := trelloLabelNames(c)
 		tasks = append(tasks, Task{
 			ID:          c.ID,
 			Name:        c.Name,
 			Description: c.Desc,
 			URL:         c.ShortURL,
-			Priority:    trelloPriority(c),
+			Priority:    priorityFromLabelNames(labels),
+			Labels:      labels,
+			BaseBranch:  baseFromLabelNames(labels),
 		})
 	}
 	return tasks, nil
@@ -84,10 +92,131 @@ func (s *TrelloSource) MarkFailed(id, comment string) error {
 	return s.client.AddComment(id, comment)
 }
 
+// Requeue moves a card back to the Ready list.
+func (s *TrelloSource) Requeue(id string) error {
+	return s.client.MoveCard(id, s.readyListID)
+}
+
+// CreateTask adds a card to the Inbox list, where it waits for someone to
+// review it and move it to Ready.
+func (s *TrelloSource) CreateTask(title, plan string) (Task, error) {
+	inbox, err := s.client.FindListByName(s.boardID, "Inbox")
+	if err != nil {
+		return Task{}, fmt.Errorf("find list %q: %w", "Inbox", err)
+	}
+	card, err := s.client.CreateCard(inbox.ID, title, plan)
+	if err != nil {
+		return Task{}, fmt.Errorf("create card: %w", err)
+	}
+	return Task{ID: card.ID, Name: card.Name, Description: card.Desc, URL: card.ShortURL}, nil
+}
+
+func (s *TrelloSource) GatherContext(task Task, want project.ContextConfig) (*TaskContext, error) {
+	c := &TaskContext{}
+	if want.Comments {
+		comments, err := s.client.GetCardComments(task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get comments: %w", err)
+		}
+		for _, cm := range comments {
+			if _, isLease := parseLease(cm.Data.Text); isLease {
+				continue
+			}
+			author := cm.MemberCreator.FullName
+			if author == "" {
+				author = cm.MemberCreator.Username
+			}
+			c.Comments = append(c.Comments, TaskComment{Author: author, Body: cm.Data.Text, CreatedAt: cm.Date})
+		}
+	}
+	if want.Checklists {
+		checklists, err := s.client.GetCardChecklists(task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get checklists: %w", err)
+		}
+		for _, cl := range checklists {
+			list := Checklist{Name: cl.Name}
+			for _, item := range cl.CheckItems {
+				list.Items = append(list.Items, ChecklistItem{Text: item.Name, Done: item.State == "complete"})
+			}
+			c.Checklists = append(c.Checklists, list)
+		}
+	}
+	if want.Attachments {
+		attachments, err := s.client.GetCardAttachments(task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get attachments: %w", err)
+		}
+		for _, at := range attachments {
+			a := Attachment{Name: at.Name, URL: at.URL, Size: at.Bytes}
+			if at.IsUpload {
+				url := at.URL
+				a.open = func() (io.ReadCloser, error) { return s.client.Download(url) }
+			}
+			c.Attachments = append(c.Attachments, a)
+		}
+	}
+	return c, nil
+}
+
+func (s *TrelloSource) UpdatePlan(id, plan string) error {
+	return s.client.UpdateCard(id, plan)
+}
+
+func (s *TrelloSource) Claim(id string, lease Lease) (bool, error) {
+	return claimByComment(trelloCommentThread{s.client, id}, lease, time.Now())
+}
+
+func (s *TrelloSource) Renew(id string, lease Lease) error {
+	return renewByComment(trelloCommentThread{s.client, id}, lease, time.Now())
+}
+
+func (s *TrelloSource) Release(id, runnerID string) error {
+	return releaseByComment(trelloCommentThread{s.client, id}, runnerID)
+}
+
+// trelloCommentThread is the comment thread of a card, used for leases.
+type trelloCommentThread struct {
+	client *trello.Client
+	cardID string
+}
+
+func (t trelloCommentThread) List() ([]leaseComment, error) {
+	comments, err := t.client.GetCardComments(t.cardID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]leaseComment, len(comments))
+	for i, c := range comments {
+		out[i] = leaseComment{ID: c.ID, Body: c.Data.Text}
+	}
+	return out, nil
+}
+
+func (t trelloCommentThread) Add(body string) (string, error) {
+	c, err := t.client.CreateComment(t.cardID, body)
+	if err != nil {
+		return "", err
+	}
+	return c.ID, nil
+}
+
+func (t trelloCommentThread) Edit(commentID, body string) error {
+	return t.client.UpdateComment(t.cardID, commentID, body)
+}
+
+func (t trelloCommentThread) Delete(commentID string) error {
+	return t.client.DeleteComment(t.cardID, commentID)
+}
+
 func trelloPriority(c trello.Card) int {
+	return priorityFromLabelNames(trelloLabelNames(c))
+}
+
+func trelloLabelNames(c trello.Card) []string {
 	names := make([]string, len(c.Labels))
 	for i, l := range c.Labels {
 		names[i] = l.Name
 	}
-	return priorityFromLabelNames(names)
+	return names
 }
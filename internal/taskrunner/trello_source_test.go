@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/siyuqian/devpilot/internal/project"
 	"github.com/siyuqian/devpilot/internal/trello"
 )
 
@@ -61,3 +62,35 @@ func TestParseTrelloPriority(t *testing.T) {
 		}
 	}
 }
+
+func TestTrelloSource_GatherContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1/cards/c1/actions":
+			w.Write([]byte(`[{"id":"a2","data":{"text":"Use staging"},"memberCreator":{"fullName":"Alice"}},
+			  {"id":"a1","data":{"text":"🔒 devpilot lease: runner=x expires=2999-01-01T00:00:00Z"}}]`))
+		case "/1/cards/c1/checklists":
+			w.Write([]byte(`[{"name":"QA","checkItems":[{"name":"second","state":"incomplete","pos":2},{"name":"first","state":"complete","pos":1}]}]`))
+		case "/1/cards/c1/attachments":
+			w.Write([]byte(`[{"name":"spec.pdf","url":"https://trello.com/spec.pdf","bytes":10,"isUpload":true},{"name":"Figma","url":"https://figma.com/f","isUpload":false}]`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewTrelloSource(trello.NewClient("k", "t", trello.WithBaseURL(srv.URL)), "Board")
+	c, err := s.GatherContext(Task{ID: "c1"}, project.ContextConfig{Comments: true, Checklists: true, Attachments: true})
+	if err != nil {
+		t.Fatalf("GatherContext: %v", err)
+	}
+	if len(c.Comments) != 1 || c.Comments[0].Author != "Alice" || c.Comments[0].Body != "Use staging" {
+		t.Errorf("comments = %+v", c.Comments)
+	}
+	if len(c.Checklists) != 1 || c.Checklists[0].Items[0].Text != "first" || !c.Checklists[0].Items[0].Done {
+		t.Errorf("checklists = %+v", c.Checklists)
+	}
+	if len(c.Attachments) != 2 || c.Attachments[0].open == nil || c.Attachments[1].open != nil {
+		t.Errorf("attachments = %+v", c.Attachments)
+	}
+}
@@ -40,6 +40,8 @@ type TUIModel struct {
 	toolCalls  []toolCallEntry
 	activeCall *toolCallEntry
 	textLines  []string
+	waitUntil  time.Time // set while the schedule holds the runner
+	waitReason string
 	stats      sessionStats
 
 	// File tracking
@@ -245,6 +247,22 @@ func (m TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.phase = "idle"
 		return m, waitForEvent(m.eventCh)
 
+	case ScheduleWaitEvent:
+		m.phase = "scheduled"
+		m.waitUntil = msg.Until
+		m.waitReason = msg.Reason
+		return m, waitForEvent(m.eventCh)
+
+	case PausedEvent:
+		m.phase = "paused"
+		return m, waitForEvent(m.eventCh)
+
+	case ApprovalNeededEvent:
+		m.textLines = append(m.textLines, "⏳ "+msg.CardName+" waits for approval (/devpilot approve "+msg.CardID+")")
+		m.wrapAndSetTextContent()
+		m.textViewport.GotoBottom()
+		return m, waitForEvent(m.eventCh)
+
 	case CardStartedEvent:
 		m.activeCard = &cardState{
 			id:      msg.CardID,
@@ -348,6 +366,12 @@ func (m TUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case FixDoneEvent:
 		return m, waitForEvent(m.eventCh)
 
+	case EscalatedEvent:
+		m.textLines = append(m.textLines, "⤴ "+msg.Reason+" on "+modelName(msg.From)+"; retrying on "+msg.To)
+		m.wrapAndSetTextContent()
+		m.textViewport.GotoBottom()
+		return m, waitForEvent(m.eventCh)
+
 	case RunnerStoppedEvent:
 		m.phase = "stopped"
 		return m, waitForEvent(m.eventCh)
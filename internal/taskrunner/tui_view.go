@@ -81,6 +81,10 @@ func renderHeader(m TUIModel) string {
 		phaseText = "▶ running"
 	case "idle":
 		phaseText = "waiting"
+	case "scheduled":
+		phaseText = "◷ scheduled"
+	case "paused":
+		phaseText = "⏸ paused"
 	case "stopped":
 		phaseText = "■ stopped"
 	}
@@ -119,6 +123,10 @@ func renderActiveTask(m TUIModel) string {
 		switch m.phase {
 		case "idle":
 			return activeCardStyle.Render("  (waiting for tasks...)")
+		case "scheduled":
+			return activeCardStyle.Render(fmt.Sprintf("  (waiting for %s at %s)", m.waitReason, m.waitUntil.Format("Mon 15:04")))
+		case "paused":
+			return activeCardStyle.Render("  (paused; resume with /devpilot resume)")
 		case "stopped":
 			return activeCardStyle.Render("  (runner stopped)")
 		default:
@@ -0,0 +1,88 @@
+package taskrunner
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/siyuqian/devpilot/internal/github"
+)
+
+// Workspace keeps one checkout per repository under a root directory, so a
+// single runner can execute tasks for several repositories.
+type Workspace struct {
+	root string
+	host string // GitHub host to clone from; empty means github.com
+
+	cloneURL func(host, repo string) string
+}
+
+func NewWorkspace(root, host string) *Workspace {
+	return &Workspace{root: root, host: host, cloneURL: github.CloneURL}
+}
+
+// DefaultWorkspaceRoot returns ~/.config/devpilot/workspaces.
+func DefaultWorkspaceRoot() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "devpilot", "workspaces")
+}
+
+// Path returns the checkout directory for repo ("owner/repo").
+func (w *Workspace) Path(repo string) string {
+	return filepath.Join(w.root, filepath.FromSlash(repo))
+}
+
+// Ensure clones repo into the workspace, or fetches it if already cloned,
+// and returns the checkout directory. Leftovers from an interrupted run are
+// discarded: the workspace belongs to devpilot, not to a person.
+func (w *Workspace) Ensure(repo string) (string, error) {
+	dir := w.Path(repo)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+			return "", fmt.Errorf("create workspace: %w", err)
+		}
+		if err := runGit("", "clone", w.cloneURL(w.host, repo), dir); err != nil {
+			return "", fmt.Errorf("clone %s: %w", repo, err)
+		}
+		return dir, nil
+	}
+	for _, args := range [][]string{
+		{"fetch", "--prune", defaultRemote},
+		{"reset", "--hard"},
+		{"clean", "-fd"},
+	} {
+		if err := runGit(dir, args...); err != nil {
+			return "", fmt.Errorf("refresh %s: %w", repo, err)
+		}
+	}
+	return dir, nil
+}
+
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s: %s %w", args[0], strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// checkout returns the git checkout task runs in: the workspace clone of
+// its repository, cloned or fetched first, or the runner's own checkout for
+// tasks without a repository and runners without a workspace. The executor,
+// reviewer and everything else working on the task's files take their
+// directory from it, so tasks never change the runner's shared state.
+func (r *Runner) checkout(task Task) (*GitOps, error) {
+	if r.workspace == nil || task.Repo == "" {
+		return r.git, nil
+	}
+	dir, err := r.workspace.Ensure(task.Repo)
+	if err != nil {
+		return nil, err
+	}
+	r.logger.Printf("Using checkout %s for %s", dir, task.Repo)
+	// The clone's origin is the task's repository, which PRs target too.
+	return NewGitOps(dir, WithBaseBranch(r.config.BaseBranch), WithGitHub(r.github)), nil
+}
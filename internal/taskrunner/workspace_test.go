@@ -0,0 +1,100 @@
+package taskrunner
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestWorkspace returns a workspace whose clones come from local bare
+// repositories under remotes, keyed by owner/repo.
+func newTestWorkspace(t *testing.T, remotes string) *Workspace {
+	t.Helper()
+	w := NewWorkspace(t.TempDir(), "")
+	w.cloneURL = func(host, repo string) string {
+		return filepath.Join(remotes, filepath.FromSlash(repo)+".git")
+	}
+	return w
+}
+
+func TestWorkspace_EnsureClonesThenRefreshes(t *testing.T) {
+	src := setupGitRepoWithRemote(t)
+	remotes := t.TempDir()
+	os.MkdirAll(filepath.Join(remotes, "acme"), 0755)
+	gitRun(t, "", "clone", "--bare", src, filepath.Join(remotes, "acme", "api.git"))
+
+	w := newTestWorkspace(t, remotes)
+	dir, err := w.Ensure("acme/api")
+	if err != nil {
+		t.Fatalf("first Ensure: %v", err)
+	}
+	if dir != w.Path("acme/api") {
+		t.Errorf("dir = %s, want %s", dir, w.Path("acme/api"))
+	}
+
+	// Leftovers from an interrupted run are discarded on the next Ensure.
+	os.WriteFile(filepath.Join(dir, "stray.txt"), []byte("x"), 0644)
+	if _, err := w.Ensure("acme/api"); err != nil {
+		t.Fatalf("second Ensure: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stray.txt")); !os.IsNotExist(err) {
+		t.Error("expected untracked leftovers to be cleaned")
+	}
+}
+
+func TestRunner_Checkout(t *testing.T) {
+	src := setupGitRepoWithRemote(t)
+	remotes := t.TempDir()
+	os.MkdirAll(filepath.Join(remotes, "acme"), 0755)
+	gitRun(t, "", "clone", "--bare", src, filepath.Join(remotes, "acme", "web.git"))
+
+	home := t.TempDir()
+	r := &Runner{
+		config:    Config{WorkDir: home},
+		workspace: newTestWorkspace(t, remotes),
+		git:       NewGitOps(home),
+		executor:  NewExecutor(WithCommand("pwd")),
+		logger:    log.New(io.Discard, "", 0),
+	}
+
+	git, err := r.checkout(Task{Repo: "acme/web"})
+	if err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	want := r.workspace.Path("acme/web")
+	if git.dir != want {
+		t.Errorf("checkout dir = %s, want %s", git.dir, want)
+	}
+	if r.config.WorkDir != home || r.git.dir != home {
+		t.Errorf("checkout changed the runner's own: WorkDir=%s git=%s", r.config.WorkDir, r.git.dir)
+	}
+	result, err := r.executor.InDir(git.dir).Run(context.Background(), "")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(want); !strings.Contains(result.Stdout, resolved) {
+		t.Errorf("executor ran in %q, want %s", result.Stdout, want)
+	}
+	if data := r.promptData(Task{Repo: "acme/web"}, git, ""); data.Repo.Dir != want {
+		t.Errorf("prompt Repo.Dir = %s, want %s", data.Repo.Dir, want)
+	}
+
+	// Tasks without a repo stay in the runner's checkout.
+	if git, _ := r.checkout(Task{}); git != r.git {
+		t.Errorf("task without repo should use the runner's checkout, got %s", git.dir)
+	}
+}
+
+func TestSafeID(t *testing.T) {
+	git := NewGitOps("/tmp")
+	if got := git.BranchName("acme/api#42", "Fix login"); got != "task/acme-api-42-fix-login" {
+		t.Errorf("BranchName = %q", got)
+	}
+	if got := safeID("6543abcd"); got != "6543abcd" {
+		t.Errorf("plain IDs must be unchanged, got %q", got)
+	}
+}
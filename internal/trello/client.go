@@ -6,6 +6,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"sort"
 	"time"
 )
 
@@ -114,6 +116,28 @@ func (c *Client) put(path string, params url.Values) ([]byte, error) {
 	return body, nil
 }
 
+func (c *Client) delete(path string) error {
+	params := url.Values{"key": {c.apiKey}, "token": {c.token}}
+	reqURL := fmt.Sprintf("%s%s?%s", c.baseURL, path, params.Encode())
+
+	req, err := http.NewRequest(http.MethodDelete, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("create request failed: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
 func (c *Client) GetBoards() ([]Board, error) {
 	params := url.Values{"filter": {"open"}}
 	data, err := c.get("/1/members/me/boards", params)
@@ -165,6 +189,94 @@ func (c *Client) AddComment(cardID, text string) error {
 	return err
 }
 
+// CreateComment adds a comment to a card and returns it, so it can later be
+// edited or deleted.
+func (c *Client) CreateComment(cardID, text string) (*Comment, error) {
+	params := url.Values{"text": {text}}
+	data, err := c.post(fmt.Sprintf("/1/cards/%s/actions/comments", cardID), params)
+	if err != nil {
+		return nil, err
+	}
+	var comment Comment
+	if err := json.Unmarshal(data, &comment); err != nil {
+		return nil, fmt.Errorf("parse comment: %w", err)
+	}
+	return &comment, nil
+}
+
+// GetCardComments returns the comments on a card, oldest first.
+func (c *Client) GetCardComments(cardID string) ([]Comment, error) {
+	params := url.Values{"filter": {"commentCard"}, "limit": {"1000"}}
+	data, err := c.get(fmt.Sprintf("/1/cards/%s/actions", cardID), params)
+	if err != nil {
+		return nil, err
+	}
+	var comments []Comment
+	if err := json.Unmarshal(data, &comments); err != nil {
+		return nil, fmt.Errorf("parse comments: %w", err)
+	}
+	// Trello lists actions newest first.
+	slices.Reverse(comments)
+	return comments, nil
+}
+
+func (c *Client) UpdateComment(cardID, commentID, text string) error {
+	params := url.Values{"text": {text}}
+	_, err := c.put(fmt.Sprintf("/1/cards/%s/actions/%s/comments", cardID, commentID), params)
+	return err
+}
+
+func (c *Client) DeleteComment(cardID, commentID string) error {
+	return c.delete(fmt.Sprintf("/1/cards/%s/actions/%s/comments", cardID, commentID))
+}
+
+func (c *Client) GetCardChecklists(cardID string) ([]Checklist, error) {
+	data, err := c.get(fmt.Sprintf("/1/cards/%s/checklists", cardID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var checklists []Checklist
+	if err := json.Unmarshal(data, &checklists); err != nil {
+		return nil, fmt.Errorf("parse checklists: %w", err)
+	}
+	for _, cl := range checklists {
+		sort.SliceStable(cl.CheckItems, func(i, j int) bool { return cl.CheckItems[i].Pos < cl.CheckItems[j].Pos })
+	}
+	return checklists, nil
+}
+
+func (c *Client) GetCardAttachments(cardID string) ([]Attachment, error) {
+	data, err := c.get(fmt.Sprintf("/1/cards/%s/attachments", cardID), nil)
+	if err != nil {
+		return nil, err
+	}
+	var attachments []Attachment
+	if err := json.Unmarshal(data, &attachments); err != nil {
+		return nil, fmt.Errorf("parse attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// Download fetches an uploaded attachment. Trello serves uploads only to
+// requests carrying the OAuth header, not the key and token query params.
+func (c *Client) Download(rawURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf(`OAuth oauth_consumer_key="%s", oauth_token="%s"`, c.apiKey, c.token))
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	return resp.Body, nil
+}
+
 func (c *Client) CreateCard(listID, name, desc string) (*Card, error) {
 	params := url.Values{
 		"idList": {listID},
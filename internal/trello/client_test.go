@@ -123,6 +123,37 @@ func TestAddComment(t *testing.T) {
 	}
 }
 
+func TestCardComments(t *testing.T) {
+	var deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/1/cards/card1/actions":
+			if r.URL.Query().Get("filter") != "commentCard" {
+				t.Errorf("unexpected filter: %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `[{"id":"a2","data":{"text":"second"}},{"id":"a1","data":{"text":"first"}}]`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/1/cards/card1/actions/a1/comments":
+			deleted = "a1"
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("k", "t", WithBaseURL(server.URL))
+	comments, err := client.GetCardComments("card1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(comments) != 2 || comments[0].ID != "a1" || comments[0].Data.Text != "first" {
+		t.Errorf("expected oldest first, got %+v", comments)
+	}
+	if err := client.DeleteComment("card1", "a1"); err != nil || deleted != "a1" {
+		t.Errorf("DeleteComment: err=%v deleted=%q", err, deleted)
+	}
+}
+
 func TestFindBoardByName(t *testing.T) {
 	boards := []Board{{ID: "b1", Name: "Sprint Board"}, {ID: "b2", Name: "Backlog"}}
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
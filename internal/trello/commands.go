@@ -4,10 +4,8 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 
-	"github.com/siyuqian/devpilot/internal/auth"
 	"github.com/siyuqian/devpilot/internal/project"
 	"github.com/spf13/cobra"
 )
@@ -31,6 +29,7 @@ var pushCmd = &cobra.Command{
 
 		filePath := args[0]
 		listName, _ := cmd.Flags().GetString("list")
+		boardName, _ := cmd.Flags().GetString("board")
 
 		sourceName, _ := cmd.Flags().GetString("source")
 		dir, _ := os.Getwd()
@@ -44,81 +43,27 @@ var pushCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		// Extract title from first # heading
-		title := extractTitle(string(content))
-		if title == "" {
-			fmt.Fprintln(os.Stderr, "Error: no # heading found in file")
+		task, err := CreateTask(projectCfg, TaskTarget{
+			Source: sourceName,
+			Board:  boardName,
+			List:   listName,
+			Dir:    dir,
+		}, string(content))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		switch sourceName {
-		case "trello":
-			boardName, _ := cmd.Flags().GetString("board")
-
-			if boardName == "" {
-				if projectCfg.Board != "" {
-					boardName = projectCfg.Board
-				}
-			}
-			if boardName == "" {
-				fmt.Fprintln(os.Stderr, "Error: --board is required (or run: devpilot init)")
-				os.Exit(1)
-			}
-
-			// Load Trello credentials
-			creds, err := auth.Load("trello")
-			if err != nil {
-				fmt.Fprintln(os.Stderr, "Not logged in to Trello. Run: devpilot login trello")
-				os.Exit(1)
-			}
-
-			client := NewClient(creds["api_key"], creds["token"])
-
-			// Resolve board
-			board, err := client.FindBoardByName(boardName)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
-			}
-
-			// Resolve list
-			list, err := client.FindListByName(board.ID, listName)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
-			}
-
-			// Create card
-			card, err := client.CreateCard(list.ID, title, string(content))
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error creating card: %v\n", err)
-				os.Exit(1)
-			}
-
-			fmt.Printf("Created card: %s\n", title)
-			if card.ShortURL != "" {
-				fmt.Println(card.ShortURL)
-			}
-		case "github":
-			out, err := exec.Command("gh", "issue", "create",
-				"--title", title,
-				"--body", string(content),
-				"--label", "devpilot",
-			).Output()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error creating issue: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Printf("Created issue: %s\n", title)
-			fmt.Println(strings.TrimSpace(string(out)))
-		default:
-			fmt.Fprintf(os.Stderr, "Unknown source %q. Must be trello or github.\n", sourceName)
-			os.Exit(1)
+		fmt.Printf("Created %s: %s\n", task.Kind, task.Title)
+		if task.URL != "" {
+			fmt.Println(task.URL)
 		}
 	},
 }
 
-func extractTitle(content string) string {
+// ExtractTitle returns the text of a plan's first # heading, or "" when it
+// has none.
+func ExtractTitle(content string) string {
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
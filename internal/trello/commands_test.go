@@ -31,9 +31,9 @@ func TestExtractTitle(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := extractTitle(tt.content)
+			got := ExtractTitle(tt.content)
 			if got != tt.want {
-				t.Errorf("extractTitle() = %q, want %q", got, tt.want)
+				t.Errorf("ExtractTitle() = %q, want %q", got, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,89 @@
+package trello
+
+import (
+	"fmt"
+
+	"github.com/siyuqian/devpilot/internal/auth"
+	"github.com/siyuqian/devpilot/internal/github"
+	"github.com/siyuqian/devpilot/internal/project"
+)
+
+// TaskTarget says where CreateTask files a task.
+type TaskTarget struct {
+	Source string // "trello" or "github"
+	Board  string // Trello board name; empty uses the board from .devpilot.yaml
+	List   string // Trello list name
+	Dir    string // checkout whose remote names the GitHub repository
+	// Labels go on GitHub issues. Nil means "devpilot", which queues the
+	// issue for the runner.
+	Labels []string
+}
+
+// CreatedTask is a card or issue made by CreateTask.
+type CreatedTask struct {
+	Kind  string // "card" or "issue"
+	Title string
+	URL   string
+}
+
+// CreateTask files a plan as a Trello card or GitHub issue, titled after the
+// plan's first # heading with the whole plan as its description.
+func CreateTask(cfg *project.Config, target TaskTarget, plan string) (*CreatedTask, error) {
+	title := ExtractTitle(plan)
+	if title == "" {
+		return nil, fmt.Errorf("no # heading found in plan")
+	}
+
+	switch target.Source {
+	case "trello":
+		boardName := target.Board
+		if boardName == "" {
+			boardName = cfg.Board
+		}
+		if boardName == "" {
+			return nil, fmt.Errorf("--board is required (or run: devpilot init)")
+		}
+		creds, err := auth.Load("trello")
+		if err != nil {
+			return nil, fmt.Errorf("not logged in to Trello. Run: devpilot login trello")
+		}
+		client := NewClient(creds["api_key"], creds["token"])
+		board, err := client.FindBoardByName(boardName)
+		if err != nil {
+			return nil, err
+		}
+		list, err := client.FindListByName(board.ID, target.List)
+		if err != nil {
+			return nil, err
+		}
+		card, err := client.CreateCard(list.ID, title, plan)
+		if err != nil {
+			return nil, fmt.Errorf("create card: %w", err)
+		}
+		return &CreatedTask{Kind: "card", Title: title, URL: card.ShortURL}, nil
+	case "github":
+		token, err := github.LoadToken(cfg.GitHub.Host)
+		if err != nil {
+			return nil, err
+		}
+		repo, err := github.ResolveRepo(target.Dir, cfg.PRRepo, cfg.Remote)
+		if err != nil {
+			return nil, err
+		}
+		labels := target.Labels
+		if labels == nil {
+			labels = []string{"devpilot"}
+		}
+		issue, err := github.NewClient(token, github.WithHost(cfg.GitHub.Host)).CreateIssue(repo, github.NewIssue{
+			Title:  title,
+			Body:   plan,
+			Labels: labels,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create issue: %w", err)
+		}
+		return &CreatedTask{Kind: "issue", Title: title, URL: issue.HTMLURL}, nil
+	default:
+		return nil, fmt.Errorf("unknown source %q. Must be trello or github", target.Source)
+	}
+}
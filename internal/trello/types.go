@@ -1,5 +1,7 @@
 package trello
 
+import "time"
+
 type Board struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -24,3 +26,38 @@ type Card struct {
 	ShortURL string  `json:"shortUrl"`
 	Labels   []Label `json:"labels"`
 }
+
+// Comment is a card comment, stored by Trello as a commentCard action.
+type Comment struct {
+	ID   string    `json:"id"`
+	Date time.Time `json:"date"`
+	Data struct {
+		Text string `json:"text"`
+	} `json:"data"`
+	MemberCreator struct {
+		FullName string `json:"fullName"`
+		Username string `json:"username"`
+	} `json:"memberCreator"`
+}
+
+type Checklist struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name"`
+	CheckItems []CheckItem `json:"checkItems"`
+}
+
+type CheckItem struct {
+	Name  string  `json:"name"`
+	State string  `json:"state"` // "complete" or "incomplete"
+	Pos   float64 `json:"pos"`
+}
+
+// Attachment is a file uploaded to a card or a link attached to it.
+type Attachment struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Bytes    int64  `json:"bytes"`
+	MimeType string `json:"mimeType"`
+	IsUpload bool   `json:"isUpload"`
+}